@@ -0,0 +1,170 @@
+package api
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+
+	"github.com/YaleSpinup/apierror"
+	"github.com/YaleSpinup/aws-go/services/resourcegroupstaggingapi"
+	"github.com/YaleSpinup/datasync-api/common"
+)
+
+var accountIdRegex = regexp.MustCompile(`^[0-9]{12}$`)
+
+// accountConfig is the configuration of a member account, with the defaults of the API's account applied
+type accountConfig struct {
+	id         string
+	role       string
+	externalId string
+	// regions are the regions movers are managed in, the default region comes first
+	regions []string
+	// groups are the groups allowed in the account, any group is allowed when it's empty
+	groups []string
+}
+
+// newAccountConfigs returns the configured member accounts by account ID and by alias
+func newAccountConfigs(config common.Config) (map[string]*accountConfig, error) {
+	defaults := defaultAccountConfig(config.Account)
+
+	accounts := map[string]*accountConfig{}
+	for key, a := range config.Accounts {
+		id := a.Id
+		if id == "" {
+			id = key
+		}
+
+		if !accountIdRegex.MatchString(id) {
+			return nil, fmt.Errorf("account %s: id must be a 12 digit account id", key)
+		}
+
+		acct := &accountConfig{
+			id:         id,
+			role:       a.Role,
+			externalId: a.ExternalID,
+			regions:    defaults.regions,
+			groups:     a.Groups,
+		}
+
+		if acct.role == "" {
+			acct.role = defaults.role
+		}
+
+		if acct.externalId == "" {
+			acct.externalId = defaults.externalId
+		}
+
+		if len(a.Regions) > 0 {
+			acct.regions = uniqueRegions(a.Regions)
+		}
+
+		keys := []string{key}
+		if id != key {
+			keys = append(keys, id)
+		}
+
+		for _, k := range keys {
+			if _, ok := accounts[k]; ok {
+				return nil, fmt.Errorf("account %s is configured more than once", k)
+			}
+			accounts[k] = acct
+		}
+	}
+
+	return accounts, nil
+}
+
+// defaultAccountConfig returns the configuration used for all accounts when no accounts are configured
+func defaultAccountConfig(account common.Account) accountConfig {
+	return accountConfig{
+		role:       account.Role,
+		externalId: account.ExternalID,
+		regions:    moverRegions(account),
+	}
+}
+
+// accountConfig returns the configuration of an account by ID or alias, or a not found error if the account
+// isn't configured.  When no accounts are configured, any account is managed with the default configuration.
+func (s *server) accountConfig(account string) (*accountConfig, error) {
+	if len(s.accounts) == 0 {
+		acct := s.defaultAccount
+		acct.id = account
+		return &acct, nil
+	}
+
+	if acct, ok := s.accounts[account]; ok {
+		return acct, nil
+	}
+
+	return nil, apierror.New(apierror.ErrNotFound, "account "+account+" is not configured", nil)
+}
+
+// configuredAccounts returns the configured member accounts once each (they're mapped by ID and alias), by ID
+func (s *server) configuredAccounts() []*accountConfig {
+	accounts := []*accountConfig{}
+	seen := map[string]bool{}
+	for _, a := range s.accounts {
+		if !seen[a.id] {
+			accounts = append(accounts, a)
+			seen[a.id] = true
+		}
+	}
+
+	sort.Slice(accounts, func(i, j int) bool { return accounts[i].id < accounts[j].id })
+
+	return accounts
+}
+
+// region returns the default region when the region is empty, or an error if the region isn't configured for the account
+func (a *accountConfig) region(region string) (string, error) {
+	if len(a.regions) == 0 {
+		return "", apierror.New(apierror.ErrInternalError, "no regions are configured", nil)
+	}
+
+	if region == "" {
+		return a.regions[0], nil
+	}
+
+	for _, r := range a.regions {
+		if r == region {
+			return region, nil
+		}
+	}
+
+	return "", apierror.New(apierror.ErrBadRequest, "region "+region+" is not supported", nil)
+}
+
+// groupAllowed returns true if the group can be managed in the account
+func (a *accountConfig) groupAllowed(group string) bool {
+	if a == nil || len(a.groups) == 0 {
+		return true
+	}
+
+	for _, g := range a.groups {
+		if g == group {
+			return true
+		}
+	}
+
+	return false
+}
+
+// groupTagFilter returns the spinup:spaceid tag filter for a group, or for the groups allowed in the account
+// when the group is empty.  It's nil when the group is empty and any group is allowed.
+func (o *datasyncOrchestrator) groupTagFilter(group string) *resourcegroupstaggingapi.TagFilter {
+	var groups []string
+	if group != "" {
+		groups = []string{group}
+	} else if o.accountConfig != nil && len(o.accountConfig.groups) > 0 {
+		groups = o.accountConfig.groups
+	}
+
+	if groups == nil {
+		return nil
+	}
+
+	return &resourcegroupstaggingapi.TagFilter{
+		Key:   "spinup:spaceid",
+		Value: groups,
+	}
+}
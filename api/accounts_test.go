@@ -0,0 +1,187 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/YaleSpinup/apierror"
+	"github.com/YaleSpinup/datasync-api/common"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+)
+
+var testAccountsConfig = common.Config{
+	Account: common.Account{
+		Region:     "us-east-1",
+		Regions:    []string{"us-west-2"},
+		Role:       "default-role",
+		ExternalID: "default-ext",
+	},
+	Accounts: map[string]common.MemberAccount{
+		"012345678901": {},
+		"research": {
+			Id:         "123456789012",
+			Role:       "research-role",
+			ExternalID: "research-ext",
+			Regions:    []string{"us-west-2", "us-east-1", "us-west-2"},
+			Groups:     []string{"group1", "group2"},
+		},
+	},
+}
+
+func TestNewAccountConfigs(t *testing.T) {
+	accounts, err := newAccountConfigs(testAccountsConfig)
+	if err != nil {
+		t.Fatalf("expected nil error, got %s", err)
+	}
+
+	assert.Len(t, accounts, 3)
+	assert.Equal(t, &accountConfig{
+		id:         "012345678901",
+		role:       "default-role",
+		externalId: "default-ext",
+		regions:    []string{"us-east-1", "us-west-2"},
+	}, accounts["012345678901"])
+
+	// aliased accounts are found by alias and by ID
+	assert.Equal(t, &accountConfig{
+		id:         "123456789012",
+		role:       "research-role",
+		externalId: "research-ext",
+		regions:    []string{"us-west-2", "us-east-1"},
+		groups:     []string{"group1", "group2"},
+	}, accounts["research"])
+	assert.Same(t, accounts["research"], accounts["123456789012"])
+
+	tests := []struct {
+		name     string
+		accounts map[string]common.MemberAccount
+	}{
+		{"alias without id", map[string]common.MemberAccount{"research": {}}},
+		{"invalid id", map[string]common.MemberAccount{"research": {Id: "1234"}}},
+		{"duplicate id", map[string]common.MemberAccount{"123456789012": {}, "research": {Id: "123456789012"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := newAccountConfigs(common.Config{Accounts: tt.accounts}); err == nil {
+				t.Error("expected error, got nil")
+			}
+		})
+	}
+}
+
+func TestServerAccountConfig(t *testing.T) {
+	accounts, err := newAccountConfigs(testAccountsConfig)
+	if err != nil {
+		t.Fatalf("expected nil error, got %s", err)
+	}
+	s := &server{accounts: accounts, defaultAccount: defaultAccountConfig(testAccountsConfig.Account)}
+
+	acct, err := s.accountConfig("research")
+	if err != nil {
+		t.Fatalf("expected nil error, got %s", err)
+	}
+	assert.Equal(t, "123456789012", acct.id)
+
+	_, err = s.accountConfig("999999999999")
+	if aerr, ok := err.(apierror.Error); !ok || aerr.Code != apierror.ErrNotFound {
+		t.Errorf("expected not found error, got %v", err)
+	}
+
+	// without configured accounts, any account uses the defaults
+	s.accounts = nil
+	acct, err = s.accountConfig("999999999999")
+	if err != nil {
+		t.Fatalf("expected nil error, got %s", err)
+	}
+	assert.Equal(t, &accountConfig{
+		id:         "999999999999",
+		role:       "default-role",
+		externalId: "default-ext",
+		regions:    []string{"us-east-1", "us-west-2"},
+	}, acct)
+	assert.Equal(t, "", s.defaultAccount.id)
+}
+
+func TestAccountConfigRegion(t *testing.T) {
+	a := &accountConfig{regions: []string{"us-west-2", "us-east-1"}}
+
+	tests := []struct {
+		region  string
+		want    string
+		wantErr bool
+	}{
+		{"", "us-west-2", false},
+		{"us-east-1", "us-east-1", false},
+		{"eu-west-1", "", true},
+	}
+
+	for _, tt := range tests {
+		got, err := a.region(tt.region)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("region(%q) error = %v, wantErr %v", tt.region, err, tt.wantErr)
+		}
+
+		if aerr, ok := err.(apierror.Error); ok && aerr.Code != apierror.ErrBadRequest {
+			t.Errorf("region(%q) expected bad request, got %s", tt.region, aerr.Code)
+		}
+
+		if got != tt.want {
+			t.Errorf("region(%q) = %q, want %q", tt.region, got, tt.want)
+		}
+	}
+
+	if _, err := (&accountConfig{}).region(""); err == nil {
+		t.Error("expected error without regions, got nil")
+	}
+}
+
+func TestGroupTagFilter(t *testing.T) {
+	o := &datasyncOrchestrator{accountConfig: &accountConfig{}}
+
+	assert.Nil(t, o.groupTagFilter(""))
+	assert.Equal(t, []string{"group1"}, o.groupTagFilter("group1").Value)
+	assert.True(t, o.accountConfig.groupAllowed("group3"))
+
+	o.accountConfig.groups = []string{"group1", "group2"}
+	assert.Equal(t, []string{"group1", "group2"}, o.groupTagFilter("").Value)
+	assert.True(t, o.accountConfig.groupAllowed("group2"))
+	assert.False(t, o.accountConfig.groupAllowed("group3"))
+}
+
+func TestGroupMiddleware(t *testing.T) {
+	accounts, err := newAccountConfigs(testAccountsConfig)
+	if err != nil {
+		t.Fatalf("expected nil error, got %s", err)
+	}
+	s := &server{accounts: accounts}
+
+	router := mux.NewRouter()
+	router.Use(s.GroupMiddleware)
+	ok := func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) }
+	router.HandleFunc("/{account}/movers", ok)
+	router.HandleFunc("/{account}/movers/{group}", ok)
+
+	tests := []struct {
+		path string
+		want int
+	}{
+		{"/research/movers", http.StatusOK},
+		{"/research/movers/group1", http.StatusOK},
+		{"/123456789012/movers/group2", http.StatusOK},
+		{"/research/movers/group3", http.StatusNotFound},
+		{"/012345678901/movers/group3", http.StatusOK},
+		// unconfigured accounts are rejected by the handlers
+		{"/999999999999/movers/group1", http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, tt.path, nil))
+		if rr.Code != tt.want {
+			t.Errorf("GET %s returned %d, want %d", tt.path, rr.Code, tt.want)
+		}
+	}
+}
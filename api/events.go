@@ -0,0 +1,299 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/YaleSpinup/apierror"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/sqs"
+	"github.com/patrickmn/go-cache"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// executionStateChangeType is the detail type of the EventBridge events sent when a task execution changes state
+	executionStateChangeType = "DataSync Task Execution State Change"
+	// eventRetryInterval is the wait before receiving again after a failed receive, it doubles up to eventMaxRetryInterval
+	eventRetryInterval    = time.Second
+	eventMaxRetryInterval = time.Minute
+)
+
+// runStateChanges counts the execution state change events received for movers in the org, by state
+var runStateChanges = promauto.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "datasync_api",
+	Name:      "run_state_changes_total",
+	Help:      "Number of DataSync task execution state change events received for data movers, by state.",
+}, []string{"state"})
+
+// eventQueue is the queue EventBridge sends the DataSync execution state change events to
+type eventQueue interface {
+	ReceiveMessages(ctx context.Context) ([]*sqs.Message, error)
+	DeleteMessage(ctx context.Context, receiptHandle string) error
+}
+
+// executionStateEvent is a DataSync Task Execution State Change event, ie.
+//
+//	{
+//	  "source": "aws.datasync",
+//	  "detail-type": "DataSync Task Execution State Change",
+//	  "account": "012345678901",
+//	  "region": "us-east-1",
+//	  "resources": ["arn:aws:datasync:us-east-1:012345678901:task/task-0123/execution/exec-0123"],
+//	  "detail": {"State": "SUCCESS"}
+//	}
+type executionStateEvent struct {
+	Source     string   `json:"source"`
+	DetailType string   `json:"detail-type"`
+	Account    string   `json:"account"`
+	Region     string   `json:"region"`
+	Resources  []string `json:"resources"`
+	Detail     struct {
+		State string `json:"State"`
+	} `json:"detail"`
+}
+
+// eventTask is the data mover of a task that sent events, tasks outside of the org or in groups
+// that aren't allowed in the account are ignored
+type eventTask struct {
+	group   string
+	name    string
+	ignored bool
+}
+
+// eventConsumer receives the execution state change events from the queue.  Events for finished runs
+// update the finished run cache used to describe runs and notify the webhooks of the mover, and all
+// events wake the run watchers and are counted in the metrics.
+type eventConsumer struct {
+	server        *server
+	queue         eventQueue
+	retryInterval time.Duration
+	// orchestrator returns a read-only orchestrator for an account in a region
+	orchestrator func(ctx context.Context, account, region string) (*datasyncOrchestrator, error)
+	// tasks maps task ARNs to their data movers
+	tasks *cache.Cache
+}
+
+// newEventConsumer returns a consumer for the events in the queue
+func newEventConsumer(s *server, queue eventQueue, orchestrator func(ctx context.Context, account, region string) (*datasyncOrchestrator, error)) *eventConsumer {
+	return &eventConsumer{
+		server:        s,
+		queue:         queue,
+		retryInterval: eventRetryInterval,
+		orchestrator:  orchestrator,
+		tasks:         cache.New(taskIndexTTL, 2*taskIndexTTL),
+	}
+}
+
+// run receives and handles events until the context is cancelled.  Messages are deleted once they're
+// handled, messages that fail are received again after the queue's visibility timeout.
+func (c *eventConsumer) run(ctx context.Context) {
+	wait := c.retryInterval
+	for ctx.Err() == nil {
+		msgs, err := c.queue.ReceiveMessages(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+
+			log.Errorf("failed to receive execution state change events, retrying in %s: %s", wait, err)
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(wait):
+			}
+
+			if wait *= 2; wait > eventMaxRetryInterval {
+				wait = eventMaxRetryInterval
+			}
+			continue
+		}
+		wait = c.retryInterval
+
+		for _, m := range msgs {
+			if err := c.handle(ctx, m); err != nil {
+				log.Warnf("failed to handle execution state change event %s, it will be retried: %s", aws.StringValue(m.MessageId), err)
+				continue
+			}
+
+			if err := c.queue.DeleteMessage(ctx, aws.StringValue(m.ReceiptHandle)); err != nil {
+				log.Errorf("failed to delete execution state change event %s: %s", aws.StringValue(m.MessageId), err)
+			}
+		}
+	}
+}
+
+// handle processes an execution state change event, messages that aren't execution state change events, are
+// from accounts or regions that aren't configured, or are for tasks outside of the org or that were deleted are
+// ignored.  Other errors are returned, so the message is received again.
+func (c *eventConsumer) handle(ctx context.Context, m *sqs.Message) error {
+	ev := &executionStateEvent{}
+	if err := json.Unmarshal([]byte(aws.StringValue(m.Body)), ev); err != nil {
+		log.Warnf("ignoring message %s, not an EventBridge event: %s", aws.StringValue(m.MessageId), err)
+		return nil
+	}
+
+	if ev.Source != "aws.datasync" || ev.DetailType != executionStateChangeType {
+		log.Debugf("ignoring %s event from %s", ev.DetailType, ev.Source)
+		return nil
+	}
+
+	var execArn string
+	for _, r := range ev.Resources {
+		if strings.Contains(r, "/execution/") {
+			execArn = r
+		}
+	}
+
+	if execArn == "" || ev.Detail.State == "" {
+		log.Warnf("ignoring execution state change event %s without an execution or state", aws.StringValue(m.MessageId))
+		return nil
+	}
+	taskArn := execArn[:strings.Index(execArn, "/execution/")]
+
+	if !c.configured(ev.Account, ev.Region) {
+		log.Warnf("ignoring execution state change event for task %s, account %s region %s isn't configured", taskArn, ev.Account, ev.Region)
+		return nil
+	}
+
+	task, err := c.eventTask(ctx, ev.Account, ev.Region, taskArn)
+	if err != nil {
+		// the mover may have been deleted since the event was sent, DataSync returns an InvalidRequestException
+		// for tasks that don't exist, but it's also returned for other errors, so the task must be missing from
+		// the list of tasks to ignore the event
+		if aerr, ok := err.(apierror.Error); ok && (aerr.Code == apierror.ErrNotFound || aerr.Code == apierror.ErrBadRequest) {
+			missing, merr := c.taskMissing(ctx, ev.Account, ev.Region, taskArn)
+			if merr != nil {
+				log.Warnf("failed to confirm task %s is missing: %s", taskArn, merr)
+				return err
+			}
+
+			if missing {
+				log.Warnf("ignoring execution state change event for task %s, the task doesn't exist: %s", taskArn, err)
+				return nil
+			}
+		}
+
+		return err
+	}
+
+	if task.ignored {
+		return nil
+	}
+
+	log.Infof("data mover %s/%s run %s changed state to %s", task.group, task.name, execArn[strings.LastIndex(execArn, "/")+1:], ev.Detail.State)
+
+	runStateChanges.WithLabelValues(ev.Detail.State).Inc()
+	c.server.runWatchers.wake(execArn)
+
+	if !runFinished(aws.String(ev.Detail.State)) {
+		return nil
+	}
+
+	o, err := c.orchestrator(ctx, ev.Account, ev.Region)
+	if err != nil {
+		return err
+	}
+
+	// describing a finished run adds it to the finished run cache
+	if _, err := o.describeRun(ctx, execArn); err != nil {
+		return err
+	}
+
+	if c.server.notifier == nil {
+		return nil
+	}
+
+	// the webhooks may have changed since the task was cached
+	tags, err := o.datasyncClient.GetDatasyncTags(ctx, taskArn)
+	if err != nil {
+		return err
+	}
+
+	if urls := webhookUrls(fromDatasyncTags(tags)); len(urls) > 0 {
+		if err := c.server.notifier.watch(ctx, o, task.group, task.name, taskArn, urls); err != nil {
+			return err
+		}
+
+		c.server.notifier.followEvent(taskArn, execArn)
+	}
+
+	return nil
+}
+
+// configured returns true if the account and region of an event are configured
+func (c *eventConsumer) configured(account, region string) bool {
+	acct, err := c.server.accountConfig(account)
+	if err != nil {
+		return false
+	}
+
+	_, err = acct.region(region)
+	return err == nil
+}
+
+// taskMissing returns true if the task isn't in the list of tasks of the account in the region
+func (c *eventConsumer) taskMissing(ctx context.Context, account, region, taskArn string) (bool, error) {
+	o, err := c.orchestrator(ctx, account, region)
+	if err != nil {
+		return false, err
+	}
+
+	tasks, err := o.datasyncClient.ListDatasyncTasks(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	for _, t := range tasks {
+		if t == taskArn {
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
+
+// eventTask returns the data mover of a task from the cache, or from the task and its tags
+func (c *eventConsumer) eventTask(ctx context.Context, account, region, taskArn string) (*eventTask, error) {
+	if v, ok := c.tasks.Get(taskArn); ok {
+		if t, ok := v.(*eventTask); ok {
+			return t, nil
+		}
+	}
+
+	o, err := c.orchestrator(ctx, account, region)
+	if err != nil {
+		return nil, err
+	}
+
+	name, err := o.datamoverNameFromArn(ctx, taskArn)
+	if err != nil {
+		return nil, err
+	}
+
+	dsTags, err := o.datasyncClient.GetDatasyncTags(ctx, taskArn)
+	if err != nil {
+		return nil, err
+	}
+	tags := fromDatasyncTags(dsTags)
+
+	t := &eventTask{name: name, ignored: !tags.inOrg(c.server.org)}
+	for _, tag := range tags {
+		if tag.Key == "spinup:spaceid" {
+			t.group = tag.Value
+		}
+	}
+
+	if t.group == "" || !o.accountConfig.groupAllowed(t.group) {
+		t.ignored = true
+	}
+
+	c.tasks.Set(taskArn, t, cache.DefaultExpiration)
+
+	return t, nil
+}
@@ -0,0 +1,299 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	ydatasync "github.com/YaleSpinup/datasync-api/datasync"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/datasync"
+	"github.com/aws/aws-sdk-go/service/sqs"
+	"github.com/patrickmn/go-cache"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeEventQueue is an in-memory event queue, the first failures receives return an error
+type fakeEventQueue struct {
+	mu       sync.Mutex
+	pending  []*sqs.Message
+	deleted  []string
+	failures int
+}
+
+func (q *fakeEventQueue) ReceiveMessages(ctx context.Context) ([]*sqs.Message, error) {
+	q.mu.Lock()
+	if q.failures > 0 {
+		q.failures--
+		q.mu.Unlock()
+		return nil, errors.New("receive failed")
+	}
+
+	msgs := q.pending
+	q.pending = nil
+	q.mu.Unlock()
+
+	if len(msgs) == 0 {
+		select {
+		case <-ctx.Done():
+		case <-time.After(time.Millisecond):
+		}
+	}
+
+	return msgs, nil
+}
+
+func (q *fakeEventQueue) DeleteMessage(ctx context.Context, receiptHandle string) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.deleted = append(q.deleted, receiptHandle)
+	return nil
+}
+
+// mockEventDataSync returns the tags of the tasks of the index mock, describing a task fails with describeErr when it's set
+type mockEventDataSync struct {
+	*mockIndexDataSync
+	tags        map[string]Tags
+	describeErr error
+}
+
+func (d *mockEventDataSync) DescribeTaskWithContext(ctx context.Context, input *datasync.DescribeTaskInput, opts ...request.Option) (*datasync.DescribeTaskOutput, error) {
+	if d.describeErr != nil {
+		return nil, d.describeErr
+	}
+
+	return d.mockIndexDataSync.DescribeTaskWithContext(ctx, input, opts...)
+}
+
+func (d *mockEventDataSync) ListTasksPagesWithContext(ctx context.Context, input *datasync.ListTasksInput, fn func(*datasync.ListTasksOutput, bool) bool, opts ...request.Option) error {
+	out := &datasync.ListTasksOutput{}
+	for tArn := range d.names {
+		out.Tasks = append(out.Tasks, &datasync.TaskListEntry{TaskArn: aws.String(tArn)})
+	}
+	fn(out, true)
+
+	return nil
+}
+
+func (d *mockEventDataSync) ListTagsForResourceWithContext(ctx context.Context, input *datasync.ListTagsForResourceInput, opts ...request.Option) (*datasync.ListTagsForResourceOutput, error) {
+	tags := d.tags[aws.StringValue(input.ResourceArn)]
+	return &datasync.ListTagsForResourceOutput{Tags: tags.toDatasyncTags()}, nil
+}
+
+func testTaskArn(i int) string {
+	return fmt.Sprintf("arn:aws:datasync:us-east-1:012345678901:task/task-%017d", i)
+}
+
+// stateChangeMessage returns a queue message with an execution state change event in us-east-1
+func stateChangeMessage(id, execArn, state string) *sqs.Message {
+	return regionStateChangeMessage(id, "us-east-1", execArn, state)
+}
+
+// regionStateChangeMessage returns a queue message with an execution state change event in a region
+func regionStateChangeMessage(id, region, execArn, state string) *sqs.Message {
+	return &sqs.Message{
+		MessageId:     aws.String(id),
+		ReceiptHandle: aws.String("receipt-" + id),
+		Body: aws.String(fmt.Sprintf(`{
+			"source": "aws.datasync",
+			"detail-type": "DataSync Task Execution State Change",
+			"account": "012345678901",
+			"region": %q,
+			"resources": [%q],
+			"detail": {"State": %q}
+		}`, region, execArn, state)),
+	}
+}
+
+// newTestEventConsumer returns a consumer for two movers, mover0 in the org with a webhook and mover1 in another org
+func newTestEventConsumer(t *testing.T, webhook string) (*eventConsumer, *notifier, *fakeEventQueue) {
+	o, ds := newMockIndexOrchestrator(t, 2)
+	o.server.finishedRuns = cache.New(finishedRunTTL, time.Hour)
+	o.server.runWatchers = newTestRunWatchers()
+	o.server.defaultAccount = accountConfig{regions: []string{"us-east-1"}}
+	o.datasyncClient = ydatasync.Datasync{Service: &mockEventDataSync{
+		mockIndexDataSync: ds,
+		tags: map[string]Tags{
+			testTaskArn(0): {
+				{Key: "spinup:org", Value: "org"},
+				{Key: "spinup:spaceid", Value: "group1"},
+				{Key: "spinup:webhook:0", Value: webhook},
+			},
+			testTaskArn(1): {
+				{Key: "spinup:org", Value: "other"},
+				{Key: "spinup:spaceid", Value: "group1"},
+			},
+		},
+	}}
+
+	n := newTestNotifier(o)
+	q := &fakeEventQueue{}
+
+	return newEventConsumer(o.server, q, func(ctx context.Context, account, region string) (*datasyncOrchestrator, error) {
+		return o, nil
+	}), n, q
+}
+
+func Test_eventConsumerHandle(t *testing.T) {
+	h := &webhookReceiver{}
+	srv := httptest.NewServer(h)
+	defer srv.Close()
+
+	c, n, _ := newTestEventConsumer(t, srv.URL)
+
+	tests := []struct {
+		name     string
+		msg      *sqs.Message
+		state    string
+		counted  bool
+		finished bool
+	}{
+		{
+			name: "not json",
+			msg:  &sqs.Message{MessageId: aws.String("1"), Body: aws.String("not json")},
+		},
+		{
+			name: "other event",
+			msg:  &sqs.Message{MessageId: aws.String("2"), Body: aws.String(`{"source": "aws.s3", "detail-type": "Object Created"}`)},
+		},
+		{
+			name: "missing execution",
+			msg:  stateChangeMessage("3", testTaskArn(0), datasync.TaskExecutionStatusSuccess),
+		},
+		{
+			name: "other org",
+			msg:  stateChangeMessage("4", testTaskArn(1)+"/execution/exec-2", datasync.TaskExecutionStatusSuccess),
+		},
+		{
+			name: "unknown task",
+			msg:  stateChangeMessage("5", testTaskArn(7)+"/execution/exec-2", datasync.TaskExecutionStatusSuccess),
+		},
+		{
+			name: "region not configured",
+			msg:  regionStateChangeMessage("9", "us-west-2", "arn:aws:datasync:us-west-2:012345678901:task/task-00000000000000000/execution/exec-2", datasync.TaskExecutionStatusSuccess),
+		},
+		{
+			name:    "running",
+			msg:     stateChangeMessage("6", testTaskArn(0)+"/execution/exec-3", datasync.TaskExecutionStatusTransferring),
+			state:   datasync.TaskExecutionStatusTransferring,
+			counted: true,
+		},
+		{
+			name:     "finished",
+			msg:      stateChangeMessage("7", testTaskArn(0)+"/execution/exec-2", datasync.TaskExecutionStatusSuccess),
+			state:    datasync.TaskExecutionStatusSuccess,
+			counted:  true,
+			finished: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var before float64
+			if tt.state != "" {
+				before = testutil.ToFloat64(runStateChanges.WithLabelValues(tt.state))
+			}
+
+			if err := c.handle(context.TODO(), tt.msg); err != nil {
+				t.Fatalf("expected nil error, got %s", err)
+			}
+
+			if tt.state != "" {
+				want := before
+				if tt.counted {
+					want++
+				}
+				assert.Equal(t, want, testutil.ToFloat64(runStateChanges.WithLabelValues(tt.state)))
+			}
+
+			if tt.finished {
+				_, ok := c.server.finishedRuns.Get(testTaskArn(0) + "/execution/exec-2")
+				assert.True(t, ok, "expected the finished run to be cached")
+			}
+		})
+	}
+
+	// the run finished before the mover was watched, but the event notifies the webhook
+	d := waitForDeliveries(t, n, testTaskArn(0), 1)
+	assert.True(t, d[0].Delivered)
+	assert.Equal(t, webhookEventSucceeded, d[0].Event)
+	assert.Equal(t, "exec-2", d[0].RunId)
+
+	// a repeated event isn't notified again
+	if err := c.handle(context.TODO(), stateChangeMessage("8", testTaskArn(0)+"/execution/exec-2", datasync.TaskExecutionStatusSuccess)); err != nil {
+		t.Fatalf("expected nil error, got %s", err)
+	}
+	time.Sleep(10 * time.Millisecond)
+	assert.Len(t, deliveryLog(t, n, testTaskArn(0)), 1)
+
+	// the other org's mover isn't watched
+	n.mu.Lock()
+	assert.NotContains(t, n.movers, testTaskArn(1))
+	n.mu.Unlock()
+}
+
+func Test_eventConsumerHandleRetried(t *testing.T) {
+	c, _, _ := newTestEventConsumer(t, "https://hooks.example.edu")
+
+	// DataSync errors other than a missing task are mapped to bad requests too, the task still exists so the event is retried
+	o, err := c.orchestrator(context.TODO(), "012345678901", "us-east-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	o.datasyncClient.Service.(*mockEventDataSync).describeErr = awserr.New("ThrottlingException", "rate exceeded", nil)
+
+	if err := c.handle(context.TODO(), stateChangeMessage("1", testTaskArn(0)+"/execution/exec-2", datasync.TaskExecutionStatusSuccess)); err == nil {
+		t.Error("expected error for an existing task, got nil")
+	}
+
+	// a task that doesn't exist is still ignored
+	if err := c.handle(context.TODO(), stateChangeMessage("2", testTaskArn(7)+"/execution/exec-2", datasync.TaskExecutionStatusSuccess)); err != nil {
+		t.Errorf("expected nil error for a missing task, got %s", err)
+	}
+}
+
+func Test_eventConsumerRun(t *testing.T) {
+	c, _, q := newTestEventConsumer(t, "https://hooks.example.edu")
+	c.retryInterval = time.Millisecond
+
+	q.failures = 2
+	q.pending = []*sqs.Message{
+		stateChangeMessage("1", testTaskArn(0)+"/execution/exec-3", datasync.TaskExecutionStatusTransferring),
+		{MessageId: aws.String("2"), ReceiptHandle: aws.String("receipt-2"), Body: aws.String("not json")},
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		c.run(ctx)
+		close(done)
+	}()
+
+	timeout := time.Now().Add(5 * time.Second)
+	for time.Now().Before(timeout) {
+		q.mu.Lock()
+		n := len(q.deleted)
+		q.mu.Unlock()
+
+		if n == 2 {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	cancel()
+	<-done
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	assert.Equal(t, []string{"receipt-1", "receipt-2"}, q.deleted)
+	assert.Equal(t, 0, q.failures)
+}
@@ -19,13 +19,15 @@ package api
 import (
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
-	"regexp"
 	"strconv"
+	"time"
 
 	"github.com/YaleSpinup/apierror"
 	"github.com/gorilla/mux"
 	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
 )
 
 // MoverCreateHandler creates a new Datasync mover
@@ -35,33 +37,135 @@ func (s *server) MoverCreateHandler(w http.ResponseWriter, r *http.Request) {
 	account := vars["account"]
 	group := vars["group"]
 
+	// a dry run returns the plan of the resources the request would create, without creating them
+	dryRun := false
+	if d := r.URL.Query().Get("dryRun"); d != "" {
+		v, err := strconv.ParseBool(d)
+		if err != nil {
+			handleError(w, apierror.New(apierror.ErrBadRequest, "dryRun must be true or false", nil))
+			return
+		}
+		dryRun = v
+	}
+
+	// the raw body identifies retries of the same request made with an Idempotency-Key
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		handleError(w, apierror.New(apierror.ErrBadRequest, "failed to read request body", err))
+		return
+	}
+
 	// read the input against our struct in api/types.go
 	req := DatamoverCreateRequest{}
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+	if err := json.Unmarshal(body, &req); err != nil {
 		msg := fmt.Sprintf("cannot decode body into create data mover input: %s", err)
 		handleError(w, apierror.New(apierror.ErrBadRequest, msg, err))
 		return
 	}
 
-	if req.Name == nil {
-		handleError(w, apierror.New(apierror.ErrBadRequest, "Name is a required field", nil))
+	if err := validateMoverName(req.Name); err != nil {
+		handleError(w, err)
 		return
 	}
 
-	if len(*req.Name) > 40 {
-		handleError(w, apierror.New(apierror.ErrBadRequest, "Name cannot exceed 40 characters ", nil))
+	if req.Source == nil || req.Destination == nil || req.Source.Type == "" || req.Destination.Type == "" {
+		handleError(w, apierror.New(apierror.ErrBadRequest, "Source and Destination are required", nil))
 		return
 	}
 
-	regexName := "^[a-zA-Z0-9-]+$"
-	re := regexp.MustCompile(regexName)
-	if !re.MatchString(*req.Name) {
-		handleError(w, apierror.New(apierror.ErrBadRequest, "Name doesn't match regex "+regexName, nil))
+	if err := validateLocationInput(req.Source); err != nil {
+		handleError(w, err)
 		return
 	}
 
-	if req.Source == nil || req.Destination == nil || req.Source.Type == "" || req.Destination.Type == "" {
-		handleError(w, apierror.New(apierror.ErrBadRequest, "Source and Destination are required", nil))
+	if err := validateLocationInput(req.Destination); err != nil {
+		handleError(w, err)
+		return
+	}
+
+	if req.BytesPerSecond != nil {
+		if req.Options != nil && req.Options.BytesPerSecond != nil {
+			handleError(w, apierror.New(apierror.ErrBadRequest, "BytesPerSecond cannot be passed in both the request and Options", nil))
+			return
+		}
+
+		if req.Options == nil {
+			req.Options = &DatamoverOptions{}
+		}
+		req.Options.BytesPerSecond = req.BytesPerSecond
+	}
+
+	if err := validateOptions(req.Options, req.Destination, req.Logging != nil); err != nil {
+		handleError(w, err)
+		return
+	}
+
+	if err := validateLogging(req.Logging); err != nil {
+		handleError(w, err)
+		return
+	}
+
+	if err := validateReport(req.Report); err != nil {
+		handleError(w, err)
+		return
+	}
+
+	if err := validateManifest(req.Manifest); err != nil {
+		handleError(w, err)
+		return
+	}
+
+	if err := validateFilterPatterns("Includes", req.Includes); err != nil {
+		handleError(w, err)
+		return
+	}
+
+	if err := validateFilterPatterns("Excludes", req.Excludes); err != nil {
+		handleError(w, err)
+		return
+	}
+
+	if req.Schedule != nil {
+		if _, err := parseSchedule(*req.Schedule); err != nil {
+			handleError(w, err)
+			return
+		}
+	}
+
+	if dryRun {
+		orch, err := s.newDatasyncOrchestrator(
+			r.Context(),
+			account,
+			&sessionParams{
+				region: r.URL.Query().Get("region"),
+				policyArns: []string{
+					"arn:aws:iam::aws:policy/AWSDataSyncReadOnlyAccess",
+					"arn:aws:iam::aws:policy/CloudWatchLogsReadOnlyAccess",
+					"arn:aws:iam::aws:policy/IAMReadOnlyAccess",
+					"arn:aws:iam::aws:policy/ResourceGroupsandTagEditorReadOnlyAccess",
+				},
+			},
+		)
+		if err != nil {
+			handleError(w, errors.Wrap(err, "unable to create datasync orchestrator"))
+			return
+		}
+
+		plan, err := orch.datamoverPlan(r.Context(), group, &req)
+		if err != nil {
+			handleError(w, err)
+			return
+		}
+
+		j, err := json.Marshal(plan)
+		if err != nil {
+			handleError(w, apierror.New(apierror.ErrInternalError, "failed to marshal json", err))
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write(j)
 		return
 	}
 
@@ -75,9 +179,10 @@ func (s *server) MoverCreateHandler(w http.ResponseWriter, r *http.Request) {
 		r.Context(),
 		account,
 		&sessionParams{
-			role: fmt.Sprintf("arn:aws:iam::%s:role/%s", account, s.session.RoleName),
+			region: r.URL.Query().Get("region"),
 			policyArns: []string{
 				"arn:aws:iam::aws:policy/AWSDataSyncFullAccess",
+				"arn:aws:iam::aws:policy/ResourceGroupsandTagEditorReadOnlyAccess",
 			},
 			inlinePolicy: policy,
 		},
@@ -87,12 +192,37 @@ func (s *server) MoverCreateHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	idempotencyKey := r.Header.Get("Idempotency-Key")
+	if idempotencyKey != "" {
+		taskID, err := s.reserveIdempotencyKey(r.Context(), orch.account, orch.region, group, idempotencyKey, body)
+		if err != nil {
+			handleError(w, err)
+			return
+		}
+
+		// this request has already been made, return the original task
+		if taskID != "" {
+			w.Header().Set("Content-Type", "application/json")
+			w.Header().Set("X-Flywheel-Task", taskID)
+			w.WriteHeader(http.StatusAccepted)
+			return
+		}
+	}
+
 	task, err := orch.datamoverCreate(r.Context(), group, &req)
 	if err != nil {
+		if idempotencyKey != "" {
+			s.releaseIdempotencyKey(r.Context(), orch.account, orch.region, group, idempotencyKey)
+		}
+
 		handleError(w, err)
 		return
 	}
 
+	if idempotencyKey != "" {
+		s.completeIdempotencyKey(r.Context(), orch.account, orch.region, group, idempotencyKey, body, task.ID)
+	}
+
 	w.Header().Set("Content-Type", "application/json")
 	w.Header().Set("X-Flywheel-Task", task.ID)
 	w.WriteHeader(http.StatusAccepted)
@@ -116,7 +246,7 @@ func (s *server) MoverDeleteHandler(w http.ResponseWriter, r *http.Request) {
 		r.Context(),
 		account,
 		&sessionParams{
-			role: fmt.Sprintf("arn:aws:iam::%s:role/%s", account, s.session.RoleName),
+			region: r.URL.Query().Get("region"),
 			policyArns: []string{
 				"arn:aws:iam::aws:policy/AWSDataSyncFullAccess",
 				"arn:aws:iam::aws:policy/ResourceGroupsandTagEditorReadOnlyAccess",
@@ -129,26 +259,36 @@ func (s *server) MoverDeleteHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := orch.datamoverDelete(r.Context(), group, name); err != nil {
+	task, err := orch.datamoverDelete(r.Context(), group, name)
+	if err != nil {
 		handleError(w, err)
 		return
 	}
 
-	w.WriteHeader(http.StatusNoContent)
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("X-Flywheel-Task", task.ID)
+	w.WriteHeader(http.StatusAccepted)
 }
 
-// MoverListHandler lists all of the data movers in a group by id
+// MoverListHandler lists all of the data movers in a group by id, in a region or in all of the configured regions
 func (s *server) MoverListHandler(w http.ResponseWriter, r *http.Request) {
 	w = LogWriter{w}
 	vars := mux.Vars(r)
 	account := vars["account"]
 	group := vars["group"]
 
-	orch, err := s.newDatasyncOrchestrator(
+	input, err := parseListInput(r.URL.Query())
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	// without a region, movers are listed in all of the configured regions
+	orchs, err := s.newRegionalOrchestrators(
 		r.Context(),
 		account,
 		&sessionParams{
-			role: fmt.Sprintf("arn:aws:iam::%s:role/%s", account, s.session.RoleName),
+			region: r.URL.Query().Get("region"),
 			policyArns: []string{
 				"arn:aws:iam::aws:policy/AWSDataSyncReadOnlyAccess",
 				"arn:aws:iam::aws:policy/ResourceGroupsandTagEditorReadOnlyAccess",
@@ -160,19 +300,52 @@ func (s *server) MoverListHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	resp, err := orch.datamoverList(r.Context(), group)
+	// without filters, expansion or pagination, return the names of all of the movers
+	if input == nil {
+		resp, err := datamoverListRegions(r.Context(), orchs, group)
+		if err != nil {
+			handleError(w, err)
+			return
+		}
+
+		j, err := json.Marshal(resp)
+		if err != nil {
+			handleError(w, apierror.New(apierror.ErrInternalError, "failed to marshal json", err))
+			return
+		}
+
+		w.Header().Set("X-Items", strconv.Itoa(len(resp)))
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write(j)
+		return
+	}
+
+	movers, next, err := datamoverListPage(r.Context(), orchs, group, input)
 	if err != nil {
 		handleError(w, err)
 		return
 	}
 
-	j, err := json.Marshal(resp)
+	var j []byte
+	if input.Expand {
+		j, err = json.Marshal(movers)
+	} else {
+		names := make([]string, 0, len(movers))
+		for _, m := range movers {
+			names = append(names, m.Name)
+		}
+		j, err = json.Marshal(names)
+	}
 	if err != nil {
 		handleError(w, apierror.New(apierror.ErrInternalError, "failed to marshal json", err))
 		return
 	}
 
-	w.Header().Set("X-Items", strconv.Itoa(len(resp)))
+	if next != "" {
+		w.Header().Set("X-Next-Cursor", next)
+	}
+	w.Header().Set("X-Items", strconv.Itoa(len(movers)))
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
 	w.Write(j)
@@ -190,7 +363,7 @@ func (s *server) MoverShowHandler(w http.ResponseWriter, r *http.Request) {
 		r.Context(),
 		account,
 		&sessionParams{
-			role: fmt.Sprintf("arn:aws:iam::%s:role/%s", account, s.session.RoleName),
+			region: r.URL.Query().Get("region"),
 			policyArns: []string{
 				"arn:aws:iam::aws:policy/AWSDataSyncReadOnlyAccess",
 				"arn:aws:iam::aws:policy/ResourceGroupsandTagEditorReadOnlyAccess",
@@ -219,6 +392,103 @@ func (s *server) MoverShowHandler(w http.ResponseWriter, r *http.Request) {
 	w.Write(j)
 }
 
+// MoverPatchHandler updates the configuration of a Datasync mover
+func (s *server) MoverPatchHandler(w http.ResponseWriter, r *http.Request) {
+	w = LogWriter{w}
+	vars := mux.Vars(r)
+	account := vars["account"]
+	group := vars["group"]
+	name := vars["name"]
+
+	req := DatamoverUpdateRequest{}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		msg := fmt.Sprintf("cannot decode body into update data mover input: %s", err)
+		handleError(w, apierror.New(apierror.ErrBadRequest, msg, err))
+		return
+	}
+
+	if req.Name == nil && req.Options == nil && req.BytesPerSecond == nil && req.Schedule == nil && req.Includes == nil && req.Excludes == nil && req.Tags == nil {
+		handleError(w, apierror.New(apierror.ErrBadRequest, "nothing to update", nil))
+		return
+	}
+
+	if req.Name != nil {
+		if err := validateMoverName(req.Name); err != nil {
+			handleError(w, err)
+			return
+		}
+	}
+
+	if req.BytesPerSecond != nil && req.Options != nil && req.Options.BytesPerSecond != nil {
+		handleError(w, apierror.New(apierror.ErrBadRequest, "BytesPerSecond cannot be passed in both the request and Options", nil))
+		return
+	}
+
+	// an empty schedule removes the existing schedule
+	if req.Schedule != nil && *req.Schedule != "" {
+		if _, err := parseSchedule(*req.Schedule); err != nil {
+			handleError(w, err)
+			return
+		}
+	}
+
+	if err := validateFilterPatterns("Includes", req.Includes); err != nil {
+		handleError(w, err)
+		return
+	}
+
+	if err := validateFilterPatterns("Excludes", req.Excludes); err != nil {
+		handleError(w, err)
+		return
+	}
+
+	orch, err := s.newDatasyncOrchestrator(
+		r.Context(),
+		account,
+		&sessionParams{
+			region: r.URL.Query().Get("region"),
+			policyArns: []string{
+				"arn:aws:iam::aws:policy/AWSDataSyncFullAccess",
+				"arn:aws:iam::aws:policy/ResourceGroupsandTagEditorReadOnlyAccess",
+			},
+		},
+	)
+	if err != nil {
+		handleError(w, errors.Wrap(err, "unable to create datasync orchestrator"))
+		return
+	}
+
+	// tag changes are applied to the task and both locations, so they're run as an async flywheel task
+	if req.Tags != nil {
+		task, err := orch.datamoverUpdateAsync(r.Context(), group, name, &req)
+		if err != nil {
+			handleError(w, err)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("X-Flywheel-Task", task.ID)
+		w.WriteHeader(http.StatusAccepted)
+		return
+	}
+
+	resp, err := orch.datamoverUpdate(r.Context(), group, name, &req)
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	j, err := json.Marshal(resp)
+	if err != nil {
+		handleError(w, apierror.New(apierror.ErrInternalError, "failed to marshal json", err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write(j)
+}
+
 func (s *server) MoverUpdateHandler(w http.ResponseWriter, r *http.Request) {
 	req := MoverUpdateAction{}
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
@@ -229,10 +499,26 @@ func (s *server) MoverUpdateHandler(w http.ResponseWriter, r *http.Request) {
 
 	if req.State == nil {
 		handleError(w, apierror.New(apierror.ErrBadRequest, "missing required parameter: State", nil))
+		return
 	}
 
 	if *req.State == "start" {
-		s.StartTaskHandler(w, r)
+		if err := validateFilterPatterns("Includes", req.Includes); err != nil {
+			handleError(w, err)
+			return
+		}
+
+		if err := validateFilterPatterns("Excludes", req.Excludes); err != nil {
+			handleError(w, err)
+			return
+		}
+
+		if err := validateManifest(req.Manifest); err != nil {
+			handleError(w, err)
+			return
+		}
+
+		s.StartTaskHandler(w, r, &req.DatamoverRunOverrides)
 	} else if *req.State == "stop" {
 		s.StopTaskHandler(w, r)
 	} else {
@@ -240,29 +526,42 @@ func (s *server) MoverUpdateHandler(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
-func (s *server) StartTaskHandler(w http.ResponseWriter, r *http.Request) {
+func (s *server) StartTaskHandler(w http.ResponseWriter, r *http.Request, overrides *DatamoverRunOverrides) {
 	w = LogWriter{w}
 	vars := mux.Vars(r)
 	account := vars["account"]
 	group := vars["group"]
 	name := vars["name"]
 
+	// runs with a manifest create or update the manifest role
+	var policy string
+	if overrides != nil && overrides.Manifest != nil {
+		p, err := s.moverManifestPolicy()
+		if err != nil {
+			handleError(w, apierror.New(apierror.ErrInternalError, "failed to generate policy", err))
+			return
+		}
+		policy = p
+	}
+
 	orch, err := s.newDatasyncOrchestrator(
 		r.Context(),
 		account,
 		&sessionParams{
-			role: fmt.Sprintf("arn:aws:iam::%s:role/%s", account, s.session.RoleName),
+			region: r.URL.Query().Get("region"),
 			policyArns: []string{
 				"arn:aws:iam::aws:policy/AWSDataSyncFullAccess",
 				"arn:aws:iam::aws:policy/ResourceGroupsandTagEditorReadOnlyAccess",
-			}},
+			},
+			inlinePolicy: policy,
+		},
 	)
 	if err != nil {
 		handleError(w, errors.Wrap(err, "unable to create datasync orchestrator"))
 		return
 	}
 
-	resp, err := orch.startTaskRun(r.Context(), group, name)
+	resp, err := orch.startTaskRun(r.Context(), group, name, overrides)
 	if err != nil {
 		handleError(w, err)
 		return
@@ -290,7 +589,7 @@ func (s *server) StopTaskHandler(w http.ResponseWriter, r *http.Request) {
 		r.Context(),
 		account,
 		&sessionParams{
-			role: fmt.Sprintf("arn:aws:iam::%s:role/%s", account, s.session.RoleName),
+			region: r.URL.Query().Get("region"),
 			policyArns: []string{
 				"arn:aws:iam::aws:policy/AWSDataSyncFullAccess",
 				"arn:aws:iam::aws:policy/ResourceGroupsandTagEditorReadOnlyAccess",
@@ -317,11 +616,17 @@ func (s *server) RunListHandler(w http.ResponseWriter, r *http.Request) {
 	group := vars["group"]
 	name := vars["name"]
 
+	input, err := parseRunListInput(r.URL.Query())
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
 	orch, err := s.newDatasyncOrchestrator(
 		r.Context(),
 		account,
 		&sessionParams{
-			role: fmt.Sprintf("arn:aws:iam::%s:role/%s", account, s.session.RoleName),
+			region: r.URL.Query().Get("region"),
 			policyArns: []string{
 				"arn:aws:iam::aws:policy/AWSDataSyncReadOnlyAccess",
 				"arn:aws:iam::aws:policy/ResourceGroupsandTagEditorReadOnlyAccess",
@@ -332,18 +637,51 @@ func (s *server) RunListHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	resp, err := orch.datamoverRunList(r.Context(), group, name)
+	// without filters, expansion or pagination, return the ids of all of the runs
+	if input == nil {
+		resp, err := orch.datamoverRunList(r.Context(), group, name)
+		if err != nil {
+			handleError(w, err)
+			return
+		}
+
+		j, err := json.Marshal(resp)
+		if err != nil {
+			handleError(w, apierror.New(apierror.ErrInternalError, "failed to marshal json", err))
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write(j)
+		return
+	}
+
+	runs, next, err := orch.datamoverRunListPage(r.Context(), group, name, input)
 	if err != nil {
 		handleError(w, err)
 		return
 	}
 
-	j, err := json.Marshal(resp)
+	var j []byte
+	if input.Expand {
+		j, err = json.Marshal(runs)
+	} else {
+		ids := make([]string, 0, len(runs))
+		for _, run := range runs {
+			ids = append(ids, run.Id)
+		}
+		j, err = json.Marshal(ids)
+	}
 	if err != nil {
 		handleError(w, apierror.New(apierror.ErrInternalError, "failed to marshal json", err))
 		return
 	}
 
+	if next != "" {
+		w.Header().Set("X-Next-Cursor", next)
+	}
+	w.Header().Set("X-Items", strconv.Itoa(len(runs)))
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
 	w.Write(j)
@@ -361,7 +699,7 @@ func (s *server) RunShowHandler(w http.ResponseWriter, r *http.Request) {
 		r.Context(),
 		account,
 		&sessionParams{
-			role: fmt.Sprintf("arn:aws:iam::%s:role/%s", account, s.session.RoleName),
+			region: r.URL.Query().Get("region"),
 			policyArns: []string{
 				"arn:aws:iam::aws:policy/AWSDataSyncReadOnlyAccess",
 				"arn:aws:iam::aws:policy/ResourceGroupsandTagEditorReadOnlyAccess",
@@ -389,3 +727,332 @@ func (s *server) RunShowHandler(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusOK)
 	w.Write(j)
 }
+
+// WebhooksShowHandler returns the webhooks of a Datasync mover and the most recent deliveries
+func (s *server) WebhooksShowHandler(w http.ResponseWriter, r *http.Request) {
+	w = LogWriter{w}
+	vars := mux.Vars(r)
+	account := vars["account"]
+	group := vars["group"]
+	name := vars["name"]
+
+	orch, err := s.newDatasyncOrchestrator(
+		r.Context(),
+		account,
+		&sessionParams{
+			region: r.URL.Query().Get("region"),
+			policyArns: []string{
+				"arn:aws:iam::aws:policy/AWSDataSyncReadOnlyAccess",
+				"arn:aws:iam::aws:policy/ResourceGroupsandTagEditorReadOnlyAccess",
+			},
+		},
+	)
+	if err != nil {
+		handleError(w, errors.Wrap(err, "unable to create datasync orchestrator"))
+		return
+	}
+
+	resp, err := orch.datamoverWebhooks(r.Context(), group, name)
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	j, err := json.Marshal(resp)
+	if err != nil {
+		handleError(w, apierror.New(apierror.ErrInternalError, "failed to marshal json", err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write(j)
+}
+
+// WebhooksUpdateHandler replaces the webhooks of a Datasync mover
+func (s *server) WebhooksUpdateHandler(w http.ResponseWriter, r *http.Request) {
+	w = LogWriter{w}
+	vars := mux.Vars(r)
+	account := vars["account"]
+	group := vars["group"]
+	name := vars["name"]
+
+	req := DatamoverWebhooksRequest{}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		msg := fmt.Sprintf("cannot decode body into update webhooks input: %s", err)
+		handleError(w, apierror.New(apierror.ErrBadRequest, msg, err))
+		return
+	}
+
+	if req.Urls == nil {
+		req.Urls = []string{}
+	}
+
+	if err := validateWebhookUrls(req.Urls); err != nil {
+		handleError(w, err)
+		return
+	}
+
+	orch, err := s.newDatasyncOrchestrator(
+		r.Context(),
+		account,
+		&sessionParams{
+			region: r.URL.Query().Get("region"),
+			policyArns: []string{
+				"arn:aws:iam::aws:policy/AWSDataSyncFullAccess",
+				"arn:aws:iam::aws:policy/ResourceGroupsandTagEditorReadOnlyAccess",
+			},
+		},
+	)
+	if err != nil {
+		handleError(w, errors.Wrap(err, "unable to create datasync orchestrator"))
+		return
+	}
+
+	resp, err := orch.datamoverWebhooksUpdate(r.Context(), group, name, &req)
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	j, err := json.Marshal(resp)
+	if err != nil {
+		handleError(w, apierror.New(apierror.ErrInternalError, "failed to marshal json", err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write(j)
+}
+
+// RunEventsHandler streams the progress of a Datasync mover run as Server-Sent Events
+func (s *server) RunEventsHandler(w http.ResponseWriter, r *http.Request) {
+	w = LogWriter{w}
+	vars := mux.Vars(r)
+	account := vars["account"]
+	group := vars["group"]
+	name := vars["name"]
+	id := vars["id"]
+
+	orch, err := s.newDatasyncOrchestrator(
+		r.Context(),
+		account,
+		&sessionParams{
+			region: r.URL.Query().Get("region"),
+			policyArns: []string{
+				"arn:aws:iam::aws:policy/AWSDataSyncReadOnlyAccess",
+				"arn:aws:iam::aws:policy/ResourceGroupsandTagEditorReadOnlyAccess",
+			},
+		},
+	)
+	if err != nil {
+		handleError(w, errors.Wrap(err, "unable to create datasync orchestrator"))
+		return
+	}
+
+	events, unsubscribe, err := orch.datamoverRunEvents(r.Context(), group, name, id)
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+	defer unsubscribe()
+
+	// the stream outlives the server write timeout
+	rc := http.NewResponseController(w)
+	if err := rc.SetWriteDeadline(time.Time{}); err != nil {
+		log.Warnf("failed to clear write deadline for run events: %s", err)
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	rc.Flush()
+
+	keepalive := time.NewTicker(runEventsKeepalive)
+	defer keepalive.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-keepalive.C:
+			if _, err := io.WriteString(w, ": keepalive\n\n"); err != nil {
+				return
+			}
+		case ev, ok := <-events:
+			if !ok {
+				return
+			}
+
+			if err := writeRunEvent(w, ev); err != nil {
+				return
+			}
+
+			if ev.Final {
+				rc.Flush()
+				return
+			}
+		}
+
+		if err := rc.Flush(); err != nil {
+			return
+		}
+	}
+}
+
+// RunLogsHandler returns a page of log events for a Datasync mover run
+func (s *server) RunLogsHandler(w http.ResponseWriter, r *http.Request) {
+	w = LogWriter{w}
+	vars := mux.Vars(r)
+	account := vars["account"]
+	group := vars["group"]
+	name := vars["name"]
+	id := vars["id"]
+
+	limit := int64(100)
+	if l := r.URL.Query().Get("limit"); l != "" {
+		v, err := strconv.ParseInt(l, 10, 64)
+		if err != nil || v < 1 || v > 10000 {
+			handleError(w, apierror.New(apierror.ErrBadRequest, "limit must be between 1 and 10000", nil))
+			return
+		}
+		limit = v
+	}
+
+	orch, err := s.newDatasyncOrchestrator(
+		r.Context(),
+		account,
+		&sessionParams{
+			region: r.URL.Query().Get("region"),
+			policyArns: []string{
+				"arn:aws:iam::aws:policy/AWSDataSyncReadOnlyAccess",
+				"arn:aws:iam::aws:policy/CloudWatchLogsReadOnlyAccess",
+				"arn:aws:iam::aws:policy/ResourceGroupsandTagEditorReadOnlyAccess",
+			},
+		},
+	)
+	if err != nil {
+		handleError(w, errors.Wrap(err, "unable to create datasync orchestrator"))
+		return
+	}
+
+	resp, err := orch.datamoverRunLogs(r.Context(), group, name, id, limit, r.URL.Query().Get("next"))
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	j, err := json.Marshal(resp)
+	if err != nil {
+		handleError(w, apierror.New(apierror.ErrInternalError, "failed to marshal json", err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write(j)
+}
+
+// RunReportHandler returns a summary of the task report for a Datasync mover run
+func (s *server) RunReportHandler(w http.ResponseWriter, r *http.Request) {
+	w = LogWriter{w}
+	vars := mux.Vars(r)
+	account := vars["account"]
+	group := vars["group"]
+	name := vars["name"]
+	id := vars["id"]
+
+	orch, err := s.newDatasyncOrchestrator(
+		r.Context(),
+		account,
+		&sessionParams{
+			region: r.URL.Query().Get("region"),
+			policyArns: []string{
+				"arn:aws:iam::aws:policy/AWSDataSyncReadOnlyAccess",
+				"arn:aws:iam::aws:policy/AmazonS3ReadOnlyAccess",
+				"arn:aws:iam::aws:policy/ResourceGroupsandTagEditorReadOnlyAccess",
+			},
+		},
+	)
+	if err != nil {
+		handleError(w, errors.Wrap(err, "unable to create datasync orchestrator"))
+		return
+	}
+
+	resp, err := orch.datamoverRunReport(r.Context(), group, name, id)
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	j, err := json.Marshal(resp)
+	if err != nil {
+		handleError(w, apierror.New(apierror.ErrInternalError, "failed to marshal json", err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write(j)
+}
+
+// RunUpdateHandler updates the bandwidth limit of a running Datasync mover run
+func (s *server) RunUpdateHandler(w http.ResponseWriter, r *http.Request) {
+	w = LogWriter{w}
+	vars := mux.Vars(r)
+	account := vars["account"]
+	group := vars["group"]
+	name := vars["name"]
+	id := vars["id"]
+
+	req := DatamoverRunUpdateRequest{}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		msg := fmt.Sprintf("cannot decode body into update data mover run input: %s", err)
+		handleError(w, apierror.New(apierror.ErrBadRequest, msg, err))
+		return
+	}
+
+	if req.BytesPerSecond == nil {
+		handleError(w, apierror.New(apierror.ErrBadRequest, "missing required parameter: BytesPerSecond", nil))
+		return
+	}
+
+	if err := validateBandwidth(req.BytesPerSecond); err != nil {
+		handleError(w, err)
+		return
+	}
+
+	orch, err := s.newDatasyncOrchestrator(
+		r.Context(),
+		account,
+		&sessionParams{
+			region: r.URL.Query().Get("region"),
+			policyArns: []string{
+				"arn:aws:iam::aws:policy/AWSDataSyncFullAccess",
+				"arn:aws:iam::aws:policy/ResourceGroupsandTagEditorReadOnlyAccess",
+			},
+		},
+	)
+	if err != nil {
+		handleError(w, errors.Wrap(err, "unable to create datasync orchestrator"))
+		return
+	}
+
+	resp, err := orch.datamoverRunUpdate(r.Context(), group, name, id, &req)
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	j, err := json.Marshal(resp)
+	if err != nil {
+		handleError(w, apierror.New(apierror.ErrInternalError, "failed to marshal json", err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write(j)
+}
@@ -0,0 +1,106 @@
+package api
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"time"
+
+	"github.com/YaleSpinup/apierror"
+	log "github.com/sirupsen/logrus"
+)
+
+// idempotencyTTL is how long an Idempotency-Key is remembered
+const idempotencyTTL = 24 * time.Hour
+
+// idempotentRequest is a request recorded under an Idempotency-Key
+type idempotentRequest struct {
+	// Hash is the SHA-256 hash of the request body
+	Hash string `json:"hash"`
+	// TaskID is the flywheel task started by the request, empty while the request is in progress
+	TaskID string `json:"taskId,omitempty"`
+}
+
+// idempotencyStoreKey returns the store key of an Idempotency-Key, keys are scoped to the account, region and group
+func idempotencyStoreKey(account, region, group, key string) string {
+	return "idempotency:" + account + "/" + region + "/" + group + "/" + key
+}
+
+// reserveIdempotencyKey records a request under an Idempotency-Key and returns an empty task ID, or returns
+// the flywheel task ID if the same request has already been made with the key
+func (s *server) reserveIdempotencyKey(ctx context.Context, account, region, group, key string, body []byte) (string, error) {
+	if key == "" || len(key) > 255 {
+		return "", apierror.New(apierror.ErrBadRequest, "Idempotency-Key must be between 1 and 255 characters", nil)
+	}
+
+	sum := sha256.Sum256(body)
+	hash := hex.EncodeToString(sum[:])
+	storeKey := idempotencyStoreKey(account, region, group, key)
+
+	value, err := json.Marshal(&idempotentRequest{Hash: hash})
+	if err != nil {
+		return "", apierror.New(apierror.ErrInternalError, "failed to encode idempotency key", err)
+	}
+
+	ok, err := s.store.setNX(ctx, storeKey, string(value), idempotencyTTL)
+	if err != nil {
+		return "", apierror.New(apierror.ErrInternalError, "failed to reserve idempotency key", err)
+	}
+
+	if ok {
+		log.Debugf("reserved idempotency key %s", storeKey)
+		return "", nil
+	}
+
+	v, ok, err := s.store.get(ctx, storeKey)
+	if err != nil {
+		return "", apierror.New(apierror.ErrInternalError, "failed to get idempotency key", err)
+	}
+
+	if !ok {
+		// the key expired (or was released) between setting and getting it
+		return s.reserveIdempotencyKey(ctx, account, region, group, key, body)
+	}
+
+	req := &idempotentRequest{}
+	if err := json.Unmarshal([]byte(v), req); err != nil {
+		return "", apierror.New(apierror.ErrInternalError, "invalid idempotency store entry", err)
+	}
+
+	if req.Hash != hash {
+		return "", apierror.New(apierror.ErrBadRequest, "Idempotency-Key has already been used for a different request", nil)
+	}
+
+	if req.TaskID == "" {
+		return "", apierror.New(apierror.ErrConflict, "a request with the same Idempotency-Key is in progress", nil)
+	}
+
+	log.Infof("returning flywheel task %s for idempotency key %s", req.TaskID, storeKey)
+
+	return req.TaskID, nil
+}
+
+// completeIdempotencyKey records the flywheel task started by the request made with an Idempotency-Key
+func (s *server) completeIdempotencyKey(ctx context.Context, account, region, group, key string, body []byte, taskID string) {
+	sum := sha256.Sum256(body)
+	value, err := json.Marshal(&idempotentRequest{
+		Hash:   hex.EncodeToString(sum[:]),
+		TaskID: taskID,
+	})
+	if err != nil {
+		log.Errorf("failed to encode idempotency key %s: %s", key, err)
+		return
+	}
+
+	if err := s.store.set(ctx, idempotencyStoreKey(account, region, group, key), string(value), idempotencyTTL); err != nil {
+		log.Errorf("failed to record flywheel task %s for idempotency key %s: %s", taskID, key, err)
+	}
+}
+
+// releaseIdempotencyKey forgets an Idempotency-Key when the request fails, so it can be retried
+func (s *server) releaseIdempotencyKey(ctx context.Context, account, region, group, key string) {
+	if err := s.store.del(ctx, idempotencyStoreKey(account, region, group, key)); err != nil {
+		log.Errorf("failed to release idempotency key %s: %s", key, err)
+	}
+}
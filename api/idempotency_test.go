@@ -0,0 +1,98 @@
+package api
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/YaleSpinup/apierror"
+)
+
+func TestReserveIdempotencyKey(t *testing.T) {
+	s := server{store: newMockStore()}
+	body := []byte(`{"name":"mover1"}`)
+
+	tests := []struct {
+		name    string
+		key     string
+		body    []byte
+		taskID  string
+		errCode string
+	}{
+		{"empty key", "", body, "", apierror.ErrBadRequest},
+		{"long key", strings.Repeat("k", 256), body, "", apierror.ErrBadRequest},
+		{"new key", "key1", body, "", ""},
+		{"in progress", "key1", body, "", apierror.ErrConflict},
+		{"different body", "key1", []byte(`{"name":"mover2"}`), "", apierror.ErrBadRequest},
+		{"different key", "key2", body, "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := s.reserveIdempotencyKey(context.TODO(), "012345678901", "us-east-1", "group1", tt.key, tt.body)
+			if tt.errCode != "" {
+				aerr, ok := err.(apierror.Error)
+				if !ok || aerr.Code != tt.errCode {
+					t.Errorf("expected %s error, got %v", tt.errCode, err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Errorf("expected nil error, got %s", err)
+			}
+
+			if got != tt.taskID {
+				t.Errorf("expected task id %q, got %q", tt.taskID, got)
+			}
+		})
+	}
+}
+
+func TestCompleteIdempotencyKey(t *testing.T) {
+	s := server{store: newMockStore()}
+	body := []byte(`{"name":"mover1"}`)
+
+	if _, err := s.reserveIdempotencyKey(context.TODO(), "012345678901", "us-east-1", "group1", "key1", body); err != nil {
+		t.Fatalf("expected nil error, got %s", err)
+	}
+
+	s.completeIdempotencyKey(context.TODO(), "012345678901", "us-east-1", "group1", "key1", body, "task-1234")
+
+	got, err := s.reserveIdempotencyKey(context.TODO(), "012345678901", "us-east-1", "group1", "key1", body)
+	if err != nil {
+		t.Errorf("expected nil error, got %s", err)
+	}
+
+	if got != "task-1234" {
+		t.Errorf("expected task id task-1234, got %q", got)
+	}
+
+	// keys are scoped to the group
+	got, err = s.reserveIdempotencyKey(context.TODO(), "012345678901", "us-east-1", "group2", "key1", body)
+	if err != nil || got != "" {
+		t.Errorf("expected new reservation for another group, got %q, %v", got, err)
+	}
+
+	// and to the region
+	got, err = s.reserveIdempotencyKey(context.TODO(), "012345678901", "us-west-2", "group1", "key1", body)
+	if err != nil || got != "" {
+		t.Errorf("expected new reservation for another region, got %q, %v", got, err)
+	}
+}
+
+func TestReleaseIdempotencyKey(t *testing.T) {
+	s := server{store: newMockStore()}
+
+	if _, err := s.reserveIdempotencyKey(context.TODO(), "012345678901", "us-east-1", "group1", "key1", []byte(`{"name":"mover1"}`)); err != nil {
+		t.Fatalf("expected nil error, got %s", err)
+	}
+
+	s.releaseIdempotencyKey(context.TODO(), "012345678901", "us-east-1", "group1", "key1")
+
+	// a released key can be reused, even for a different request
+	got, err := s.reserveIdempotencyKey(context.TODO(), "012345678901", "us-east-1", "group1", "key1", []byte(`{"name":"mover2"}`))
+	if err != nil || got != "" {
+		t.Errorf("expected new reservation after release, got %q, %v", got, err)
+	}
+}
@@ -19,11 +19,34 @@ package api
 import (
 	"net/http"
 	"net/url"
+	"strconv"
+	"time"
 
+	"github.com/YaleSpinup/apierror"
+	"github.com/gorilla/mux"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
 	log "github.com/sirupsen/logrus"
 	"golang.org/x/crypto/bcrypt"
 )
 
+var (
+	// httpRequests counts the requests by route template, method and status code
+	httpRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "datasync_api",
+		Name:      "http_requests_total",
+		Help:      "Number of HTTP requests, by route, method and status code.",
+	}, []string{"route", "method", "code"})
+
+	// httpRequestDuration observes the request latency by route template, method and status code
+	httpRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: "datasync_api",
+		Name:      "http_request_duration_seconds",
+		Help:      "Latency of HTTP requests, by route, method and status code.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"route", "method", "code"})
+)
+
 // TokenMiddleware checks the tokens for non-public URLs
 func TokenMiddleware(psk []byte, public map[string]string, h http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -64,3 +87,72 @@ func TokenMiddleware(psk []byte, public map[string]string, h http.Handler) http.
 		h.ServeHTTP(w, r)
 	})
 }
+
+// MetricsMiddleware records the count and latency of the requests to the matched route.  Routes are
+// labelled by their path template, so the account, group and names in the path don't add series.
+func MetricsMiddleware(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		route := "unmatched"
+		if current := mux.CurrentRoute(r); current != nil {
+			if tpl, err := current.GetPathTemplate(); err == nil {
+				route = tpl
+			}
+		}
+
+		start := time.Now()
+		sw := &statusWriter{ResponseWriter: w}
+
+		h.ServeHTTP(sw, r)
+
+		if sw.status == 0 {
+			sw.status = http.StatusOK
+		}
+		code := strconv.Itoa(sw.status)
+
+		httpRequests.WithLabelValues(route, r.Method, code).Inc()
+		httpRequestDuration.WithLabelValues(route, r.Method, code).Observe(time.Since(start).Seconds())
+	})
+}
+
+// statusWriter is an http.ResponseWriter that records the status code of the response
+type statusWriter struct {
+	http.ResponseWriter
+	status int
+}
+
+// WriteHeader records the status code and writes the header
+func (w *statusWriter) WriteHeader(code int) {
+	if w.status == 0 {
+		w.status = code
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+// Write records an implicit 200 status code and writes the body
+func (w *statusWriter) Write(p []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	return w.ResponseWriter.Write(p)
+}
+
+// Unwrap returns the underlying http.ResponseWriter, so http.ResponseController can flush streamed responses
+func (w *statusWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// GroupMiddleware returns a not found error for requests to groups that aren't allowed in the account.  Accounts
+// that aren't configured are passed through, they're rejected when the orchestrator is created.
+func (s *server) GroupMiddleware(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+		if group, ok := vars["group"]; ok {
+			if acct, err := s.accountConfig(vars["account"]); err == nil && !acct.groupAllowed(group) {
+				handleError(w, apierror.New(apierror.ErrNotFound, "group "+group+" not found in account "+vars["account"], nil))
+				return
+			}
+		}
+
+		h.ServeHTTP(w, r)
+	})
+}
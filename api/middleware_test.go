@@ -22,6 +22,8 @@ import (
 	"net/http/httptest"
 	"testing"
 
+	"github.com/gorilla/mux"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"golang.org/x/crypto/bcrypt"
 )
 
@@ -107,3 +109,33 @@ func TestTokenMiddleware(t *testing.T) {
 		}
 	}
 }
+
+func TestMetricsMiddleware(t *testing.T) {
+	router := mux.NewRouter()
+	router.Use(MetricsMiddleware)
+	router.HandleFunc("/v1/datasync/{account}/movers/{group}", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusAccepted)
+	}).Methods(http.MethodPost)
+	router.HandleFunc("/v1/datasync/{account}/movers/{group}", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("[]"))
+	}).Methods(http.MethodGet)
+
+	tests := []struct {
+		method string
+		path   string
+		code   string
+	}{
+		{http.MethodPost, "/v1/datasync/012345678901/movers/group1", "202"},
+		{http.MethodGet, "/v1/datasync/012345678901/movers/group2", "200"},
+	}
+
+	for _, tt := range tests {
+		before := testutil.ToFloat64(httpRequests.WithLabelValues("/v1/datasync/{account}/movers/{group}", tt.method, tt.code))
+
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(tt.method, tt.path, nil))
+
+		if got := testutil.ToFloat64(httpRequests.WithLabelValues("/v1/datasync/{account}/movers/{group}", tt.method, tt.code)); got != before+1 {
+			t.Errorf("expected %f %s requests with code %s, got %f", before+1, tt.method, tt.code, got)
+		}
+	}
+}
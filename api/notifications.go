@@ -0,0 +1,720 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/YaleSpinup/apierror"
+	"github.com/YaleSpinup/aws-go/services/resourcegroupstaggingapi"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/datasync"
+	"github.com/google/uuid"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// webhookTagPrefix is the prefix of the task tags that store the webhooks of a mover, ie. spinup:webhook:0
+	webhookTagPrefix = "spinup:webhook:"
+	// maxWebhooks is the maximum number of webhooks per mover
+	maxWebhooks = 5
+	// webhookAttempts is the number of times a notification is sent before giving up
+	webhookAttempts = 5
+	// webhookRetryInterval is the wait before the first retry, it doubles after each attempt
+	webhookRetryInterval = 5 * time.Second
+	// webhookTimeout is the timeout of each delivery attempt
+	webhookTimeout = 10 * time.Second
+	// maxWebhookDeliveries is the number of deliveries kept in the delivery log of a mover
+	maxWebhookDeliveries = 50
+	// webhookDeliveriesTTL is how long the delivery log of a mover is kept after its last delivery
+	webhookDeliveriesTTL = 30 * 24 * time.Hour
+	// webhookAccountsKey is the store key of the set of accounts movers with webhooks were watched in
+	webhookAccountsKey = "webhooks:accounts"
+	// notifierScanInterval is how often the movers with webhooks are checked for runs started by a schedule
+	notifierScanInterval = time.Minute
+)
+
+const (
+	webhookEventSucceeded = "run.succeeded"
+	webhookEventFailed    = "run.failed"
+)
+
+// notifier follows the runs of the movers with webhooks and notifies the webhooks when the runs finish.  Runs
+// started with startTaskRun are followed right away, runs started by a schedule are found by scanning the
+// executions of the watched movers.  The delivery logs are kept in the shared store, so they survive restarts.
+type notifier struct {
+	// ctx is cancelled when the API shuts down, it stops the deliveries waiting to be retried
+	ctx           context.Context
+	secret        []byte
+	client        *http.Client
+	retryInterval time.Duration
+	store         sharedStore
+	watchers      *runWatchers
+	// orchestrator returns a read-only orchestrator for an account in a region
+	orchestrator func(ctx context.Context, account, region string) (*datasyncOrchestrator, error)
+
+	mu     sync.Mutex
+	movers map[string]*notifierMover
+	// logMu serializes the updates of the delivery logs, so the store isn't read and written while holding mu
+	logMu sync.Mutex
+}
+
+// notifierMover is a mover watched by the notifier, by task ARN
+type notifierMover struct {
+	account string
+	region  string
+	group   string
+	name    string
+	taskArn string
+	urls    []string
+	// seen is when the executions that are followed or finished before the mover was watched were seen
+	seen map[string]time.Time
+	// watched is when the mover was watched, executions seen then weren't followed
+	watched time.Time
+}
+
+// newNotifier returns a notifier that signs the payloads with the secret
+func newNotifier(ctx context.Context, secret string, store sharedStore, watchers *runWatchers, orchestrator func(ctx context.Context, account, region string) (*datasyncOrchestrator, error)) *notifier {
+	return &notifier{
+		ctx:           ctx,
+		secret:        []byte(secret),
+		client:        newWebhookClient(),
+		retryInterval: webhookRetryInterval,
+		store:         store,
+		watchers:      watchers,
+		orchestrator:  orchestrator,
+		movers:        map[string]*notifierMover{},
+	}
+}
+
+// newWebhookClient returns the client webhooks are delivered with.  It doesn't use a proxy and refuses to connect
+// to loopback, link-local and private addresses, so a webhook host that resolves to one (ie. the instance metadata
+// endpoint) can't be reached.
+func newWebhookClient() *http.Client {
+	dialer := &net.Dialer{
+		Timeout: webhookTimeout,
+		Control: func(network, address string, c syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+
+			if ip := net.ParseIP(host); ip == nil || privateIP(ip) {
+				return fmt.Errorf("webhook address %s is not allowed", address)
+			}
+
+			return nil
+		},
+	}
+
+	return &http.Client{
+		Timeout: webhookTimeout,
+		Transport: &http.Transport{
+			DialContext:         dialer.DialContext,
+			TLSHandshakeTimeout: webhookTimeout,
+		},
+	}
+}
+
+// webhookDeliveriesKey returns the store key of the delivery log of a mover
+func webhookDeliveriesKey(taskArn string) string {
+	return "webhooks:deliveries:" + taskArn
+}
+
+// webhookSentKey returns the store key claimed by the instance of the API that notifies a webhook of a run
+func webhookSentKey(execArn, url string) string {
+	return "webhooks:sent:" + execArn + ":" + url
+}
+
+// webhookUrls returns the webhooks stored in the task tags
+func webhookUrls(tags Tags) []string {
+	type hook struct {
+		index int
+		url   string
+	}
+
+	hooks := []hook{}
+	for _, t := range tags {
+		if !strings.HasPrefix(t.Key, webhookTagPrefix) {
+			continue
+		}
+
+		i, err := strconv.Atoi(strings.TrimPrefix(t.Key, webhookTagPrefix))
+		if err != nil {
+			continue
+		}
+
+		hooks = append(hooks, hook{i, t.Value})
+	}
+
+	sort.Slice(hooks, func(i, j int) bool { return hooks[i].index < hooks[j].index })
+
+	urls := make([]string, 0, len(hooks))
+	for _, h := range hooks {
+		urls = append(urls, h.url)
+	}
+
+	return urls
+}
+
+// webhookTags returns the tags to add and the tag keys to remove to store the webhooks in the task tags
+func webhookTags(current Tags, urls []string) (Tags, []string) {
+	add := Tags{}
+	wanted := map[string]bool{}
+	for i, u := range urls {
+		key := fmt.Sprintf("%s%d", webhookTagPrefix, i)
+		wanted[key] = true
+		add = append(add, Tag{Key: key, Value: u})
+	}
+
+	remove := []string{}
+	for _, t := range current {
+		if strings.HasPrefix(t.Key, webhookTagPrefix) && !wanted[t.Key] {
+			remove = append(remove, t.Key)
+		}
+	}
+
+	return add, remove
+}
+
+// signWebhook returns the signature of a webhook payload, a hex encoded HMAC-SHA256 of the body
+func signWebhook(secret, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// run scans the watched movers until the context is cancelled
+func (n *notifier) run(ctx context.Context) {
+	ticker := time.NewTicker(notifierScanInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			n.scan(ctx)
+		}
+	}
+}
+
+// watch starts or updates watching a mover with webhooks, a mover without webhooks is forgotten.  The
+// executions of a newly watched mover are listed so that only runs started later are notified.
+func (n *notifier) watch(ctx context.Context, o *datasyncOrchestrator, group, name, taskArn string, urls []string) error {
+	if n == nil {
+		return nil
+	}
+
+	if len(urls) == 0 {
+		n.forget(ctx, taskArn)
+		return nil
+	}
+
+	n.mu.Lock()
+	if m, ok := n.movers[taskArn]; ok {
+		m.name = name
+		m.urls = urls
+		n.mu.Unlock()
+		return nil
+	}
+	n.mu.Unlock()
+
+	execs, err := o.datasyncClient.ListDatasyncTaskExecutionEntries(ctx, taskArn)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	seen := map[string]time.Time{}
+	for _, e := range execs {
+		seen[aws.StringValue(e.TaskExecutionArn)] = now
+	}
+
+	log.Infof("watching data mover %s for run notifications to %d webhooks", name, len(urls))
+
+	n.mu.Lock()
+	if m, ok := n.movers[taskArn]; ok {
+		m.name = name
+		m.urls = urls
+		n.mu.Unlock()
+		return nil
+	}
+
+	n.movers[taskArn] = &notifierMover{
+		account: o.account,
+		region:  o.region,
+		group:   group,
+		name:    name,
+		taskArn: taskArn,
+		urls:    urls,
+		seen:    seen,
+		watched: now,
+	}
+	n.mu.Unlock()
+
+	// remember the account, so its movers are restored after a restart when no accounts are configured
+	if err := n.store.sadd(ctx, webhookAccountsKey, o.account); err != nil {
+		log.Warnf("failed to remember account %s for run notifications: %s", o.account, err)
+	}
+
+	return nil
+}
+
+// forget stops watching a mover and drops its delivery log
+func (n *notifier) forget(ctx context.Context, taskArn string) {
+	if n == nil {
+		return
+	}
+
+	n.mu.Lock()
+	delete(n.movers, taskArn)
+	n.mu.Unlock()
+
+	n.logMu.Lock()
+	defer n.logMu.Unlock()
+
+	if err := n.store.del(ctx, webhookDeliveriesKey(taskArn)); err != nil {
+		log.Warnf("failed to delete the webhook delivery log of %s: %s", taskArn, err)
+	}
+}
+
+// webhookAccounts returns the accounts to restore the movers with webhooks in: the configured accounts, or when
+// no accounts are configured (and any account can be managed), the accounts movers with webhooks were watched in
+func (s *server) webhookAccounts(ctx context.Context) []*accountConfig {
+	if len(s.accounts) > 0 {
+		return s.configuredAccounts()
+	}
+
+	ids, err := s.store.smembers(ctx, webhookAccountsKey)
+	if err != nil {
+		log.Warnf("failed to get the accounts with webhooks: %s", err)
+		return nil
+	}
+	sort.Strings(ids)
+
+	accounts := make([]*accountConfig, 0, len(ids))
+	for _, id := range ids {
+		acct, err := s.accountConfig(id)
+		if err != nil {
+			continue
+		}
+		accounts = append(accounts, acct)
+	}
+
+	return accounts
+}
+
+// restore watches the movers with webhooks in the regions of the accounts, so runs started before or while
+// the API was restarted are still notified.  Movers are found by the first webhook tag, spinup:webhook:0.
+func (n *notifier) restore(ctx context.Context, accounts []*accountConfig) {
+	for _, a := range accounts {
+		for _, r := range a.regions {
+			o, err := n.orchestrator(ctx, a.id, r)
+			if err != nil {
+				log.Warnf("failed to restore the movers with webhooks in account %s region %s: %s", a.id, r, err)
+				continue
+			}
+
+			filters := append(o.moverTagFilters(""), &resourcegroupstaggingapi.TagFilter{Key: webhookTagPrefix + "0"})
+			out, err := o.rgClient.GetResourcesWithTags(ctx, []string{"datasync:task"}, filters)
+			if err != nil {
+				log.Warnf("failed to restore the movers with webhooks in account %s region %s: %s", a.id, r, err)
+				continue
+			}
+
+			entries, err := o.describeTaskEntries(ctx, out)
+			if err != nil {
+				log.Warnf("failed to restore the movers with webhooks in account %s region %s: %s", a.id, r, err)
+				continue
+			}
+
+			for _, e := range entries {
+				urls := webhookUrls(e.Tags)
+				if len(urls) == 0 {
+					continue
+				}
+
+				if err := n.watch(ctx, o, e.Group, e.Name, e.Arn, urls); err != nil {
+					log.Warnf("failed to watch data mover %s for run notifications: %s", e.Name, err)
+				}
+			}
+		}
+	}
+}
+
+// scan lists the executions of the watched movers and follows the ones that haven't been seen
+func (n *notifier) scan(ctx context.Context) {
+	n.mu.Lock()
+	movers := make([]notifierMover, 0, len(n.movers))
+	for _, m := range n.movers {
+		movers = append(movers, *m)
+	}
+	n.mu.Unlock()
+
+	for _, m := range movers {
+		o, err := n.orchestrator(ctx, m.account, m.region)
+		if err != nil {
+			log.Warnf("failed to scan data mover %s for runs: %s", m.name, err)
+			continue
+		}
+
+		listedAt := time.Now()
+		execs, err := o.datasyncClient.ListDatasyncTaskExecutionEntries(ctx, m.taskArn)
+		if err != nil {
+			log.Warnf("failed to scan data mover %s for runs: %s", m.name, err)
+			continue
+		}
+
+		listed := map[string]bool{}
+		for _, e := range execs {
+			execArn := aws.StringValue(e.TaskExecutionArn)
+			listed[execArn] = true
+			n.follow(m.taskArn, execArn)
+		}
+
+		// forget executions that aged out of the list, but not runs started while it was listed
+		n.mu.Lock()
+		if wm, ok := n.movers[m.taskArn]; ok {
+			for execArn, at := range wm.seen {
+				if !listed[execArn] && at.Before(listedAt) {
+					delete(wm.seen, execArn)
+				}
+			}
+		}
+		n.mu.Unlock()
+	}
+}
+
+// follow subscribes to a run of a watched mover and notifies the webhooks when it finishes.  Runs
+// are only followed once.
+func (n *notifier) follow(taskArn, execArn string) {
+	n.followRun(taskArn, execArn, false)
+}
+
+// followEvent follows a run reported by a state change event, even if it existed when the mover was watched
+func (n *notifier) followEvent(taskArn, execArn string) {
+	n.followRun(taskArn, execArn, true)
+}
+
+func (n *notifier) followRun(taskArn, execArn string, event bool) {
+	if n == nil {
+		return
+	}
+
+	n.mu.Lock()
+	m, ok := n.movers[taskArn]
+	if !ok {
+		n.mu.Unlock()
+		return
+	}
+
+	if at, seen := m.seen[execArn]; seen && (!event || at.After(m.watched)) {
+		n.mu.Unlock()
+		return
+	}
+	m.seen[execArn] = time.Now()
+	account, region := m.account, m.region
+	n.mu.Unlock()
+
+	log.Debugf("following run %s for notifications", execArn)
+
+	events, unsubscribe := n.watchers.subscribe(execArn, func(ctx context.Context) (*datasync.DescribeTaskExecutionOutput, error) {
+		o, err := n.orchestrator(ctx, account, region)
+		if err != nil {
+			return nil, err
+		}
+
+		return o.datasyncClient.DescribeTaskExecution(ctx, execArn)
+	})
+
+	go func() {
+		defer unsubscribe()
+
+		for ev := range events {
+			if !ev.Final {
+				continue
+			}
+
+			if ev.Err != nil {
+				log.Errorf("failed to follow run %s, webhooks won't be notified: %s", execArn, ev.Err)
+				return
+			}
+
+			n.notify(taskArn, execArn, ev.Run)
+		}
+	}()
+}
+
+// notify sends the notification for a finished run to each of the webhooks of the mover
+func (n *notifier) notify(taskArn, execArn string, run *DatamoverRun) {
+	n.mu.Lock()
+	m, ok := n.movers[taskArn]
+	if !ok {
+		n.mu.Unlock()
+		return
+	}
+
+	payload := &WebhookPayload{
+		Event:            webhookEventSucceeded,
+		Account:          m.account,
+		Region:           m.region,
+		Group:            m.group,
+		Mover:            m.name,
+		RunId:            execArn[strings.LastIndex(execArn, "/")+1:],
+		Status:           run.Status,
+		StartTime:        run.StartTime,
+		BytesTransferred: run.BytesTransferred,
+		FilesTransferred: run.FilesTransferred,
+		Timestamp:        time.Now().UTC(),
+	}
+	urls := append([]string{}, m.urls...)
+	n.mu.Unlock()
+
+	if aws.StringValue(run.Status) != datasync.TaskExecutionStatusSuccess {
+		payload.Event = webhookEventFailed
+	}
+
+	if run.Result != nil {
+		payload.ErrorCode = run.Result.ErrorCode
+		payload.ErrorDetail = run.Result.ErrorDetail
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		log.Errorf("failed to marshal webhook payload for run %s: %s", execArn, err)
+		return
+	}
+
+	log.Infof("notifying %d webhooks of %s for data mover %s run %s", len(urls), payload.Event, payload.Mover, payload.RunId)
+
+	for _, u := range urls {
+		go n.deliver(taskArn, execArn, u, payload, body)
+	}
+}
+
+// deliver posts a signed notification to a webhook.  Every instance of the API follows the same runs, so the
+// delivery is claimed in the store first and only the instance that claims it delivers.  Failed deliveries are
+// retried with exponential backoff, except for client errors other than 408 and 429.  The outcome is added to
+// the delivery log.
+func (n *notifier) deliver(taskArn, execArn, url string, payload *WebhookPayload, body []byte) {
+	claimed, err := n.store.setNX(n.ctx, webhookSentKey(execArn, url), payload.Event, webhookDeliveriesTTL)
+	if err != nil {
+		// notifying twice is better than not notifying at all
+		log.Warnf("failed to claim webhook delivery of run %s to %s, delivering anyway: %s", payload.RunId, url, err)
+	} else if !claimed {
+		log.Debugf("webhook delivery of run %s to %s was claimed by another instance", payload.RunId, url)
+		return
+	}
+
+	d := &WebhookDelivery{
+		Id:    uuid.New().String(),
+		Url:   url,
+		Event: payload.Event,
+		RunId: payload.RunId,
+	}
+
+	wait := n.retryInterval
+	for d.Attempts < webhookAttempts {
+		if d.Attempts > 0 {
+			timer := time.NewTimer(wait)
+			select {
+			case <-n.ctx.Done():
+				timer.Stop()
+				log.Warnf("gave up delivering webhook %s to %s after %d attempts: %s", d.Id, url, d.Attempts, n.ctx.Err())
+				d.Time = time.Now().UTC()
+				n.record(taskArn, d)
+				return
+			case <-timer.C:
+			}
+			wait *= 2
+		}
+		d.Attempts++
+
+		code, err := n.post(url, d, body)
+		d.ResponseCode = code
+		if err == nil && code >= 200 && code < 300 {
+			d.Delivered = true
+			d.Error = ""
+			break
+		}
+
+		if err != nil {
+			d.Error = err.Error()
+		} else {
+			d.Error = http.StatusText(code)
+		}
+
+		log.Warnf("failed to deliver webhook %s to %s (attempt %d/%d): %s", d.Id, url, d.Attempts, webhookAttempts, d.Error)
+
+		if err == nil && code >= 400 && code < 500 && code != http.StatusRequestTimeout && code != http.StatusTooManyRequests {
+			break
+		}
+	}
+
+	d.Time = time.Now().UTC()
+	n.record(taskArn, d)
+}
+
+// post sends a single delivery attempt and returns the response status code
+func (n *notifier) post(url string, d *WebhookDelivery, body []byte) (int, error) {
+	req, err := http.NewRequestWithContext(n.ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Spinup-Event", d.Event)
+	req.Header.Set("X-Spinup-Delivery", d.Id)
+	req.Header.Set("X-Spinup-Signature", signWebhook(n.secret, body))
+
+	res, err := n.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer res.Body.Close()
+
+	return res.StatusCode, nil
+}
+
+// record adds a delivery to the front of the delivery log of a mover.  The delivery is recorded
+// even when the API is shutting down.
+func (n *notifier) record(taskArn string, d *WebhookDelivery) {
+	n.logMu.Lock()
+	defer n.logMu.Unlock()
+
+	n.mu.Lock()
+	_, ok := n.movers[taskArn]
+	n.mu.Unlock()
+
+	if !ok {
+		return
+	}
+
+	ctx := context.Background()
+
+	entries, err := n.loadDeliveries(ctx, taskArn)
+	if err != nil {
+		log.Errorf("failed to record webhook delivery %s: %s", d.Id, err)
+		return
+	}
+
+	entries = append([]*WebhookDelivery{d}, entries...)
+	if len(entries) > maxWebhookDeliveries {
+		entries = entries[:maxWebhookDeliveries]
+	}
+
+	value, err := json.Marshal(entries)
+	if err != nil {
+		log.Errorf("failed to record webhook delivery %s: %s", d.Id, err)
+		return
+	}
+
+	if err := n.store.set(ctx, webhookDeliveriesKey(taskArn), string(value), webhookDeliveriesTTL); err != nil {
+		log.Errorf("failed to record webhook delivery %s: %s", d.Id, err)
+	}
+}
+
+// loadDeliveries returns the delivery log of a mover from the store
+func (n *notifier) loadDeliveries(ctx context.Context, taskArn string) ([]*WebhookDelivery, error) {
+	v, ok, err := n.store.get(ctx, webhookDeliveriesKey(taskArn))
+	if err != nil {
+		return nil, err
+	}
+
+	entries := []*WebhookDelivery{}
+	if !ok {
+		return entries, nil
+	}
+
+	if err := json.Unmarshal([]byte(v), &entries); err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
+
+// deliveryLog returns the delivery log of a mover, newest first
+func (n *notifier) deliveryLog(ctx context.Context, taskArn string) ([]*WebhookDelivery, error) {
+	if n == nil {
+		return []*WebhookDelivery{}, nil
+	}
+
+	entries, err := n.loadDeliveries(ctx, taskArn)
+	if err != nil {
+		return nil, apierror.New(apierror.ErrInternalError, "failed to get webhook delivery log", err)
+	}
+
+	return entries, nil
+}
+
+// datamoverWebhooks returns the webhooks of a data mover and its delivery log
+func (o *datasyncOrchestrator) datamoverWebhooks(ctx context.Context, group, name string) (*DatamoverWebhooks, error) {
+	task, tags, err := o.taskDetailsFromName(ctx, group, name)
+	if err != nil {
+		return nil, err
+	}
+
+	urls := webhookUrls(tags)
+
+	// the mover may have been given webhooks before this instance of the API started
+	if err := o.server.notifier.watch(ctx, o, group, name, aws.StringValue(task.TaskArn), urls); err != nil {
+		log.Warnf("failed to watch data mover %s for run notifications: %s", name, err)
+	}
+
+	deliveries, err := o.server.notifier.deliveryLog(ctx, aws.StringValue(task.TaskArn))
+	if err != nil {
+		return nil, err
+	}
+
+	return &DatamoverWebhooks{
+		Urls:       urls,
+		Deliveries: deliveries,
+	}, nil
+}
+
+// datamoverWebhooksUpdate replaces the webhooks of a data mover, they're stored in the task tags
+func (o *datasyncOrchestrator) datamoverWebhooksUpdate(ctx context.Context, group, name string, req *DatamoverWebhooksRequest) (*DatamoverWebhooks, error) {
+	if o.server.notifier == nil {
+		return nil, apierror.New(apierror.ErrBadRequest, "webhook notifications are not enabled", nil)
+	}
+
+	task, tags, err := o.taskDetailsFromName(ctx, group, name)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Infof("updating webhooks of data mover %s", name)
+
+	add, remove := webhookTags(tags, req.Urls)
+	if err := o.updateDatasyncTags(ctx, aws.StringValue(task.TaskArn), add, remove); err != nil {
+		return nil, err
+	}
+	o.server.invalidateTaskIndex(o.account, o.region, group)
+
+	if err := o.server.notifier.watch(ctx, o, group, name, aws.StringValue(task.TaskArn), req.Urls); err != nil {
+		return nil, err
+	}
+
+	deliveries, err := o.server.notifier.deliveryLog(ctx, aws.StringValue(task.TaskArn))
+	if err != nil {
+		return nil, err
+	}
+
+	return &DatamoverWebhooks{
+		Urls:       req.Urls,
+		Deliveries: deliveries,
+	}, nil
+}
@@ -0,0 +1,435 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	ydatasync "github.com/YaleSpinup/datasync-api/datasync"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/datasync"
+	"github.com/aws/aws-sdk-go/service/resourcegroupstaggingapi"
+	"github.com/stretchr/testify/assert"
+)
+
+// mockWebhookDataSync records the tags added to and removed from the tasks of the index mock
+type mockWebhookDataSync struct {
+	*mockIndexDataSync
+	tagged   Tags
+	untagged []string
+}
+
+func (d *mockWebhookDataSync) TagResourceWithContext(ctx context.Context, input *datasync.TagResourceInput, opts ...request.Option) (*datasync.TagResourceOutput, error) {
+	d.tagged = append(d.tagged, fromDatasyncTags(input.Tags)...)
+	return &datasync.TagResourceOutput{}, nil
+}
+
+func (d *mockWebhookDataSync) UntagResourceWithContext(ctx context.Context, input *datasync.UntagResourceInput, opts ...request.Option) (*datasync.UntagResourceOutput, error) {
+	d.untagged = append(d.untagged, aws.StringValueSlice(input.Keys)...)
+	return &datasync.UntagResourceOutput{}, nil
+}
+
+// webhookReceiver is a webhook that fails the first failures requests and records the deliveries
+type webhookReceiver struct {
+	mu         sync.Mutex
+	failures   int
+	status     int
+	requests   int
+	payloads   []*WebhookPayload
+	signatures []string
+	bodies     [][]byte
+}
+
+func (h *webhookReceiver) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.requests++
+	if h.requests <= h.failures {
+		w.WriteHeader(h.status)
+		return
+	}
+
+	body, _ := io.ReadAll(r.Body)
+	payload := &WebhookPayload{}
+	if err := json.Unmarshal(body, payload); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	h.payloads = append(h.payloads, payload)
+	h.signatures = append(h.signatures, r.Header.Get("X-Spinup-Signature"))
+	h.bodies = append(h.bodies, body)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func newTestNotifier(o *datasyncOrchestrator) *notifier {
+	return newTestNotifierWithStore(o, newMockStore())
+}
+
+// newTestNotifierWithStore returns a test notifier using the store, notifiers sharing a store stand in for
+// instances of the API
+func newTestNotifierWithStore(o *datasyncOrchestrator, store sharedStore) *notifier {
+	n := newNotifier(context.Background(), "s3cret", store, newTestRunWatchers(), func(ctx context.Context, account, region string) (*datasyncOrchestrator, error) {
+		return o, nil
+	})
+	n.retryInterval = time.Millisecond
+	// the test webhook receivers listen on loopback addresses
+	n.client = &http.Client{Timeout: webhookTimeout}
+	o.server.notifier = n
+	return n
+}
+
+// deliveryLog returns the delivery log of a task
+func deliveryLog(t *testing.T, n *notifier, taskArn string) []*WebhookDelivery {
+	d, err := n.deliveryLog(context.TODO(), taskArn)
+	if err != nil {
+		t.Fatalf("expected nil error, got %s", err)
+	}
+
+	return d
+}
+
+// waitForDeliveries waits until the delivery log of a task has count entries
+func waitForDeliveries(t *testing.T, n *notifier, taskArn string, count int) []*WebhookDelivery {
+	timeout := time.Now().Add(5 * time.Second)
+	for time.Now().Before(timeout) {
+		if d := deliveryLog(t, n, taskArn); len(d) >= count {
+			return d
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	t.Fatalf("timed out waiting for %d webhook deliveries", count)
+	return nil
+}
+
+func Test_webhookTags(t *testing.T) {
+	current := Tags{
+		{Key: "spinup:spaceid", Value: "group1"},
+		{Key: "spinup:webhook:1", Value: "https://b.example.edu"},
+		{Key: "spinup:webhook:0", Value: "https://a.example.edu"},
+		{Key: "spinup:webhook:2", Value: "https://c.example.edu"},
+	}
+
+	assert.Equal(t, []string{"https://a.example.edu", "https://b.example.edu", "https://c.example.edu"}, webhookUrls(current))
+
+	add, remove := webhookTags(current, []string{"https://d.example.edu"})
+	assert.Equal(t, Tags{{Key: "spinup:webhook:0", Value: "https://d.example.edu"}}, add)
+	assert.Equal(t, []string{"spinup:webhook:1", "spinup:webhook:2"}, remove)
+
+	assert.Equal(t, []string{"https://d.example.edu"}, webhookUrls(current.apply(add, remove)))
+	assert.Equal(t, []string{}, webhookUrls(Tags{{Key: "spinup:webhook:x", Value: "https://a.example.edu"}}))
+}
+
+func Test_signWebhook(t *testing.T) {
+	assert.Equal(t, "sha256=f7bc83f430538424b13298e6aa6fb143ef4d59a14946175997479dbc2d1a3cd8", signWebhook([]byte("key"), []byte("The quick brown fox jumps over the lazy dog")))
+}
+
+func Test_notifierDeliver(t *testing.T) {
+	tests := []struct {
+		name         string
+		failures     int
+		status       int
+		wantAttempts int
+		delivered    bool
+	}{
+		{"delivered", 0, 0, 1, true},
+		{"retried", 2, http.StatusServiceUnavailable, 3, true},
+		{"rate limited", 1, http.StatusTooManyRequests, 2, true},
+		{"client error", 1, http.StatusNotFound, 1, false},
+		{"gave up", webhookAttempts, http.StatusInternalServerError, webhookAttempts, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := &webhookReceiver{failures: tt.failures, status: tt.status}
+			srv := httptest.NewServer(h)
+			defer srv.Close()
+
+			o, _ := newMockIndexOrchestrator(t, 1)
+			n := newTestNotifier(o)
+			n.movers["task"] = &notifierMover{account: o.account, name: "mover0", taskArn: "task", urls: []string{srv.URL}}
+
+			payload := &WebhookPayload{Event: webhookEventSucceeded, RunId: "exec-2"}
+			n.deliver("task", "task/execution/exec-2", srv.URL, payload, []byte(`{"Event":"run.succeeded"}`))
+
+			d := deliveryLog(t, n, "task")
+			if !assert.Len(t, d, 1) {
+				return
+			}
+			assert.Equal(t, tt.wantAttempts, d[0].Attempts)
+			assert.Equal(t, tt.delivered, d[0].Delivered)
+			assert.Equal(t, "exec-2", d[0].RunId)
+			if tt.delivered {
+				assert.Equal(t, http.StatusNoContent, d[0].ResponseCode)
+				assert.Empty(t, d[0].Error)
+				assert.Equal(t, signWebhook([]byte("s3cret"), []byte(`{"Event":"run.succeeded"}`)), h.signatures[0])
+			} else {
+				assert.NotEmpty(t, d[0].Error)
+			}
+		})
+	}
+}
+
+func Test_notifierDeliverOnce(t *testing.T) {
+	h := &webhookReceiver{}
+	srv := httptest.NewServer(h)
+	defer srv.Close()
+
+	// two instances of the API follow the same run
+	store := newMockStore()
+	o, _ := newMockIndexOrchestrator(t, 1)
+	first := newTestNotifierWithStore(o, store)
+	second := newTestNotifierWithStore(o, store)
+
+	for _, n := range []*notifier{first, second} {
+		n.movers["task"] = &notifierMover{account: o.account, name: "mover0", taskArn: "task", urls: []string{srv.URL}}
+		n.deliver("task", "task/execution/exec-1", srv.URL, &WebhookPayload{Event: webhookEventSucceeded, RunId: "exec-1"}, []byte(`{"Event":"run.succeeded"}`))
+	}
+
+	// the webhook is only notified by the instance that claimed the delivery
+	assert.Equal(t, 1, h.requests)
+	assert.Len(t, deliveryLog(t, first, "task"), 1)
+
+	// another run is notified
+	second.deliver("task", "task/execution/exec-2", srv.URL, &WebhookPayload{Event: webhookEventSucceeded, RunId: "exec-2"}, []byte(`{"Event":"run.succeeded"}`))
+	assert.Equal(t, 2, h.requests)
+	assert.Len(t, deliveryLog(t, first, "task"), 2)
+}
+
+func Test_newWebhookClient(t *testing.T) {
+	h := &webhookReceiver{}
+	srv := httptest.NewServer(h)
+	defer srv.Close()
+
+	// the test server listens on a loopback address, so the webhook client refuses to connect
+	res, err := newWebhookClient().Post(srv.URL, "application/json", nil)
+	if err == nil {
+		res.Body.Close()
+		t.Fatal("expected error connecting to a loopback address, got nil")
+	}
+	assert.Contains(t, err.Error(), "is not allowed")
+	assert.Equal(t, 0, h.requests)
+}
+
+func Test_notifierDeliverCancelled(t *testing.T) {
+	h := &webhookReceiver{failures: webhookAttempts, status: http.StatusServiceUnavailable}
+	srv := httptest.NewServer(h)
+	defer srv.Close()
+
+	o, _ := newMockIndexOrchestrator(t, 1)
+	n := newTestNotifier(o)
+	n.movers["task"] = &notifierMover{account: o.account, name: "mover0", taskArn: "task", urls: []string{srv.URL}}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	n.ctx = ctx
+	n.retryInterval = time.Hour
+
+	done := make(chan struct{})
+	go func() {
+		n.deliver("task", "task/execution/exec-1", srv.URL, &WebhookPayload{Event: webhookEventFailed, RunId: "exec-1"}, []byte(`{"Event":"run.failed"}`))
+		close(done)
+	}()
+
+	// the retry doesn't wait for the interval once the API shuts down
+	for timeout := time.Now().Add(5 * time.Second); ; time.Sleep(time.Millisecond) {
+		h.mu.Lock()
+		requests := h.requests
+		h.mu.Unlock()
+
+		if requests > 0 {
+			break
+		}
+
+		if time.Now().After(timeout) {
+			t.Fatal("timed out waiting for the first delivery attempt")
+		}
+	}
+	cancel()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for the delivery to give up")
+	}
+
+	d := deliveryLog(t, n, "task")
+	if assert.Len(t, d, 1) {
+		assert.Equal(t, 1, d[0].Attempts)
+		assert.False(t, d[0].Delivered)
+	}
+}
+
+func Test_notifierRestore(t *testing.T) {
+	o, _ := newMockIndexOrchestrator(t, 2)
+	n := newTestNotifier(o)
+
+	// only mover1 has webhooks
+	rg := o.rgClient.Service.(*mockIndexRGClient)
+	rg.tasks[1].Tags = append(rg.tasks[1].Tags, &resourcegroupstaggingapi.Tag{Key: aws.String(webhookTagPrefix + "0"), Value: aws.String("https://hooks.example.edu/datasync")})
+
+	// the delivery log of the mover was kept in the store before the restart
+	taskArn := testTaskArn(1)
+	n.movers[taskArn] = &notifierMover{taskArn: taskArn}
+	n.record(taskArn, &WebhookDelivery{Id: "delivery-1", RunId: "exec-1", Delivered: true})
+	delete(n.movers, taskArn)
+
+	n.restore(context.TODO(), []*accountConfig{{id: "012345678901", regions: []string{"us-east-1"}}})
+
+	n.mu.Lock()
+	if assert.Len(t, n.movers, 1) && assert.Contains(t, n.movers, taskArn) {
+		m := n.movers[taskArn]
+		assert.Equal(t, "group1", m.group)
+		assert.Equal(t, "mover1", m.name)
+		assert.Equal(t, []string{"https://hooks.example.edu/datasync"}, m.urls)
+	}
+	n.mu.Unlock()
+
+	d := deliveryLog(t, n, taskArn)
+	if assert.Len(t, d, 1) {
+		assert.Equal(t, "delivery-1", d[0].Id)
+	}
+
+	// forgetting the mover drops its delivery log
+	n.forget(context.TODO(), taskArn)
+	assert.Empty(t, deliveryLog(t, n, taskArn))
+}
+
+func Test_webhookAccounts(t *testing.T) {
+	o, _ := newMockIndexOrchestrator(t, 1)
+	n := newTestNotifier(o)
+	s := o.server
+	s.store = n.store
+	s.defaultAccount = accountConfig{regions: []string{"us-east-1"}}
+
+	assert.Empty(t, s.webhookAccounts(context.TODO()))
+
+	// without configured accounts, the accounts movers with webhooks were watched in are restored
+	taskArn := testTaskArn(0)
+	if err := n.watch(context.TODO(), o, "group1", "mover0", taskArn, []string{"https://hooks.example.edu/datasync"}); err != nil {
+		t.Fatalf("expected nil error, got %s", err)
+	}
+
+	accounts := s.webhookAccounts(context.TODO())
+	if assert.Len(t, accounts, 1) {
+		assert.Equal(t, o.account, accounts[0].id)
+		assert.Equal(t, []string{"us-east-1"}, accounts[0].regions)
+	}
+
+	// with configured accounts, the configured accounts are restored
+	s.accounts = map[string]*accountConfig{"other": {id: "210987654321", regions: []string{"us-west-2"}}}
+	accounts = s.webhookAccounts(context.TODO())
+	if assert.Len(t, accounts, 1) {
+		assert.Equal(t, "210987654321", accounts[0].id)
+	}
+}
+
+func Test_notifierFollow(t *testing.T) {
+	h := &webhookReceiver{}
+	srv := httptest.NewServer(h)
+	defer srv.Close()
+
+	o, _ := newMockIndexOrchestrator(t, 1)
+	n := newTestNotifier(o)
+
+	taskArn := "arn:aws:datasync:us-east-1:012345678901:task/task-00000000000000000"
+	if err := n.watch(context.TODO(), o, "group1", "mover0", taskArn, []string{srv.URL}); err != nil {
+		t.Fatalf("expected nil error, got %s", err)
+	}
+
+	// runs that existed when the mover was watched aren't notified
+	n.scan(context.TODO())
+	n.follow(taskArn, taskArn+"/execution/exec-2")
+	time.Sleep(10 * time.Millisecond)
+	assert.Empty(t, deliveryLog(t, n, taskArn))
+
+	// a new run is notified once it finishes
+	n.mu.Lock()
+	delete(n.movers[taskArn].seen, taskArn+"/execution/exec-1")
+	n.mu.Unlock()
+
+	n.follow(taskArn, taskArn+"/execution/exec-1")
+	n.follow(taskArn, taskArn+"/execution/exec-1")
+
+	d := waitForDeliveries(t, n, taskArn, 1)
+	assert.True(t, d[0].Delivered)
+	assert.Equal(t, webhookEventFailed, d[0].Event)
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if assert.Len(t, h.payloads, 1) {
+		assert.Equal(t, &WebhookPayload{
+			Event:            webhookEventFailed,
+			Account:          "012345678901",
+			Region:           "us-east-1",
+			Group:            "group1",
+			Mover:            "mover0",
+			RunId:            "exec-1",
+			Status:           aws.String(datasync.TaskExecutionStatusError),
+			StartTime:        aws.Time(testTime.Add(time.Hour)),
+			BytesTransferred: aws.Int64(1024),
+			FilesTransferred: aws.Int64(1),
+			Timestamp:        h.payloads[0].Timestamp,
+		}, h.payloads[0])
+		assert.Equal(t, signWebhook([]byte("s3cret"), h.bodies[0]), h.signatures[0])
+	}
+}
+
+func Test_notifierFollowUnwatched(t *testing.T) {
+	o, _ := newMockIndexOrchestrator(t, 1)
+	n := newTestNotifier(o)
+
+	// runs of movers without webhooks are started without watching the mover
+	taskArn := "arn:aws:datasync:us-east-1:012345678901:task/task-00000000000000000"
+	n.follow(taskArn, taskArn+"/execution/exec-1")
+
+	n.mu.Lock()
+	assert.Empty(t, n.movers)
+	n.mu.Unlock()
+	assert.Empty(t, deliveryLog(t, n, taskArn))
+}
+
+func Test_datamoverWebhooksUpdate(t *testing.T) {
+	o, ds := newMockIndexOrchestrator(t, 1)
+	wds := &mockWebhookDataSync{mockIndexDataSync: ds}
+	o.datasyncClient = ydatasync.Datasync{Service: wds}
+
+	if _, err := o.datamoverWebhooksUpdate(context.TODO(), "group1", "mover0", &DatamoverWebhooksRequest{Urls: []string{"https://a.example.edu"}}); err == nil {
+		t.Error("expected error without a notifier, got nil")
+	}
+
+	n := newTestNotifier(o)
+
+	out, err := o.datamoverWebhooksUpdate(context.TODO(), "group1", "mover0", &DatamoverWebhooksRequest{Urls: []string{"https://a.example.edu", "https://b.example.edu"}})
+	if err != nil {
+		t.Fatalf("expected nil error, got %s", err)
+	}
+
+	assert.Equal(t, &DatamoverWebhooks{Urls: []string{"https://a.example.edu", "https://b.example.edu"}, Deliveries: []*WebhookDelivery{}}, out)
+	assert.Equal(t, Tags{
+		{Key: "spinup:webhook:0", Value: "https://a.example.edu"},
+		{Key: "spinup:webhook:1", Value: "https://b.example.edu"},
+	}, wds.tagged)
+	assert.Empty(t, wds.untagged)
+
+	taskArn := "arn:aws:datasync:us-east-1:012345678901:task/task-00000000000000000"
+	assert.Contains(t, n.movers, taskArn)
+
+	// removing the webhooks stops watching the mover
+	if _, err := o.datamoverWebhooksUpdate(context.TODO(), "group1", "mover0", &DatamoverWebhooksRequest{Urls: []string{}}); err != nil {
+		t.Fatalf("expected nil error, got %s", err)
+	}
+	assert.NotContains(t, n.movers, taskArn)
+
+	if _, err := o.datamoverWebhooksUpdate(context.TODO(), "group1", "missing", &DatamoverWebhooksRequest{}); err == nil {
+		t.Error("expected error for unknown mover, got nil")
+	}
+}
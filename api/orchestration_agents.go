@@ -0,0 +1,142 @@
+package api
+
+import (
+	"context"
+
+	"github.com/YaleSpinup/apierror"
+	"github.com/YaleSpinup/aws-go/services/resourcegroupstaggingapi"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/datasync"
+	log "github.com/sirupsen/logrus"
+)
+
+// agentCreate activates a datasync agent in a group and returns its details
+func (o *datasyncOrchestrator) agentCreate(ctx context.Context, group string, req *AgentCreateRequest) (*AgentResponse, error) {
+	if group == "" || req == nil {
+		return nil, apierror.New(apierror.ErrBadRequest, "invalid input", nil)
+	}
+
+	log.Infof("activating agent %s in group %s", aws.StringValue(req.Name), group)
+
+	if _, _, err := o.agentDetailsFromName(ctx, group, aws.StringValue(req.Name)); err == nil {
+		return nil, apierror.New(apierror.ErrConflict, "datasync agent already exists", nil)
+	} else if aerr, ok := err.(apierror.Error); !ok || aerr.Code != apierror.ErrNotFound {
+		return nil, err
+	}
+
+	tags := req.Tags.normalize(o.server.org, group)
+
+	out, err := o.datasyncClient.CreateDatasyncAgent(ctx, &datasync.CreateAgentInput{
+		ActivationKey: req.ActivationKey,
+		AgentName:     req.Name,
+		Tags:          tags.toDatasyncTags(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	agent, err := o.datasyncClient.DescribeDatasyncAgent(ctx, aws.StringValue(out.AgentArn))
+	if err != nil {
+		return nil, err
+	}
+
+	return &AgentResponse{Agent: agent, Tags: tags}, nil
+}
+
+// agentDelete deletes a datasync agent from a group
+func (o *datasyncOrchestrator) agentDelete(ctx context.Context, group, name string) error {
+	log.Infof("deleting agent %s in group %s", name, group)
+
+	agent, _, err := o.agentDetailsFromName(ctx, group, name)
+	if err != nil {
+		return err
+	}
+
+	return o.datasyncClient.DeleteDatasyncAgent(ctx, aws.StringValue(agent.AgentArn))
+}
+
+// agentDescribe gets details about a specific datasync agent in a group
+func (o *datasyncOrchestrator) agentDescribe(ctx context.Context, group, name string) (*AgentResponse, error) {
+	agent, tags, err := o.agentDetailsFromName(ctx, group, name)
+	if err != nil {
+		return nil, err
+	}
+
+	return &AgentResponse{Agent: agent, Tags: tags}, nil
+}
+
+// agentList lists the names of all datasync agents in a group by querying the Resourcegroupstaggingapi
+func (o *datasyncOrchestrator) agentList(ctx context.Context, group string) ([]string, error) {
+	if group == "" {
+		log.Debug("listing all agents")
+	} else {
+		log.Debugf("listing agents in group %s", group)
+	}
+
+	out, err := o.rgClient.GetResourcesWithTags(ctx, []string{"datasync:agent"}, o.agentTagFilters(group))
+	if err != nil {
+		return nil, err
+	}
+
+	agents := make([]string, 0, len(out))
+	for _, r := range out {
+		agent, err := o.datasyncClient.DescribeDatasyncAgent(ctx, aws.StringValue(r.ResourceARN))
+		if err != nil {
+			return nil, err
+		}
+
+		agents = append(agents, aws.StringValue(agent.Name))
+	}
+
+	return agents, nil
+}
+
+// agentDetailsFromName finds a datasync agent based on its group/name and returns information about it
+func (o *datasyncOrchestrator) agentDetailsFromName(ctx context.Context, group, name string) (*datasync.DescribeAgentOutput, Tags, error) {
+	if group == "" || name == "" {
+		return nil, nil, apierror.New(apierror.ErrBadRequest, "invalid input", nil)
+	}
+
+	out, err := o.rgClient.GetResourcesWithTags(ctx, []string{"datasync:agent"}, o.agentTagFilters(group))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for _, r := range out {
+		agent, err := o.datasyncClient.DescribeDatasyncAgent(ctx, aws.StringValue(r.ResourceARN))
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if aws.StringValue(agent.Name) == name {
+			return agent, fromResourcegroupstaggingapiTags(r.Tags), nil
+		}
+	}
+
+	return nil, nil, apierror.New(apierror.ErrNotFound, "datasync agent not found", nil)
+}
+
+// agentTagFilters returns the tag filters used to scope agents to the org and a group, or to the
+// groups allowed in the account when the group is empty
+func (o *datasyncOrchestrator) agentTagFilters(group string) []*resourcegroupstaggingapi.TagFilter {
+	filters := []*resourcegroupstaggingapi.TagFilter{
+		{
+			Key:   "spinup:org",
+			Value: []string{o.server.org},
+		},
+		{
+			Key:   "spinup:type",
+			Value: []string{"storage"},
+		},
+		{
+			Key:   "spinup:flavor",
+			Value: []string{"datamover"},
+		},
+	}
+
+	if f := o.groupTagFilter(group); f != nil {
+		filters = append(filters, f)
+	}
+
+	return filters
+}
@@ -0,0 +1,104 @@
+package api
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/datasync"
+	"github.com/stretchr/testify/assert"
+)
+
+var testAgentArn = "arn:aws:datasync:us-east-1:012345678901:agent/agent-0914d8e6e0674c8b7"
+
+func (d *mockDataSync) CreateAgentWithContext(ctx context.Context, input *datasync.CreateAgentInput, opts ...request.Option) (*datasync.CreateAgentOutput, error) {
+	if d.err != nil {
+		return nil, d.err
+	}
+
+	return &datasync.CreateAgentOutput{AgentArn: aws.String(testAgentArn)}, nil
+}
+
+func (d *mockDataSync) DescribeAgentWithContext(ctx context.Context, input *datasync.DescribeAgentInput, opts ...request.Option) (*datasync.DescribeAgentOutput, error) {
+	if d.err != nil {
+		return nil, d.err
+	}
+
+	return &datasync.DescribeAgentOutput{
+		AgentArn: input.AgentArn,
+		Name:     aws.String("agent1"),
+		Status:   aws.String("ONLINE"),
+	}, nil
+}
+
+func (d *mockDataSync) DeleteAgentWithContext(ctx context.Context, input *datasync.DeleteAgentInput, opts ...request.Option) (*datasync.DeleteAgentOutput, error) {
+	if d.err != nil {
+		return nil, d.err
+	}
+
+	return &datasync.DeleteAgentOutput{}, nil
+}
+
+func Test_agentCreate(t *testing.T) {
+	tests := []struct {
+		name    string
+		group   string
+		req     *AgentCreateRequest
+		wantErr bool
+	}{
+		{"nil request", "group1", nil, true},
+		{"empty group", "", &AgentCreateRequest{Name: aws.String("agent2"), ActivationKey: aws.String("key")}, true},
+		{"existing agent", "group1", &AgentCreateRequest{Name: aws.String("agent1"), ActivationKey: aws.String("key")}, true},
+		{"valid agent", "group1", &AgentCreateRequest{Name: aws.String("agent2"), ActivationKey: aws.String("key")}, false},
+	}
+
+	o := newMockDataSyncOrchestrator(t)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := o.agentCreate(context.Background(), tt.group, tt.req)
+			if tt.wantErr {
+				if err == nil {
+					t.Error("expected error but did not receive error")
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("received unexpected error %v", err)
+			}
+
+			assert.Equal(t, testAgentArn, aws.StringValue(got.Agent.AgentArn))
+			assert.True(t, got.Tags.inGroup(tt.group))
+		})
+	}
+}
+
+func Test_agentDescribe(t *testing.T) {
+	o := newMockDataSyncOrchestrator(t)
+
+	got, err := o.agentDescribe(context.Background(), "group1", "agent1")
+	if err != nil {
+		t.Fatalf("received unexpected error %v", err)
+	}
+	assert.Equal(t, "agent1", aws.StringValue(got.Agent.Name))
+
+	if _, err := o.agentDescribe(context.Background(), "group1", "missing"); err == nil {
+		t.Error("expected not found error but did not receive error")
+	}
+
+	if err := o.agentDelete(context.Background(), "group1", "agent1"); err != nil {
+		t.Errorf("received unexpected error deleting agent %v", err)
+	}
+}
+
+func Test_agentList(t *testing.T) {
+	o := newMockDataSyncOrchestrator(t)
+
+	got, err := o.agentList(context.Background(), "group1")
+	if err != nil {
+		t.Fatalf("received unexpected error %v", err)
+	}
+	assert.Equal(t, []string{"agent1"}, got)
+}
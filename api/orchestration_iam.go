@@ -16,15 +16,25 @@ import (
 
 var assumeRolePolicyDoc []byte
 
-// bucketAccessRole generates the role (if it doesn't exist) for DataSync access to S3 bucket and returns the ARN
-func (o *datasyncOrchestrator) bucketAccessRole(ctx context.Context, path, role, bucketArn string, tags []Tag) (string, error) {
+// bucketAccessScope limits a bucket access role to part of a bucket, without a scope
+// the role can read and write all of the objects in the bucket
+type bucketAccessScope struct {
+	// reportPrefix allows writing task reports under the prefix
+	reportPrefix *string
+	// manifestObject allows reading a manifest object
+	manifestObject *string
+}
+
+// bucketAccessRole generates the role (if it doesn't exist) for DataSync access to S3 bucket and returns the ARN.
+// When a scope is passed, the role is only allowed to access that part of the bucket.
+func (o *datasyncOrchestrator) bucketAccessRole(ctx context.Context, path, role, bucketArn string, scope *bucketAccessScope, tags []Tag) (string, error) {
 	if path == "" || role == "" {
 		return "", apierror.New(apierror.ErrBadRequest, "invalid path", nil)
 	}
 
 	log.Infof("generating bucket access role %s%s if it doesn't exist ", path, role)
 
-	defaultPolicy := bucketAccessPolicy(bucketArn)
+	defaultPolicy := bucketAccessPolicy(bucketArn, scope)
 
 	var roleArn string
 	if out, err := o.iamClient.GetRole(ctx, role); err != nil {
@@ -199,8 +209,58 @@ func assumeRolePolicy() (string, error) {
 	return string(policyDoc), nil
 }
 
-// bucketAccessPolicy generates the policy for DataSync bucket access
-func bucketAccessPolicy(bucketArn string) yiam.PolicyDocument {
+// bucketAccessPolicy generates the policy for DataSync bucket access, for writing task reports or
+// for reading a manifest object when the scope is passed
+func bucketAccessPolicy(bucketArn string, scope *bucketAccessScope) yiam.PolicyDocument {
+	if scope != nil && scope.manifestObject != nil {
+		log.Debugf("generating manifest access policy for %s/%s", bucketArn, aws.StringValue(scope.manifestObject))
+
+		return yiam.PolicyDocument{
+			Version: "2012-10-17",
+			Statement: []yiam.StatementEntry{
+				{
+					Sid:    "GetManifest",
+					Effect: "Allow",
+					Action: []string{
+						"s3:GetObject",
+						"s3:GetObjectVersion",
+					},
+					Resource: []string{bucketArn + "/" + aws.StringValue(scope.manifestObject)},
+				},
+			},
+		}
+	}
+
+	if scope != nil && scope.reportPrefix != nil {
+		log.Debugf("generating task report access policy for %s/%s", bucketArn, aws.StringValue(scope.reportPrefix))
+
+		return yiam.PolicyDocument{
+			Version: "2012-10-17",
+			Statement: []yiam.StatementEntry{
+				{
+					Sid:    "ListBucket",
+					Effect: "Allow",
+					Action: []string{
+						"s3:GetBucketLocation",
+						"s3:ListBucket",
+						"s3:ListBucketMultipartUploads",
+					},
+					Resource: []string{bucketArn},
+				},
+				{
+					Sid:    "PutTaskReports",
+					Effect: "Allow",
+					Action: []string{
+						"s3:AbortMultipartUpload",
+						"s3:ListMultipartUploadParts",
+						"s3:PutObject",
+					},
+					Resource: []string{bucketArn + "/" + aws.StringValue(scope.reportPrefix) + "*"},
+				},
+			},
+		}
+	}
+
 	log.Debugf("generating bucket access policy for %s", bucketArn)
 
 	return yiam.PolicyDocument{
@@ -0,0 +1,285 @@
+package api
+
+import (
+	"context"
+	"encoding/base64"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/YaleSpinup/apierror"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/datasync"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// defaultListLimit is the page size when a cursor is passed without a limit
+	defaultListLimit = 100
+	// maxListLimit is the maximum page size
+	maxListLimit = 1000
+)
+
+// listPosition is the position of a mover in a list, movers are sorted by group, name and region
+type listPosition struct {
+	group  string
+	name   string
+	region string
+}
+
+// encodeListCursor returns the cursor of the page after a mover
+func encodeListCursor(p listPosition) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(p.group + "/" + p.name + "/" + p.region))
+}
+
+// decodeListCursor returns the position of the last mover of the previous page
+func decodeListCursor(cursor string) (listPosition, error) {
+	b, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return listPosition{}, apierror.New(apierror.ErrBadRequest, "invalid cursor", err)
+	}
+
+	parts := strings.SplitN(string(b), "/", 3)
+	if len(parts) != 3 || parts[1] == "" || parts[2] == "" {
+		return listPosition{}, apierror.New(apierror.ErrBadRequest, "invalid cursor", nil)
+	}
+
+	return listPosition{group: parts[0], name: parts[1], region: parts[2]}, nil
+}
+
+// listLess sorts movers by group, then by name and then by region
+func listLess(a, b listPosition) bool {
+	if a.group != b.group {
+		return a.group < b.group
+	}
+
+	if a.name != b.name {
+		return a.name < b.name
+	}
+
+	return a.region < b.region
+}
+
+// entryPosition returns the list position of an index entry
+func entryPosition(e *taskIndexEntry) listPosition {
+	return listPosition{group: e.Group, name: e.Name, region: e.Region}
+}
+
+// regionalTasks returns the datasync tasks in a group (or in all groups when the group is empty) in
+// the regions of the orchestrators, and maps each region to its orchestrator
+func regionalTasks(ctx context.Context, orchs []*datasyncOrchestrator, group string) ([]*taskIndexEntry, map[string]*datasyncOrchestrator, error) {
+	regions := make(map[string]*datasyncOrchestrator, len(orchs))
+	tasks := make([][]*taskIndexEntry, len(orchs))
+	if err := concurrently(len(orchs), len(orchs), func(i int) error {
+		var err error
+		if group == "" {
+			tasks[i], err = orchs[i].allTasks(ctx)
+		} else {
+			tasks[i], err = orchs[i].groupTasks(ctx, group)
+		}
+		return err
+	}); err != nil {
+		return nil, nil, err
+	}
+
+	entries := []*taskIndexEntry{}
+	for i, o := range orchs {
+		regions[o.region] = o
+		entries = append(entries, tasks[i]...)
+	}
+
+	return entries, regions, nil
+}
+
+// datamoverListRegions returns the names of the movers in a group (or in all groups when the group is empty)
+// in the regions of the orchestrators, a name is repeated when movers in several regions have the same name
+func datamoverListRegions(ctx context.Context, orchs []*datasyncOrchestrator, group string) ([]string, error) {
+	names := make([][]string, len(orchs))
+	if err := concurrently(len(orchs), len(orchs), func(i int) error {
+		var err error
+		names[i], err = orchs[i].datamoverList(ctx, group)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	all := []string{}
+	for _, n := range names {
+		all = append(all, n...)
+	}
+
+	return all, nil
+}
+
+// hasTags returns true if the tags include all of the filter tags, filter tags with an
+// empty value match any value
+func hasTags(tags, filters Tags) bool {
+	for _, f := range filters {
+		found := false
+		for _, t := range tags {
+			if t.Key == f.Key && (f.Value == "" || t.Value == f.Value) {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			return false
+		}
+	}
+
+	return true
+}
+
+// datamoverListPage returns a page of movers in a group (or in all groups when the group is empty) in the
+// regions of the orchestrators matching the filters, sorted by group, name and region, and the cursor of the
+// next page.  Movers are only described when they're expanded or filtered by status or location type, and
+// only as many as it takes to fill the page.
+func datamoverListPage(ctx context.Context, orchs []*datasyncOrchestrator, group string, input *DatamoverListInput) ([]*DatamoverSummary, string, error) {
+	entries, regions, err := regionalTasks(ctx, orchs, group)
+	if err != nil {
+		return nil, "", err
+	}
+
+	var after listPosition
+	if input.Next != "" {
+		if after, err = decodeListCursor(input.Next); err != nil {
+			return nil, "", err
+		}
+	}
+
+	candidates := make([]*taskIndexEntry, 0, len(entries))
+	for _, e := range entries {
+		if input.Next != "" && !listLess(after, entryPosition(e)) {
+			continue
+		}
+
+		if hasTags(e.Tags, input.Tags) {
+			candidates = append(candidates, e)
+		}
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return listLess(entryPosition(candidates[i]), entryPosition(candidates[j]))
+	})
+
+	describe := input.Expand || input.Status != "" || input.LocationType != ""
+
+	// there's no way to determine the type of a specific location, so list them all once in each region,
+	// location ARNs include the region so they can be merged
+	locations := map[string]string{}
+	if input.Expand || input.LocationType != "" {
+		for _, o := range orchs {
+			l, err := o.datasyncClient.ListDatasyncLocations(ctx)
+			if err != nil {
+				return nil, "", err
+			}
+
+			for arn, t := range l {
+				locations[arn] = t
+			}
+		}
+	}
+
+	limit := input.Limit
+	if limit == 0 {
+		limit = len(candidates)
+	}
+
+	now := time.Now().UTC()
+	movers := []*DatamoverSummary{}
+	examined := 0
+	for examined < len(candidates) && len(movers) < limit {
+		// describe enough candidates to fill the page if none of them are filtered out
+		batch := candidates[examined:]
+		if len(batch) > limit-len(movers) {
+			batch = batch[:limit-len(movers)]
+		}
+		examined += len(batch)
+
+		tasks := make([]*datasync.DescribeTaskOutput, len(batch))
+		if describe {
+			if err := concurrently(len(batch), taskDescribeConcurrency, func(i int) error {
+				var err error
+				tasks[i], err = regions[batch[i].Region].datasyncClient.DescribeDatasyncTask(ctx, batch[i].Arn)
+				return err
+			}); err != nil {
+				return nil, "", err
+			}
+		}
+
+		for i, e := range batch {
+			mover := &DatamoverSummary{
+				Name:   e.Name,
+				Group:  e.Group,
+				Region: e.Region,
+				Tags:   e.Tags,
+			}
+
+			if task := tasks[i]; task != nil {
+				mover.Status = task.Status
+				mover.SourceType = locationTypeFromScheme(locations[aws.StringValue(task.SourceLocationArn)])
+				mover.DestinationType = locationTypeFromScheme(locations[aws.StringValue(task.DestinationLocationArn)])
+				mover.NextRun = nextRun(task, now)
+				if task.Schedule != nil {
+					mover.Schedule = task.Schedule.ScheduleExpression
+				}
+			}
+
+			if input.Status != "" && aws.StringValue(mover.Status) != input.Status {
+				continue
+			}
+
+			if input.LocationType != "" && mover.SourceType != input.LocationType && mover.DestinationType != input.LocationType {
+				continue
+			}
+
+			movers = append(movers, mover)
+		}
+	}
+
+	next := ""
+	if examined < len(candidates) && len(movers) > 0 {
+		last := movers[len(movers)-1]
+		next = encodeListCursor(listPosition{group: last.Group, name: last.Name, region: last.Region})
+	}
+
+	if input.Expand {
+		if err := addLastRuns(ctx, regions, movers, candidates); err != nil {
+			return nil, "", err
+		}
+	}
+
+	log.Debugf("listed %d of %d data movers, next cursor: %q", len(movers), len(candidates), next)
+
+	return movers, next, nil
+}
+
+// addLastRuns adds the id and status of the most recent run to each mover, using the orchestrator of its region
+func addLastRuns(ctx context.Context, regions map[string]*datasyncOrchestrator, movers []*DatamoverSummary, entries []*taskIndexEntry) error {
+	arns := map[listPosition]string{}
+	for _, e := range entries {
+		arns[entryPosition(e)] = e.Arn
+	}
+
+	return concurrently(len(movers), taskDescribeConcurrency, func(i int) error {
+		m := movers[i]
+
+		arn := arns[listPosition{group: m.Group, name: m.Name, region: m.Region}]
+		execs, err := regions[m.Region].datasyncClient.ListDatasyncTaskExecutionEntries(ctx, arn)
+		if err != nil {
+			return err
+		}
+
+		if len(execs) == 0 {
+			return nil
+		}
+
+		last := execs[len(execs)-1]
+		parts := strings.Split(aws.StringValue(last.TaskExecutionArn), "/")
+		m.LastRunId = aws.String(parts[len(parts)-1])
+		m.LastRunStatus = last.Status
+
+		return nil
+	})
+}
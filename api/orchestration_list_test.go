@@ -0,0 +1,198 @@
+package api
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/datasync"
+	"github.com/aws/aws-sdk-go/service/resourcegroupstaggingapi"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_listCursor(t *testing.T) {
+	p := listPosition{group: "group1", name: "mover1", region: "us-west-2"}
+	got, err := decodeListCursor(encodeListCursor(p))
+	if err != nil {
+		t.Fatalf("expected nil error, got %s", err)
+	}
+	assert.Equal(t, p, got)
+
+	for _, c := range []string{
+		"!!!",
+		encodeListCursor(listPosition{group: "group1", region: "us-east-1"}),
+		encodeListCursor(listPosition{group: "group1", name: "mover1"}),
+		"Z3JvdXAx",
+	} {
+		if _, err := decodeListCursor(c); err == nil {
+			t.Errorf("expected error for cursor %s, got nil", c)
+		}
+	}
+}
+
+func Test_hasTags(t *testing.T) {
+	tags := Tags{{Key: "spinup:spaceid", Value: "group1"}, {Key: "Project", Value: "genomics"}}
+
+	tests := []struct {
+		name    string
+		filters Tags
+		want    bool
+	}{
+		{"no filters", nil, true},
+		{"matching tag", Tags{{Key: "Project", Value: "genomics"}}, true},
+		{"any value", Tags{{Key: "Project"}}, true},
+		{"all tags", Tags{{Key: "Project", Value: "genomics"}, {Key: "spinup:spaceid", Value: "group1"}}, true},
+		{"different value", Tags{{Key: "Project", Value: "physics"}}, false},
+		{"missing tag", Tags{{Key: "Owner"}}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, hasTags(tags, tt.filters))
+		})
+	}
+}
+
+func Test_datamoverListPage(t *testing.T) {
+	o, _ := newMockIndexOrchestrator(t, 5)
+
+	// move the last two movers to another group
+	rg := o.rgClient.Service.(*mockIndexRGClient)
+	for _, r := range rg.tasks[3:] {
+		r.Tags[0].Value = aws.String("group0")
+	}
+	rg.tasks[0].Tags = append(rg.tasks[0].Tags, &resourcegroupstaggingapi.Tag{Key: aws.String("Project"), Value: aws.String("genomics")})
+
+	names := func(movers []*DatamoverSummary) []string {
+		out := []string{}
+		for _, m := range movers {
+			out = append(out, m.Group+"/"+m.Name)
+		}
+		return out
+	}
+
+	// all movers are sorted by group and name
+	movers, next, err := datamoverListPage(context.TODO(), []*datasyncOrchestrator{o}, "", &DatamoverListInput{})
+	if err != nil {
+		t.Fatalf("expected nil error, got %s", err)
+	}
+	assert.Equal(t, []string{"group0/mover3", "group0/mover4", "group1/mover0", "group1/mover1", "group1/mover2"}, names(movers))
+	assert.Equal(t, "", next)
+
+	// pages follow the cursor
+	got := []string{}
+	input := &DatamoverListInput{Limit: 2}
+	for pages := 0; pages < 5; pages++ {
+		movers, next, err = datamoverListPage(context.TODO(), []*datasyncOrchestrator{o}, "", input)
+		if err != nil {
+			t.Fatalf("expected nil error, got %s", err)
+		}
+		got = append(got, names(movers)...)
+
+		if next == "" {
+			break
+		}
+		input.Next = next
+	}
+	assert.Equal(t, []string{"group0/mover3", "group0/mover4", "group1/mover0", "group1/mover1", "group1/mover2"}, got)
+
+	// status filters fill the page from later movers
+	movers, next, err = datamoverListPage(context.TODO(), []*datasyncOrchestrator{o}, "", &DatamoverListInput{Status: datasync.TaskStatusRunning, Limit: 1})
+	if err != nil {
+		t.Fatalf("expected nil error, got %s", err)
+	}
+	assert.Equal(t, []string{"group0/mover3"}, names(movers))
+	assert.NotEqual(t, "", next)
+
+	movers, next, err = datamoverListPage(context.TODO(), []*datasyncOrchestrator{o}, "", &DatamoverListInput{Status: datasync.TaskStatusRunning, Limit: 1, Next: next})
+	if err != nil {
+		t.Fatalf("expected nil error, got %s", err)
+	}
+	assert.Equal(t, []string{"group1/mover1"}, names(movers))
+
+	// location type and tag filters in a group
+	movers, _, err = datamoverListPage(context.TODO(), []*datasyncOrchestrator{o}, "group1", &DatamoverListInput{LocationType: EFS})
+	if err != nil {
+		t.Fatalf("expected nil error, got %s", err)
+	}
+	assert.Equal(t, []string{"group1/mover0", "group1/mover2"}, names(movers))
+
+	movers, _, err = datamoverListPage(context.TODO(), []*datasyncOrchestrator{o}, "group1", &DatamoverListInput{Tags: Tags{{Key: "Project", Value: "genomics"}}})
+	if err != nil {
+		t.Fatalf("expected nil error, got %s", err)
+	}
+	assert.Equal(t, []string{"group1/mover0"}, names(movers))
+
+	// expanded movers include their status, location types and last run
+	movers, _, err = datamoverListPage(context.TODO(), []*datasyncOrchestrator{o}, "group1", &DatamoverListInput{Expand: true, Limit: 1})
+	if err != nil {
+		t.Fatalf("expected nil error, got %s", err)
+	}
+	assert.Equal(t, []*DatamoverSummary{
+		{
+			Name:            "mover0",
+			Group:           "group1",
+			Region:          "us-east-1",
+			Status:          aws.String(datasync.TaskStatusAvailable),
+			SourceType:      S3,
+			DestinationType: EFS,
+			LastRunId:       aws.String("exec-3"),
+			LastRunStatus:   aws.String(datasync.TaskExecutionStatusTransferring),
+			Tags:            Tags{{Key: "spinup:spaceid", Value: "group1"}, {Key: "Project", Value: "genomics"}},
+		},
+	}, movers)
+
+	if _, _, err := datamoverListPage(context.TODO(), []*datasyncOrchestrator{o}, "", &DatamoverListInput{Next: "!!!"}); err == nil {
+		t.Error("expected error for invalid cursor, got nil")
+	}
+}
+
+func Test_datamoverListPageRegions(t *testing.T) {
+	east, _ := newMockIndexOrchestrator(t, 3)
+	west, westDs := newMockIndexOrchestrator(t, 2)
+	west.region = "us-west-2"
+	west.server = east.server
+	east.accountConfig.regions = []string{"us-east-1", "us-west-2"}
+	west.accountConfig = east.accountConfig
+
+	orchs := []*datasyncOrchestrator{east, west}
+
+	names, err := datamoverListRegions(context.TODO(), orchs, "group1")
+	if err != nil {
+		t.Fatalf("expected nil error, got %s", err)
+	}
+	assert.Equal(t, []string{"mover0", "mover1", "mover2", "mover0", "mover1"}, names)
+
+	// movers with the same name are sorted by region, and pages follow the cursor across regions
+	got := []string{}
+	input := &DatamoverListInput{Limit: 2}
+	for pages := 0; pages < 5; pages++ {
+		movers, next, err := datamoverListPage(context.TODO(), orchs, "group1", input)
+		if err != nil {
+			t.Fatalf("expected nil error, got %s", err)
+		}
+
+		for _, m := range movers {
+			got = append(got, m.Name+"@"+m.Region)
+		}
+
+		if next == "" {
+			break
+		}
+		input.Next = next
+	}
+	assert.Equal(t, []string{"mover0@us-east-1", "mover0@us-west-2", "mover1@us-east-1", "mover1@us-west-2", "mover2@us-east-1"}, got)
+
+	// expanded movers are described in their own region
+	calls := westDs.calls
+	movers, _, err := datamoverListPage(context.TODO(), orchs, "group1", &DatamoverListInput{Expand: true, Next: encodeListCursor(listPosition{group: "group1", name: "mover1", region: "us-east-1"})})
+	if err != nil {
+		t.Fatalf("expected nil error, got %s", err)
+	}
+	if assert.Len(t, movers, 2) {
+		assert.Equal(t, "us-west-2", movers[0].Region)
+		assert.Equal(t, aws.String(datasync.TaskStatusRunning), movers[0].Status)
+		assert.Equal(t, "us-east-1", movers[1].Region)
+	}
+	assert.Equal(t, calls+1, westDs.calls)
+}
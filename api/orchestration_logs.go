@@ -0,0 +1,231 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/YaleSpinup/apierror"
+	"github.com/YaleSpinup/aws-go/services/iam"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/arn"
+	"github.com/aws/aws-sdk-go/service/cloudwatchlogs"
+	log "github.com/sirupsen/logrus"
+)
+
+// defaultLogRetention is the number of days mover logs are kept when a retention isn't passed
+const defaultLogRetention = 30
+
+// logGroupPrefix returns the prefix for all mover log groups in the org
+func (o *datasyncOrchestrator) logGroupPrefix() string {
+	return fmt.Sprintf("/aws/datasync/spinup/%s/", o.server.org)
+}
+
+// logGroupName returns the name of the log group for a mover in a group
+func (o *datasyncOrchestrator) logGroupName(group, name string) string {
+	return o.logGroupPrefix() + group + "/" + name
+}
+
+// logGroupArn returns the ARN of the log group for a mover in a group
+func (o *datasyncOrchestrator) logGroupArn(group, name string) string {
+	return fmt.Sprintf("arn:aws:logs:%s:%s:log-group:%s", o.region, o.account, o.logGroupName(group, name))
+}
+
+// logGroupNameFromArn returns the log group name from a log group ARN,
+// ie. arn:aws:logs:us-east-1:012345678901:log-group:/aws/datasync/foo:*
+func logGroupNameFromArn(lgArn string) (string, error) {
+	a, err := arn.Parse(lgArn)
+	if err != nil {
+		return "", apierror.New(apierror.ErrBadRequest, "failed to parse log group arn "+lgArn, err)
+	}
+
+	if a.Service != "logs" || !strings.HasPrefix(a.Resource, "log-group:") {
+		return "", apierror.New(apierror.ErrBadRequest, "invalid log group arn "+lgArn, nil)
+	}
+
+	return strings.TrimSuffix(strings.TrimPrefix(a.Resource, "log-group:"), ":*"), nil
+}
+
+// createLogGroup creates the log group for a mover, makes sure DataSync is allowed to write to it
+// and returns the log group ARN.  The log group is deleted if a later step fails, since the caller
+// only rolls it back once the ARN is returned.
+func (o *datasyncOrchestrator) createLogGroup(ctx context.Context, group, name string, input *DatamoverLoggingInput, tags Tags) (lgArn string, err error) {
+	lgName := o.logGroupName(group, name)
+
+	retention := int64(defaultLogRetention)
+	if input != nil && input.RetentionInDays != nil {
+		retention = aws.Int64Value(input.RetentionInDays)
+	}
+
+	if err = o.logsClient.CreateLogGroup(ctx, lgName, retention, tags.toCloudWatchLogsTags()); err != nil {
+		return "", err
+	}
+
+	defer func() {
+		if err != nil {
+			log.Errorf("deleting log group %s after error: %s", lgName, err)
+
+			if derr := o.logsClient.DeleteLogGroup(ctx, lgName); derr != nil {
+				log.Warnf("error deleting log group %s: %s", lgName, derr)
+			}
+		}
+	}()
+
+	policy, err := o.logsResourcePolicy()
+	if err != nil {
+		return "", apierror.New(apierror.ErrInternalError, "failed to generate log resource policy", err)
+	}
+
+	if err = o.logsClient.PutResourcePolicy(ctx, o.logsResourcePolicyName(), policy); err != nil {
+		return "", err
+	}
+
+	lg, err := o.logsClient.DescribeLogGroup(ctx, lgName)
+	if err != nil {
+		return "", err
+	}
+
+	// DataSync expects the log group ARN without the trailing :*
+	return strings.TrimSuffix(aws.StringValue(lg.Arn), ":*"), nil
+}
+
+// deleteLogGroup deletes the log group for a mover.  Log groups that weren't created by
+// this API (or that are already gone) are left alone.
+func (o *datasyncOrchestrator) deleteLogGroup(ctx context.Context, lgArn string) error {
+	lgName, err := logGroupNameFromArn(lgArn)
+	if err != nil {
+		return err
+	}
+
+	if !strings.HasPrefix(lgName, o.logGroupPrefix()) {
+		log.Warnf("not deleting log group %s, it wasn't created for a mover", lgName)
+		return nil
+	}
+
+	if err := o.logsClient.DeleteLogGroup(ctx, lgName); err != nil {
+		if aerr, ok := err.(apierror.Error); ok && aerr.Code == apierror.ErrNotFound {
+			log.Warnf("log group %s not found, not deleting", lgName)
+			return nil
+		}
+
+		return err
+	}
+
+	return nil
+}
+
+// logsResourcePolicyName returns the name of the CloudWatch Logs resource policy for the org
+func (o *datasyncOrchestrator) logsResourcePolicyName() string {
+	return fmt.Sprintf("spinup-%s-datasync-logs", o.server.org)
+}
+
+// logsResourcePolicy returns the CloudWatch Logs resource policy allowing DataSync to write to
+// the mover log groups.  Resource policies are account wide and limited to 10 per region, so a
+// single policy covers all of the log groups in the org and is never deleted.
+func (o *datasyncOrchestrator) logsResourcePolicy() (string, error) {
+	policy := iam.PolicyDocument{
+		Version: "2012-10-17",
+		Statement: []iam.StatementEntry{
+			{
+				Sid:    "DataSyncLogsToCloudWatchLogs",
+				Effect: "Allow",
+				Principal: iam.Principal{
+					"Service": iam.Value{"datasync.amazonaws.com"},
+				},
+				Action: []string{
+					"logs:PutLogEvents",
+					"logs:CreateLogStream",
+				},
+				Resource: []string{
+					fmt.Sprintf("arn:aws:logs:*:%s:log-group:%s*", o.account, o.logGroupPrefix()),
+				},
+				Condition: iam.Condition{
+					"ArnLike": iam.ConditionStatement{
+						"aws:SourceArn": iam.Value{fmt.Sprintf("arn:aws:datasync:*:%s:task/*", o.account)},
+					},
+					"StringEquals": iam.ConditionStatement{
+						"aws:SourceAccount": iam.Value{o.account},
+					},
+				},
+			},
+		},
+	}
+
+	j, err := json.Marshal(policy)
+	if err != nil {
+		return "", err
+	}
+
+	return string(j), nil
+}
+
+// datamoverRunLogs returns a page of log events written during a data mover run
+func (o *datasyncOrchestrator) datamoverRunLogs(ctx context.Context, group, name, id string, limit int64, next string) (*DatamoverRunLogs, error) {
+	task, _, err := o.taskDetailsFromName(ctx, group, name)
+	if err != nil {
+		return nil, err
+	}
+
+	if id == "" {
+		return nil, apierror.New(apierror.ErrBadRequest, "invalid input, id is missing", nil)
+	}
+
+	if aws.StringValue(task.CloudWatchLogGroupArn) == "" {
+		return nil, apierror.New(apierror.ErrNotFound, "logging is not enabled for datasync mover", nil)
+	}
+
+	lgName, err := logGroupNameFromArn(aws.StringValue(task.CloudWatchLogGroupArn))
+	if err != nil {
+		return nil, err
+	}
+
+	exec, err := o.datasyncClient.DescribeTaskExecution(ctx, fmt.Sprintf("%s/execution/%s", aws.StringValue(task.TaskArn), id))
+	if err != nil {
+		return nil, err
+	}
+
+	if exec.StartTime == nil {
+		return &DatamoverRunLogs{Events: []*DatamoverLogEvent{}}, nil
+	}
+
+	// runs of the mover can overlap, so only the events mentioning the run are returned
+	input := &cloudwatchlogs.FilterLogEventsInput{
+		LogGroupName:  aws.String(lgName),
+		FilterPattern: aws.String(fmt.Sprintf("%q", id)),
+		StartTime:     aws.Int64(aws.TimeValue(exec.StartTime).UnixMilli()),
+		Limit:         aws.Int64(limit),
+	}
+
+	if next != "" {
+		input.NextToken = aws.String(next)
+	}
+
+	// limit finished runs to their duration, allowing for log delivery delays
+	if exec.Result != nil && exec.Result.TotalDuration != nil {
+		switch aws.StringValue(exec.Status) {
+		case "SUCCESS", "ERROR":
+			end := aws.TimeValue(exec.StartTime).Add(time.Duration(aws.Int64Value(exec.Result.TotalDuration))*time.Millisecond + 5*time.Minute)
+			input.EndTime = aws.Int64(end.UnixMilli())
+		}
+	}
+
+	out, err := o.logsClient.FilterLogEvents(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+
+	events := make([]*DatamoverLogEvent, 0, len(out.Events))
+	for _, e := range out.Events {
+		events = append(events, &DatamoverLogEvent{
+			Timestamp: time.UnixMilli(aws.Int64Value(e.Timestamp)).UTC(),
+			Message:   aws.StringValue(e.Message),
+		})
+	}
+
+	return &DatamoverRunLogs{
+		Events:    events,
+		NextToken: out.NextToken,
+	}, nil
+}
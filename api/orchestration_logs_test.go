@@ -0,0 +1,251 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/YaleSpinup/apierror"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/cloudwatchlogs"
+	"github.com/aws/aws-sdk-go/service/cloudwatchlogs/cloudwatchlogsiface"
+	"github.com/aws/aws-sdk-go/service/datasync"
+	"github.com/stretchr/testify/assert"
+)
+
+type mockCloudWatchLogs struct {
+	cloudwatchlogsiface.CloudWatchLogsAPI
+	t   *testing.T
+	err error
+	// retentionErr and policyErr are returned by PutRetentionPolicyWithContext and PutResourcePolicyWithContext
+	retentionErr error
+	policyErr    error
+
+	// deleted is the list of log groups passed to DeleteLogGroupWithContext
+	deleted []string
+	// filtered is the last input passed to FilterLogEventsWithContext
+	filtered *cloudwatchlogs.FilterLogEventsInput
+}
+
+func newMockCloudWatchLogs(t *testing.T, err error) cloudwatchlogsiface.CloudWatchLogsAPI {
+	return &mockCloudWatchLogs{
+		t:   t,
+		err: err,
+	}
+}
+
+func (m *mockCloudWatchLogs) CreateLogGroupWithContext(ctx context.Context, input *cloudwatchlogs.CreateLogGroupInput, opts ...request.Option) (*cloudwatchlogs.CreateLogGroupOutput, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+
+	return &cloudwatchlogs.CreateLogGroupOutput{}, nil
+}
+
+func (m *mockCloudWatchLogs) PutRetentionPolicyWithContext(ctx context.Context, input *cloudwatchlogs.PutRetentionPolicyInput, opts ...request.Option) (*cloudwatchlogs.PutRetentionPolicyOutput, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+
+	if m.retentionErr != nil {
+		return nil, m.retentionErr
+	}
+
+	return &cloudwatchlogs.PutRetentionPolicyOutput{}, nil
+}
+
+func (m *mockCloudWatchLogs) PutResourcePolicyWithContext(ctx context.Context, input *cloudwatchlogs.PutResourcePolicyInput, opts ...request.Option) (*cloudwatchlogs.PutResourcePolicyOutput, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+
+	if m.policyErr != nil {
+		return nil, m.policyErr
+	}
+
+	return &cloudwatchlogs.PutResourcePolicyOutput{}, nil
+}
+
+func (m *mockCloudWatchLogs) DescribeLogGroupsWithContext(ctx context.Context, input *cloudwatchlogs.DescribeLogGroupsInput, opts ...request.Option) (*cloudwatchlogs.DescribeLogGroupsOutput, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+
+	return &cloudwatchlogs.DescribeLogGroupsOutput{
+		LogGroups: []*cloudwatchlogs.LogGroup{
+			{
+				LogGroupName: input.LogGroupNamePrefix,
+				Arn:          aws.String("arn:aws:logs:us-east-1:012345678901:log-group:" + aws.StringValue(input.LogGroupNamePrefix) + ":*"),
+			},
+		},
+	}, nil
+}
+
+func (m *mockCloudWatchLogs) DeleteLogGroupWithContext(ctx context.Context, input *cloudwatchlogs.DeleteLogGroupInput, opts ...request.Option) (*cloudwatchlogs.DeleteLogGroupOutput, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+
+	m.deleted = append(m.deleted, aws.StringValue(input.LogGroupName))
+
+	return &cloudwatchlogs.DeleteLogGroupOutput{}, nil
+}
+
+func (m *mockCloudWatchLogs) FilterLogEventsWithContext(ctx context.Context, input *cloudwatchlogs.FilterLogEventsInput, opts ...request.Option) (*cloudwatchlogs.FilterLogEventsOutput, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+
+	m.filtered = input
+
+	return &cloudwatchlogs.FilterLogEventsOutput{
+		Events: []*cloudwatchlogs.FilteredLogEvent{
+			{Timestamp: aws.Int64(testTime.UnixMilli()), Message: aws.String("[INFO] Execution exec-2 started.")},
+		},
+	}, nil
+}
+
+// mockLogsDataSync returns the tasks of the index mock with logging enabled
+type mockLogsDataSync struct {
+	*mockIndexDataSync
+}
+
+func (d *mockLogsDataSync) DescribeTaskWithContext(ctx context.Context, input *datasync.DescribeTaskInput, opts ...request.Option) (*datasync.DescribeTaskOutput, error) {
+	out, err := d.mockIndexDataSync.DescribeTaskWithContext(ctx, input, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	out.CloudWatchLogGroupArn = aws.String("arn:aws:logs:us-east-1:012345678901:log-group:/aws/datasync/spinup/org/group1/" + aws.StringValue(out.Name))
+	return out, nil
+}
+
+func Test_logGroupNameFromArn(t *testing.T) {
+	tests := []struct {
+		arn     string
+		want    string
+		wantErr bool
+	}{
+		{"arn:aws:logs:us-east-1:012345678901:log-group:/aws/datasync/foo:*", "/aws/datasync/foo", false},
+		{"arn:aws:logs:us-east-1:012345678901:log-group:/aws/datasync/foo", "/aws/datasync/foo", false},
+		{"arn:aws:datasync:us-east-1:012345678901:task/task-05cd6f77d7b5d15ac", "", true},
+		{"foo", "", true},
+	}
+
+	for _, tt := range tests {
+		got, err := logGroupNameFromArn(tt.arn)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("logGroupNameFromArn(%s) error = %v, wantErr %v", tt.arn, err, tt.wantErr)
+		}
+
+		if got != tt.want {
+			t.Errorf("logGroupNameFromArn(%s) = %s, want %s", tt.arn, got, tt.want)
+		}
+	}
+}
+
+func Test_createLogGroup(t *testing.T) {
+	o := newMockDataSyncOrchestrator(t)
+	o.server.org = "spindev"
+
+	got, err := o.createLogGroup(context.TODO(), "group1", "mover1", nil, Tags{})
+	if err != nil {
+		t.Fatalf("expected nil error, got %s", err)
+	}
+
+	assert.Equal(t, "arn:aws:logs:us-east-1:012345678901:log-group:/aws/datasync/spinup/spindev/group1/mover1", got)
+
+	o.logsClient.Service = newMockCloudWatchLogs(t, awserr.New(cloudwatchlogs.ErrCodeResourceAlreadyExistsException, "exists", nil))
+	if _, err := o.createLogGroup(context.TODO(), "group1", "mover1", nil, Tags{}); err == nil {
+		t.Error("expected error for existing log group, got nil")
+	} else if aerr, ok := err.(apierror.Error); !ok || aerr.Code != apierror.ErrConflict {
+		t.Errorf("expected conflict error, got %s", err)
+	}
+
+	// the log group is deleted when a step after creating it fails
+	for _, m := range []*mockCloudWatchLogs{
+		{t: t, retentionErr: awserr.New(cloudwatchlogs.ErrCodeServiceUnavailableException, "unavailable", nil)},
+		{t: t, policyErr: awserr.New(cloudwatchlogs.ErrCodeLimitExceededException, "too many policies", nil)},
+	} {
+		o.logsClient.Service = m
+		if _, err := o.createLogGroup(context.TODO(), "group1", "mover1", nil, Tags{}); err == nil {
+			t.Error("expected error, got nil")
+		}
+
+		assert.Equal(t, []string{"/aws/datasync/spinup/spindev/group1/mover1"}, m.deleted)
+	}
+}
+
+func Test_deleteLogGroup(t *testing.T) {
+	o := newMockDataSyncOrchestrator(t)
+	o.server.org = "spindev"
+
+	if err := o.deleteLogGroup(context.TODO(), "arn:aws:logs:us-east-1:012345678901:log-group:/aws/datasync/spinup/spindev/group1/mover1:*"); err != nil {
+		t.Errorf("expected nil error, got %s", err)
+	}
+
+	// log groups not created for a mover aren't deleted
+	if err := o.deleteLogGroup(context.TODO(), "arn:aws:logs:us-east-1:012345678901:log-group:/aws/datasync:*"); err != nil {
+		t.Errorf("expected nil error, got %s", err)
+	}
+
+	assert.Equal(t, []string{"/aws/datasync/spinup/spindev/group1/mover1"}, o.logsClient.Service.(*mockCloudWatchLogs).deleted)
+
+	// missing log groups are ignored
+	o.logsClient.Service = newMockCloudWatchLogs(t, awserr.New(cloudwatchlogs.ErrCodeResourceNotFoundException, "not found", nil))
+	if err := o.deleteLogGroup(context.TODO(), "arn:aws:logs:us-east-1:012345678901:log-group:/aws/datasync/spinup/spindev/group1/mover1:*"); err != nil {
+		t.Errorf("expected nil error for missing log group, got %s", err)
+	}
+}
+
+func Test_logsResourcePolicy(t *testing.T) {
+	o := newMockDataSyncOrchestrator(t)
+	o.account = "012345678901"
+	o.server.org = "spindev"
+
+	policy, err := o.logsResourcePolicy()
+	if err != nil {
+		t.Fatalf("expected nil error, got %s", err)
+	}
+
+	var doc map[string]interface{}
+	if err := json.Unmarshal([]byte(policy), &doc); err != nil {
+		t.Fatalf("failed to unmarshal policy: %s", err)
+	}
+
+	assert.Contains(t, policy, `"Service":["datasync.amazonaws.com"]`)
+	assert.Contains(t, policy, "arn:aws:logs:*:012345678901:log-group:/aws/datasync/spinup/spindev/*")
+}
+
+func Test_datamoverRunLogsNotEnabled(t *testing.T) {
+	is_running = false
+
+	o := newMockDataSyncOrchestrator(t)
+	_, err := o.datamoverRunLogs(context.TODO(), "group1", "name1", "exec-086d6c629a6bf3581", 100, "")
+	if aerr, ok := err.(apierror.Error); !ok || aerr.Code != apierror.ErrNotFound {
+		t.Errorf("expected not found error for mover without logging, got %v", err)
+	}
+}
+
+func Test_datamoverRunLogs(t *testing.T) {
+	o, ds := newMockIndexOrchestrator(t, 1)
+	o.datasyncClient.Service = &mockLogsDataSync{ds}
+	logs := &mockCloudWatchLogs{t: t}
+	o.logsClient.Service = logs
+
+	out, err := o.datamoverRunLogs(context.TODO(), "group1", "mover0", "exec-2", 100, "")
+	if err != nil {
+		t.Fatalf("expected nil error, got %s", err)
+	}
+
+	assert.Len(t, out.Events, 1)
+	assert.Equal(t, "/aws/datasync/spinup/org/group1/mover0", aws.StringValue(logs.filtered.LogGroupName))
+
+	// the events are limited to the run, by ID and by its duration
+	assert.Equal(t, `"exec-2"`, aws.StringValue(logs.filtered.FilterPattern))
+	assert.Equal(t, testTime.Add(2*time.Hour).UnixMilli(), aws.Int64Value(logs.filtered.StartTime))
+	assert.Equal(t, testTime.Add(2*time.Hour+time.Minute+5*time.Minute).UnixMilli(), aws.Int64Value(logs.filtered.EndTime))
+}
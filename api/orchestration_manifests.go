@@ -0,0 +1,133 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"hash/crc32"
+	"strings"
+	"time"
+
+	"github.com/YaleSpinup/apierror"
+	"github.com/YaleSpinup/aws-go/services/resourcegroupstaggingapi"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/datasync"
+	log "github.com/sirupsen/logrus"
+)
+
+// manifestRoleName returns the name of the role DataSync uses to read the manifest of a mover.  The name
+// includes a hash of the group, so movers with the same name in different groups don't share a role.
+func manifestRoleName(group, mover string) string {
+	return fmt.Sprintf("%s-manifest-%08x", mover, crc32.ChecksumIEEE([]byte(group)))
+}
+
+// manifestRunRoleName returns the name of the role DataSync uses to read the manifests passed when starting
+// a run.  It's separate from the mover's manifest role, so later runs can still read the mover's manifest.
+func manifestRunRoleName(group, mover string) string {
+	return fmt.Sprintf("%s-manifest-run-%08x", mover, crc32.ChecksumIEEE([]byte(group)))
+}
+
+// bucketInGroup returns an error if the bucket isn't tagged as belonging to the group
+func (o *datasyncOrchestrator) bucketInGroup(ctx context.Context, group, bucketArn string) error {
+	filters := []*resourcegroupstaggingapi.TagFilter{
+		{
+			Key:   "spinup:org",
+			Value: []string{o.server.org},
+		},
+		{
+			Key:   "spinup:spaceid",
+			Value: []string{group},
+		},
+	}
+
+	out, err := o.rgClient.GetResourcesWithTags(ctx, []string{"s3"}, filters)
+	if err != nil {
+		return err
+	}
+
+	for _, r := range out {
+		if aws.StringValue(r.ResourceARN) == bucketArn {
+			return nil
+		}
+	}
+
+	return apierror.New(apierror.ErrForbidden, "bucket "+bucketArn+" doesn't belong to group "+group, nil)
+}
+
+// manifestConfig generates (or updates) the role DataSync uses to read the manifest object and returns
+// the manifest configuration for a task or task execution
+func (o *datasyncOrchestrator) manifestConfig(ctx context.Context, group, role string, input *DatamoverManifestInput, tags Tags) (*datasync.ManifestConfig, error) {
+	if input == nil {
+		return nil, apierror.New(apierror.ErrBadRequest, "invalid input", nil)
+	}
+
+	path := fmt.Sprintf("/spinup/%s/%s/", o.server.org, group)
+
+	roleArn, err := o.bucketAccessRole(ctx, path, role, aws.StringValue(input.S3BucketArn), &bucketAccessScope{manifestObject: input.ObjectPath}, tags)
+	if err != nil {
+		return nil, err
+	}
+
+	return &datasync.ManifestConfig{
+		Action: aws.String(datasync.ManifestActionTransfer),
+		Format: aws.String(datasync.ManifestFormatCsv),
+		Source: &datasync.SourceManifestConfig{
+			S3: &datasync.S3ManifestConfig{
+				BucketAccessRoleArn:     aws.String(roleArn),
+				ManifestObjectPath:      input.ObjectPath,
+				ManifestObjectVersionId: input.ObjectVersionId,
+				S3BucketArn:             input.S3BucketArn,
+			},
+		},
+	}, nil
+}
+
+// roleNotAssumable returns whether DataSync rejected a request because it couldn't assume an IAM role,
+// which happens while a role that was just created propagates across AWS
+func roleNotAssumable(err error) bool {
+	aerr, ok := err.(apierror.Error)
+	if !ok || aerr.Code != apierror.ErrBadRequest {
+		return false
+	}
+
+	awsErr, ok := aerr.OrigErr.(awserr.Error)
+	if !ok || awsErr.Code() != datasync.ErrCodeInvalidRequestException {
+		return false
+	}
+
+	return strings.Contains(strings.ToLower(awsErr.Message()), "assume")
+}
+
+// retryRolePropagation retries f for a few seconds while it fails because DataSync can't assume a role
+// yet, other errors (like a missing manifest object) are returned right away.  It's used in requests, so
+// the retries must finish well within the server's write timeout.
+func retryRolePropagation(f func() error) error {
+	return retry(3, 0, 2*time.Second, func() error {
+		err := f()
+		if err == nil {
+			return nil
+		}
+
+		if roleNotAssumable(err) {
+			log.Warnf("retrying while role propagates: %s", err)
+			return err
+		}
+
+		return stop{err}
+	})
+}
+
+// deleteManifestRole deletes a manifest role of a mover if it exists
+func (o *datasyncOrchestrator) deleteManifestRole(ctx context.Context, name string) error {
+	role, err := o.iamClient.GetRole(ctx, name)
+	if err != nil {
+		if aerr, ok := err.(apierror.Error); ok && aerr.Code == apierror.ErrNotFound {
+			log.Debugf("manifest role %s not found, not deleting", name)
+			return nil
+		}
+
+		return err
+	}
+
+	return o.deleteBucketAccessRole(ctx, role.Arn)
+}
@@ -0,0 +1,167 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/YaleSpinup/apierror"
+	yiam "github.com/YaleSpinup/aws-go/services/iam"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/datasync"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_manifestRoleName(t *testing.T) {
+	a := manifestRoleName("group1", "mover1")
+	b := manifestRoleName("group2", "mover1")
+
+	if a == b {
+		t.Errorf("expected different role names for movers in different groups, got %s", a)
+	}
+
+	if a != manifestRoleName("group1", "mover1") {
+		t.Errorf("expected the same role name for the same mover")
+	}
+
+	if len(manifestRoleName("group1", "abcdefghijklmnopqrstuvwxyz01234567890123")) > 64 {
+		t.Errorf("expected role name to be at most 64 characters")
+	}
+
+	if manifestRunRoleName("group1", "mover1") == a {
+		t.Errorf("expected a different role for run manifests, got %s", a)
+	}
+
+	if len(manifestRunRoleName("group1", "abcdefghijklmnopqrstuvwxyz01234567890123")) > 64 {
+		t.Errorf("expected run role name to be at most 64 characters")
+	}
+}
+
+func Test_bucketAccessPolicyManifest(t *testing.T) {
+	policy := bucketAccessPolicy("arn:aws:s3:::manifests", &bucketAccessScope{manifestObject: aws.String("lists/today.csv")})
+
+	assert.Equal(t, []yiam.StatementEntry{
+		{
+			Sid:    "GetManifest",
+			Effect: "Allow",
+			Action: []string{
+				"s3:GetObject",
+				"s3:GetObjectVersion",
+			},
+			Resource: []string{"arn:aws:s3:::manifests/lists/today.csv"},
+		},
+	}, policy.Statement)
+}
+
+func Test_bucketInGroup(t *testing.T) {
+	o := newMockDataSyncOrchestrator(t)
+
+	// the mock resource groups client only returns a datasync task
+	err := o.bucketInGroup(context.TODO(), "group1", "arn:aws:s3:::manifests")
+	if aerr, ok := err.(apierror.Error); !ok || aerr.Code != apierror.ErrForbidden {
+		t.Errorf("expected forbidden error, got %v", err)
+	}
+
+	if err := o.bucketInGroup(context.TODO(), "group1", "arn:aws:datasync:us-east-1:012345678901:task/task-05cd6f77d7b5d15ac/execution/exec-086d6c629a6bf3581"); err != nil {
+		t.Errorf("expected nil error, got %s", err)
+	}
+}
+
+func Test_startTaskRunManifestNotInGroup(t *testing.T) {
+	is_running = false
+	o := newMockDataSyncOrchestrator(t)
+
+	_, err := o.startTaskRun(context.TODO(), "group1", "name1", &DatamoverRunOverrides{
+		Manifest: &DatamoverManifestInput{
+			S3BucketArn: aws.String("arn:aws:s3:::someone-elses-bucket"),
+			ObjectPath:  aws.String("lists/today.csv"),
+		},
+	})
+	if aerr, ok := err.(apierror.Error); !ok || aerr.Code != apierror.ErrForbidden {
+		t.Errorf("expected forbidden error, got %v", err)
+	}
+
+	if o.datasyncClient.Service.(*mockDataSync).startInput != nil {
+		t.Error("expected the run not to be started")
+	}
+}
+
+func Test_startTaskRunManifest(t *testing.T) {
+	is_running = false
+	o := newMockDataSyncOrchestrator(t)
+
+	moverPolicy, err := json.Marshal(bucketAccessPolicy("arn:aws:s3:::manifests", &bucketAccessScope{manifestObject: aws.String("lists/mover.csv")}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	roles := map[string]string{
+		manifestRoleName("group1", "name1"):    string(moverPolicy),
+		manifestRunRoleName("group1", "name1"): "",
+	}
+	o.iamClient = yiam.IAM{Service: newMockIAM(t, roles)}
+
+	// the mock resource groups client only returns a datasync task, so it stands in for the bucket
+	bucketArn := "arn:aws:datasync:us-east-1:012345678901:task/task-05cd6f77d7b5d15ac/execution/exec-086d6c629a6bf3581"
+	if _, err := o.startTaskRun(context.TODO(), "group1", "name1", &DatamoverRunOverrides{
+		Manifest: &DatamoverManifestInput{
+			S3BucketArn: aws.String(bucketArn),
+			ObjectPath:  aws.String("lists/today.csv"),
+		},
+	}); err != nil {
+		t.Fatalf("expected nil error, got %s", err)
+	}
+
+	// the run reads its manifest with the run role, the mover's manifest role isn't changed
+	input := o.datasyncClient.Service.(*mockDataSync).startInput
+	if assert.NotNil(t, input.ManifestConfig) {
+		assert.Equal(t, "arn:aws:iam::012345678901:role/spinup/org/group1/"+manifestRunRoleName("group1", "name1"), aws.StringValue(input.ManifestConfig.Source.S3.BucketAccessRoleArn))
+		assert.Equal(t, "lists/today.csv", aws.StringValue(input.ManifestConfig.Source.S3.ManifestObjectPath))
+	}
+
+	assert.Equal(t, string(moverPolicy), roles[manifestRoleName("group1", "name1")])
+	assert.Contains(t, roles[manifestRunRoleName("group1", "name1")], bucketArn+"/lists/today.csv")
+}
+
+func Test_roleNotAssumable(t *testing.T) {
+	tests := []struct {
+		err  error
+		want bool
+	}{
+		{
+			err:  apierror.New(apierror.ErrBadRequest, "failed to start task execution", awserr.New(datasync.ErrCodeInvalidRequestException, "DataSync is unable to assume the IAM role", nil)),
+			want: true,
+		},
+		{
+			err:  apierror.New(apierror.ErrBadRequest, "failed to start task execution", awserr.New(datasync.ErrCodeInvalidRequestException, "manifest object lists/today.csv not found", nil)),
+			want: false,
+		},
+		{
+			err:  apierror.New(apierror.ErrLimitExceeded, "failed to start task execution", awserr.New("LimitExceeded", "unable to assume role", nil)),
+			want: false,
+		},
+		{
+			err:  apierror.New(apierror.ErrBadRequest, "invalid input", nil),
+			want: false,
+		},
+	}
+
+	for _, test := range tests {
+		assert.Equal(t, test.want, roleNotAssumable(test.err), test.err.Error())
+	}
+}
+
+func Test_retryRolePropagation(t *testing.T) {
+	calls := 0
+	err := retryRolePropagation(func() error {
+		calls++
+		return apierror.New(apierror.ErrBadRequest, "failed to start task execution", awserr.New(datasync.ErrCodeInvalidRequestException, "manifest object lists/today.csv not found", nil))
+	})
+
+	// errors other than DataSync failing to assume a role aren't retried
+	assert.Equal(t, 1, calls)
+	if aerr, ok := err.(apierror.Error); !ok || aerr.Code != apierror.ErrBadRequest {
+		t.Errorf("expected bad request error, got %v", err)
+	}
+}
@@ -23,13 +23,38 @@ func (o *datasyncOrchestrator) datamoverCreate(ctx context.Context, group string
 
 	req.Tags = req.Tags.normalize(o.server.org, group)
 
+	name := aws.StringValue(req.Name)
+
+	// the name is reserved until the create finishes, so concurrent creates can't both find it available
+	if err := o.reserveMoverName(ctx, group, name); err != nil {
+		return nil, err
+	}
+
+	if err := o.moverNameAvailable(ctx, group, name); err != nil {
+		o.releaseMoverName(ctx, group, name)
+		return nil, err
+	}
+
+	if req.Manifest != nil {
+		if err := o.bucketInGroup(ctx, group, aws.StringValue(req.Manifest.S3BucketArn)); err != nil {
+			o.releaseMoverName(ctx, group, name)
+			return nil, err
+		}
+	}
+
 	task := flywheel.NewTask()
 
 	// start async orchestration to create all components of the data mover
 	go func() {
+		// released last, once the task exists or the rollback has finished
+		defer o.releaseMoverName(context.Background(), group, name)
+
 		taskCtx, cancel := context.WithCancel(context.Background())
 		defer cancel()
 
+		// the new mover (or a failed create) changes the tasks in the group
+		defer o.server.invalidateTaskIndex(o.account, o.region, group)
+
 		msgChan, errChan := o.startTask(taskCtx, task)
 
 		// setup err var, rollback function list and defer execution
@@ -43,7 +68,68 @@ func (o *datasyncOrchestrator) datamoverCreate(ctx context.Context, group string
 			}
 		}()
 
-		var srcLocationArn, dstLocationArn string
+		var srcLocationArn, dstLocationArn, logGroupArn, reportRoleArn string
+
+		if req.Logging != nil {
+			msgChan <- "requested creation of log group"
+			logGroupArn, err = o.createLogGroup(taskCtx, group, aws.StringValue(req.Name), req.Logging, req.Tags)
+			if err != nil {
+				errChan <- fmt.Errorf("failed to create log group: %s", err.Error())
+				return
+			}
+
+			rollBackTasks = append(rollBackTasks, func(ctx context.Context) error {
+				log.Errorf("rollback: deleting log group: %s", logGroupArn)
+
+				if err := o.deleteLogGroup(ctx, logGroupArn); err != nil {
+					log.Warnf("rollback: error deleting log group: %s", err)
+					return err
+				}
+
+				return nil
+			})
+		}
+
+		if req.Report != nil {
+			msgChan <- "requested creation of task report role"
+			reportRoleArn, err = o.createReportRole(taskCtx, group, aws.StringValue(req.Name), req.Report, req.Tags)
+			if err != nil {
+				errChan <- fmt.Errorf("failed to create task report role: %s", err.Error())
+				return
+			}
+
+			rollBackTasks = append(rollBackTasks, func(ctx context.Context) error {
+				log.Errorf("rollback: deleting task report role: %s", reportRoleArn)
+
+				if err := o.deleteBucketAccessRole(ctx, aws.String(reportRoleArn)); err != nil {
+					log.Warnf("rollback: error deleting task report role: %s", err)
+					return err
+				}
+
+				return nil
+			})
+		}
+
+		var manifest *datasync.ManifestConfig
+		if req.Manifest != nil {
+			msgChan <- "requested creation of manifest role"
+			manifest, err = o.manifestConfig(taskCtx, group, manifestRoleName(group, aws.StringValue(req.Name)), req.Manifest, req.Tags)
+			if err != nil {
+				errChan <- fmt.Errorf("failed to create manifest role: %s", err.Error())
+				return
+			}
+
+			rollBackTasks = append(rollBackTasks, func(ctx context.Context) error {
+				log.Errorf("rollback: deleting manifest role: %s", aws.StringValue(manifest.Source.S3.BucketAccessRoleArn))
+
+				if err := o.deleteBucketAccessRole(ctx, manifest.Source.S3.BucketAccessRoleArn); err != nil {
+					log.Warnf("rollback: error deleting manifest role: %s", err)
+					return err
+				}
+
+				return nil
+			})
+		}
 
 		msgChan <- "requested creation of source location"
 		srcLocationArn, err = o.createDatasyncLocation(taskCtx, aws.StringValue(req.Name), group, req.Source, req.Tags)
@@ -83,19 +169,46 @@ func (o *datasyncOrchestrator) datamoverCreate(ctx context.Context, group string
 
 		var t *datasync.CreateTaskOutput
 
-		msgChan <- fmt.Sprintf("requested creation of datasync task %s", aws.StringValue(req.Name))
-		t, err = o.datasyncClient.CreateDatasyncTask(taskCtx, &datasync.CreateTaskInput{
+		input := &datasync.CreateTaskInput{
 			DestinationLocationArn: aws.String(dstLocationArn),
 			Name:                   req.Name,
 			SourceLocationArn:      aws.String(srcLocationArn),
-			Options: &datasync.Options{
-				// eventually we may allow for customizing these
-				PreserveDeletedFiles: aws.String("PRESERVE"),
-				TransferMode:         aws.String("CHANGED"),
-				VerifyMode:           aws.String("ONLY_FILES_TRANSFERRED"),
-			},
-			Tags: req.Tags.toDatasyncTags(),
-		})
+			Excludes:               filterRules(req.Excludes),
+			Includes:               filterRules(req.Includes),
+			Options:                datasyncOptions(req.Options),
+			Schedule:               taskSchedule(req.Schedule),
+			Tags:                   req.Tags.toDatasyncTags(),
+		}
+
+		if reportRoleArn != "" {
+			input.TaskReportConfig = taskReportConfig(req.Report, reportRoleArn)
+		}
+
+		if manifest != nil {
+			input.ManifestConfig = manifest
+		}
+
+		// log basic information (errors and transfer summaries) unless the log level is set
+		if logGroupArn != "" {
+			input.CloudWatchLogGroupArn = aws.String(logGroupArn)
+			if input.Options.LogLevel == nil {
+				input.Options.LogLevel = aws.String(datasync.LogLevelBasic)
+			}
+		}
+
+		msgChan <- fmt.Sprintf("requested creation of datasync task %s", aws.StringValue(req.Name))
+		if reportRoleArn != "" || manifest != nil {
+			// new task report and manifest roles may take some time to propagate across AWS
+			err = retry(6, 0, 5*time.Second, func() error {
+				log.Info("retrying to create datasync task ...")
+
+				var err error
+				t, err = o.datasyncClient.CreateDatasyncTask(taskCtx, input)
+				return err
+			})
+		} else {
+			t, err = o.datasyncClient.CreateDatasyncTask(taskCtx, input)
+		}
 		if err != nil {
 			errChan <- fmt.Errorf("failed to create datasync task: %s", err.Error())
 			return
@@ -115,33 +228,765 @@ func (o *datasyncOrchestrator) datamoverCreate(ctx context.Context, group string
 	return task, nil
 }
 
-// datamoverDelete deletes a data mover and all of its associated components
-func (o *datasyncOrchestrator) datamoverDelete(ctx context.Context, group, name string) error {
+// moverReservationTTL is how long a mover name stays reserved when a create never finishes, ie. the API is restarted
+const moverReservationTTL = time.Hour
+
+// moverReservationKey returns the store key reserving a mover name.  Names are unique across the regions of
+// an account (see moverNameAvailable), so reservations aren't scoped to a region.
+func moverReservationKey(account, group, name string) string {
+	return "reservations:" + account + "/" + group + "/" + name
+}
+
+// reserveMoverName reserves the name of a mover being created, so creates on any instance of the API
+// can't use the name until the create finishes
+func (o *datasyncOrchestrator) reserveMoverName(ctx context.Context, group, name string) error {
+	ok, err := o.server.store.setNX(ctx, moverReservationKey(o.account, group, name), o.region, moverReservationTTL)
+	if err != nil {
+		return apierror.New(apierror.ErrInternalError, "failed to reserve datasync mover name", err)
+	}
+
+	if !ok {
+		return apierror.New(apierror.ErrConflict, "datasync mover "+name+" is already being created", nil)
+	}
+
+	return nil
+}
+
+// releaseMoverName releases the reservation of a mover name
+func (o *datasyncOrchestrator) releaseMoverName(ctx context.Context, group, name string) {
+	if err := o.server.store.del(ctx, moverReservationKey(o.account, group, name)); err != nil {
+		log.Errorf("failed to release reservation of datasync mover name %s: %s", name, err)
+	}
+}
+
+// moverNameAvailable returns a conflict error if a mover with the name already exists in the group, in
+// any of the configured regions.  Movers are looked up by name, so names must be unique in a group.
+func (o *datasyncOrchestrator) moverNameAvailable(ctx context.Context, group, name string) error {
+	_, _, err := o.taskDetailsFromName(ctx, group, name)
+	if err == nil {
+		return apierror.New(apierror.ErrConflict, "datasync mover "+name+" already exists", nil)
+	}
+
+	if aerr, ok := err.(apierror.Error); !ok || aerr.Code != apierror.ErrNotFound {
+		return err
+	}
+
+	if o.accountConfig == nil {
+		return nil
+	}
+
+	// the IAM roles of a mover are named after it and IAM is global, so names are unique across regions
+	for _, r := range o.accountConfig.regions {
+		if r == o.region {
+			continue
+		}
+
+		other, err := o.inRegion(ctx, r)
+		if err != nil {
+			return err
+		}
+
+		_, _, err = other.taskDetailsFromName(ctx, group, name)
+		if err == nil {
+			return apierror.New(apierror.ErrConflict, "datasync mover "+name+" already exists in region "+r, nil)
+		}
+
+		if aerr, ok := err.(apierror.Error); !ok || aerr.Code != apierror.ErrNotFound {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// bucketAccessRoleName returns the name of the role DataSync uses to access the bucket of an S3 location,
+// an 8-char CRC32 hash based on the bucket name limits the length of the role name
+func bucketAccessRoleName(mover, bucket string) string {
+	return fmt.Sprintf("%s-%08x", mover, crc32.ChecksumIEEE([]byte(bucket)))
+}
+
+// datasyncOptions converts the (optional) mover options to DataSync task options, keeping our
+// defaults for PreserveDeletedFiles, TransferMode and VerifyMode when they aren't passed
+func datasyncOptions(opts *DatamoverOptions) *datasync.Options {
+	if opts == nil {
+		opts = &DatamoverOptions{}
+	}
+
+	out := &datasync.Options{
+		Atime:                       opts.Atime,
+		BytesPerSecond:              opts.BytesPerSecond,
+		Gid:                         opts.Gid,
+		LogLevel:                    opts.LogLevel,
+		Mtime:                       opts.Mtime,
+		ObjectTags:                  opts.ObjectTags,
+		OverwriteMode:               opts.OverwriteMode,
+		PosixPermissions:            opts.PosixPermissions,
+		PreserveDeletedFiles:        opts.PreserveDeletedFiles,
+		PreserveDevices:             opts.PreserveDevices,
+		SecurityDescriptorCopyFlags: opts.SecurityDescriptorCopyFlags,
+		TaskQueueing:                opts.TaskQueueing,
+		TransferMode:                opts.TransferMode,
+		Uid:                         opts.Uid,
+		VerifyMode:                  opts.VerifyMode,
+	}
+
+	if out.PreserveDeletedFiles == nil {
+		out.PreserveDeletedFiles = aws.String(datasync.PreserveDeletedFilesPreserve)
+	}
+
+	if out.TransferMode == nil {
+		out.TransferMode = aws.String(datasync.TransferModeChanged)
+	}
+
+	if out.VerifyMode == nil {
+		out.VerifyMode = aws.String(datasync.VerifyModeOnlyFilesTransferred)
+	}
+
+	return out
+}
+
+// fromDatasyncOptions converts DataSync task options to mover options
+func fromDatasyncOptions(opts *datasync.Options) *DatamoverOptions {
+	if opts == nil {
+		return &DatamoverOptions{}
+	}
+
+	return &DatamoverOptions{
+		Atime:                       opts.Atime,
+		BytesPerSecond:              opts.BytesPerSecond,
+		Gid:                         opts.Gid,
+		LogLevel:                    opts.LogLevel,
+		Mtime:                       opts.Mtime,
+		ObjectTags:                  opts.ObjectTags,
+		OverwriteMode:               opts.OverwriteMode,
+		PosixPermissions:            opts.PosixPermissions,
+		PreserveDeletedFiles:        opts.PreserveDeletedFiles,
+		PreserveDevices:             opts.PreserveDevices,
+		SecurityDescriptorCopyFlags: opts.SecurityDescriptorCopyFlags,
+		TaskQueueing:                opts.TaskQueueing,
+		TransferMode:                opts.TransferMode,
+		Uid:                         opts.Uid,
+		VerifyMode:                  opts.VerifyMode,
+	}
+}
+
+// mergeOptions overrides the options in dst with any options set in src
+func mergeOptions(dst, src *DatamoverOptions) {
+	if src == nil {
+		return
+	}
+
+	merge := func(d **string, s *string) {
+		if s != nil {
+			*d = s
+		}
+	}
+
+	merge(&dst.Atime, src.Atime)
+	merge(&dst.Gid, src.Gid)
+	merge(&dst.LogLevel, src.LogLevel)
+	merge(&dst.Mtime, src.Mtime)
+	merge(&dst.ObjectTags, src.ObjectTags)
+	merge(&dst.OverwriteMode, src.OverwriteMode)
+	merge(&dst.PosixPermissions, src.PosixPermissions)
+	merge(&dst.PreserveDeletedFiles, src.PreserveDeletedFiles)
+	merge(&dst.PreserveDevices, src.PreserveDevices)
+	merge(&dst.SecurityDescriptorCopyFlags, src.SecurityDescriptorCopyFlags)
+	merge(&dst.TaskQueueing, src.TaskQueueing)
+	merge(&dst.TransferMode, src.TransferMode)
+	merge(&dst.Uid, src.Uid)
+	merge(&dst.VerifyMode, src.VerifyMode)
+
+	if src.BytesPerSecond != nil {
+		dst.BytesPerSecond = src.BytesPerSecond
+	}
+}
+
+// filterRules converts a list of filter patterns to a DataSync SIMPLE_PATTERN filter rule
+func filterRules(patterns []string) []*datasync.FilterRule {
+	if len(patterns) == 0 {
+		return nil
+	}
+
+	return []*datasync.FilterRule{
+		{
+			FilterType: aws.String(datasync.FilterTypeSimplePattern),
+			Value:      aws.String(strings.Join(patterns, "|")),
+		},
+	}
+}
+
+// updateFilterRules converts a list of filter patterns for a task update, where nil leaves the
+// filter unchanged and an empty list removes it
+func updateFilterRules(patterns []string) []*datasync.FilterRule {
+	if patterns == nil {
+		return nil
+	}
+
+	if len(patterns) == 0 {
+		return []*datasync.FilterRule{}
+	}
+
+	return filterRules(patterns)
+}
+
+// taskSchedule converts a schedule expression to a DataSync task schedule
+func taskSchedule(expr *string) *datasync.TaskSchedule {
+	if expr == nil {
+		return nil
+	}
+
+	return &datasync.TaskSchedule{ScheduleExpression: expr}
+}
+
+// nextRun returns the next time a task is scheduled to run, or nil if it doesn't have a schedule
+func nextRun(task *datasync.DescribeTaskOutput, now time.Time) *time.Time {
+	if task == nil || task.Schedule == nil || aws.StringValue(task.Schedule.ScheduleExpression) == "" {
+		return nil
+	}
+
+	s, err := parseSchedule(aws.StringValue(task.Schedule.ScheduleExpression))
+	if err != nil {
+		log.Warnf("unable to parse schedule for task %s: %s", aws.StringValue(task.TaskArn), err)
+		return nil
+	}
+
+	next := s.next(now, aws.TimeValue(task.CreationTime))
+	if next.IsZero() {
+		return nil
+	}
+
+	return &next
+}
+
+// datamoverUpdate updates the configuration of an existing data mover with a single UpdateTask
+// call and returns the updated data mover.  Updates that change tags use datamoverUpdateAsync.
+func (o *datasyncOrchestrator) datamoverUpdate(ctx context.Context, group, name string, req *DatamoverUpdateRequest) (*DatamoverResponse, error) {
+	log.Infof("updating data mover %s", name)
+
+	task, _, err := o.taskDetailsFromName(ctx, group, name)
+	if err != nil {
+		return nil, err
+	}
+
+	input, err := o.updateTaskInput(ctx, group, task, req)
+	if err != nil {
+		return nil, err
+	}
+
+	if input != nil {
+		if err := o.datasyncClient.UpdateDatasyncTask(ctx, input); err != nil {
+			return nil, err
+		}
+		o.server.invalidateTaskIndex(o.account, o.region, group)
+
+		name = aws.StringValue(input.Name)
+	}
+
+	return o.datamoverDescribe(ctx, group, name)
+}
+
+// datamoverUpdateAsync updates the configuration and tags of an existing data mover and returns
+// the async Flywheel task.  The task and both of its locations are re-tagged.
+func (o *datasyncOrchestrator) datamoverUpdateAsync(ctx context.Context, group, name string, req *DatamoverUpdateRequest) (*flywheel.Task, error) {
+	log.Infof("updating data mover %s and its tags", name)
+
+	task, tags, err := o.taskDetailsFromName(ctx, group, name)
+	if err != nil {
+		return nil, err
+	}
+
+	input, err := o.updateTaskInput(ctx, group, task, req)
+	if err != nil {
+		return nil, err
+	}
+
+	addTags, removeKeys := req.Tags.diff(tags)
+	restoreTags, restoreKeys := tags.diff(tags.apply(addTags, removeKeys))
+
+	resources := []string{
+		aws.StringValue(task.TaskArn),
+		aws.StringValue(task.SourceLocationArn),
+		aws.StringValue(task.DestinationLocationArn),
+	}
+
+	flywheelTask := flywheel.NewTask()
+
+	// start async orchestration to update all components of the data mover
+	go func() {
+		taskCtx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		defer o.server.invalidateTaskIndex(o.account, o.region, group)
+
+		msgChan, errChan := o.startTask(taskCtx, flywheelTask)
+
+		// setup err var, rollback function list and defer execution
+		// do not shadow err below for rollback to work properly
+		var err error
+		var rollBackTasks []rollbackFunc
+		defer func() {
+			if err != nil {
+				log.Errorf("recovering from error: %s, executing %d rollback tasks", err, len(rollBackTasks))
+				rollBack(&rollBackTasks)
+			}
+		}()
+
+		if input != nil {
+			msgChan <- fmt.Sprintf("requested update of datasync task %s", name)
+			if err = o.datasyncClient.UpdateDatasyncTask(taskCtx, input); err != nil {
+				errChan <- fmt.Errorf("failed to update datasync task: %s", err.Error())
+				return
+			}
+
+			rollBackTasks = append(rollBackTasks, func(ctx context.Context) error {
+				log.Errorf("rollback: reverting update of datasync task: %s", aws.StringValue(task.TaskArn))
+
+				if err := o.datasyncClient.UpdateDatasyncTask(ctx, revertTaskInput(task, input)); err != nil {
+					log.Warnf("rollback: error reverting datasync task: %s", err)
+					return err
+				}
+
+				return nil
+			})
+		}
+
+		for _, r := range resources {
+			resource := r
+
+			msgChan <- fmt.Sprintf("requested update of tags for %s", resource)
+			if err = o.updateDatasyncTags(taskCtx, resource, addTags, removeKeys); err != nil {
+				errChan <- fmt.Errorf("failed to update tags: %s", err.Error())
+				return
+			}
+
+			rollBackTasks = append(rollBackTasks, func(ctx context.Context) error {
+				log.Errorf("rollback: reverting tags for %s", resource)
+
+				if err := o.updateDatasyncTags(ctx, resource, restoreTags, restoreKeys); err != nil {
+					log.Warnf("rollback: error reverting tags: %s", err)
+					return err
+				}
+
+				return nil
+			})
+		}
+
+		msgChan <- fmt.Sprintf("updated data mover '%s'", name)
+	}()
+
+	return flywheelTask, nil
+}
+
+// updateTaskInput validates the requested changes against the current task and returns the
+// UpdateTask input, or nil if the task configuration doesn't change
+func (o *datasyncOrchestrator) updateTaskInput(ctx context.Context, group string, task *datasync.DescribeTaskOutput, req *DatamoverUpdateRequest) (*datasync.UpdateTaskInput, error) {
+	if req.Name == nil && req.Options == nil && req.BytesPerSecond == nil && req.Schedule == nil && req.Includes == nil && req.Excludes == nil {
+		return nil, nil
+	}
+
+	input := &datasync.UpdateTaskInput{
+		TaskArn:  task.TaskArn,
+		Name:     task.Name,
+		Excludes: updateFilterRules(req.Excludes),
+		Includes: updateFilterRules(req.Includes),
+	}
+
+	if req.Name != nil && aws.StringValue(req.Name) != aws.StringValue(task.Name) {
+		if err := o.moverNameAvailable(ctx, group, aws.StringValue(req.Name)); err != nil {
+			return nil, err
+		}
+
+		input.Name = req.Name
+	}
+
+	if req.Options != nil || req.BytesPerSecond != nil {
+		// options are merged with the current task options so the combinations are validated as a whole
+		opts := fromDatasyncOptions(task.Options)
+		mergeOptions(opts, req.Options)
+		if req.BytesPerSecond != nil {
+			opts.BytesPerSecond = req.BytesPerSecond
+		}
+
+		if err := validateOptions(opts, nil, aws.StringValue(task.CloudWatchLogGroupArn) != ""); err != nil {
+			return nil, err
+		}
+
+		input.Options = datasyncOptions(opts)
+	}
+
+	if req.Schedule != nil {
+		input.Schedule = taskSchedule(req.Schedule)
+	}
+
+	return input, nil
+}
+
+// revertTaskInput returns the UpdateTask input to revert the fields changed by input back to the
+// configuration of the task
+func revertTaskInput(task *datasync.DescribeTaskOutput, input *datasync.UpdateTaskInput) *datasync.UpdateTaskInput {
+	out := &datasync.UpdateTaskInput{
+		TaskArn: task.TaskArn,
+		Name:    task.Name,
+	}
+
+	if input.Options != nil {
+		out.Options = task.Options
+	}
+
+	if input.Schedule != nil {
+		out.Schedule = task.Schedule
+		if out.Schedule == nil {
+			out.Schedule = &datasync.TaskSchedule{ScheduleExpression: aws.String("")}
+		}
+	}
+
+	if input.Excludes != nil {
+		out.Excludes = task.Excludes
+		if out.Excludes == nil {
+			out.Excludes = []*datasync.FilterRule{}
+		}
+	}
+
+	if input.Includes != nil {
+		out.Includes = task.Includes
+		if out.Includes == nil {
+			out.Includes = []*datasync.FilterRule{}
+		}
+	}
+
+	return out
+}
+
+// updateDatasyncTags adds and removes tags on a datasync resource
+func (o *datasyncOrchestrator) updateDatasyncTags(ctx context.Context, rArn string, add Tags, remove []string) error {
+	if len(remove) > 0 {
+		if err := o.datasyncClient.UntagDatasyncResource(ctx, rArn, aws.StringSlice(remove)); err != nil {
+			return err
+		}
+	}
+
+	if len(add) > 0 {
+		if err := o.datasyncClient.TagDatasyncResource(ctx, rArn, add.toDatasyncTags()); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// deletingTagKey marks the task and locations of a data mover being deleted, so deleting the mover
+// again can find and finish deleting the locations that were left behind
+const deletingTagKey = "spinup:deleting"
+
+// deletingReportRoleTagKey records the task report role on the locations of a data mover being deleted,
+// the role is deleted after the task so deleting the mover again needs the locations to find it
+const deletingReportRoleTagKey = "spinup:deleting:reportrole"
+
+// deleteStep is a step in deleting a data mover
+type deleteStep struct {
+	// resource describes the resource deleted by the step, empty for steps that don't delete anything
+	resource string
+	msg      string
+	run      func(ctx context.Context) error
+	// rollback is executed if a later step fails, until a step with commit succeeds
+	rollback rollbackFunc
+	commit   bool
+}
+
+// datamoverDelete deletes a data mover and all of its associated components and returns the async
+// Flywheel task.  If the delete fails after the task is deleted, the remaining locations are left tagged
+// with spinup:deleting and deleting the mover again finishes the cleanup.  Resources that can't be deleted
+// are listed in the task's error.
+func (o *datasyncOrchestrator) datamoverDelete(ctx context.Context, group, name string) (*flywheel.Task, error) {
 	log.Infof("deleting data mover %s", name)
 
+	var steps []*deleteStep
+
 	// get information about the datasync task
 	mover, err := o.datamoverDescribe(ctx, group, name)
 	if err != nil {
-		return err
+		if aerr, ok := err.(apierror.Error); !ok || aerr.Code != apierror.ErrNotFound {
+			return nil, err
+		}
+
+		// the task may be gone from an earlier delete, look for locations left behind
+		leftovers, lerr := o.datamoverLeftovers(ctx, group, name)
+		if lerr != nil {
+			return nil, lerr
+		}
+
+		if len(leftovers.locations) == 0 {
+			return nil, err
+		}
+
+		log.Infof("data mover %s task not found, finishing the delete of %d locations left behind", name, len(leftovers.locations))
+
+		if leftovers.reportRoleArn != "" {
+			steps = append(steps, o.deleteReportRoleStep(aws.String(leftovers.reportRoleArn)))
+		}
+
+		// the roles and log group with names derived from the mover may be left behind too
+		steps = append(steps, o.deleteManifestRoleSteps(group, name)...)
+		steps = append(steps, o.deleteLogGroupStep(o.logGroupArn(group, name)))
+
+		for lArn, lType := range leftovers.locations {
+			steps = append(steps, o.deleteLocationStep(name, lArn, lType))
+		}
+	} else {
+		steps = o.datamoverDeleteSteps(group, name, mover)
 	}
 
-	// delete task
-	if _, err := o.datasyncClient.DeleteDatasyncTask(ctx, &datasync.DeleteTaskInput{
-		TaskArn: mover.Task.TaskArn,
-	}); err != nil {
-		return err
+	task := flywheel.NewTask()
+
+	// start async orchestration to delete all components of the data mover
+	go func() {
+		taskCtx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		defer o.server.invalidateTaskIndex(o.account, o.region, group)
+
+		msgChan, errChan := o.startTask(taskCtx, task)
+
+		// setup err var, rollback function list and defer execution
+		// do not shadow err below for rollback to work properly
+		var err error
+		var rollBackTasks []rollbackFunc
+		defer func() {
+			if err != nil {
+				log.Errorf("recovering from error: %s, executing %d rollback tasks", err, len(rollBackTasks))
+				rollBack(&rollBackTasks)
+			}
+		}()
+
+		for i, s := range steps {
+			step := s
+
+			msgChan <- step.msg
+			if err = retryTransient(func() error {
+				return ignoreNotFound(step.run(taskCtx))
+			}); err != nil {
+				remaining := []string{}
+				for _, r := range steps[i:] {
+					if r.resource != "" {
+						remaining = append(remaining, r.resource)
+					}
+				}
+
+				errChan <- fmt.Errorf("failed to delete data mover %s: %s, left behind: %s", name, err, strings.Join(remaining, ", "))
+				return
+			}
+
+			if step.rollback != nil {
+				rollBackTasks = append(rollBackTasks, step.rollback)
+			}
+
+			if step.commit {
+				rollBackTasks = nil
+			}
+		}
+
+		if mover != nil && mover.Task != nil {
+			o.server.notifier.forget(taskCtx, aws.StringValue(mover.Task.TaskArn))
+		}
+
+		msgChan <- fmt.Sprintf("deleted data mover '%s'", name)
+	}()
+
+	return task, nil
+}
+
+// datamoverDeleteSteps returns the steps to delete a data mover.  The task and its locations are marked and the
+// task is deleted first, so a failed delete never leaves a mover that can still run without its roles.  The roles,
+// log group and locations are deleted after the task, the locations last so a later delete can find them (and
+// the task report role recorded on them).
+func (o *datasyncOrchestrator) datamoverDeleteSteps(group, name string, mover *DatamoverResponse) []*deleteStep {
+	taskArn := aws.StringValue(mover.Task.TaskArn)
+	srcArn, dstArn := aws.StringValue(mover.Task.SourceLocationArn), aws.StringValue(mover.Task.DestinationLocationArn)
+	marked := []string{taskArn, srcArn, dstArn}
+
+	var reportRoleArn *string
+	if c := mover.Task.TaskReportConfig; c != nil && c.Destination != nil && c.Destination.S3 != nil && c.Destination.S3.BucketAccessRoleArn != nil {
+		reportRoleArn = c.Destination.S3.BucketAccessRoleArn
 	}
 
-	// delete source and destination locations
-	if err := o.deleteDatasyncLocation(ctx, name, aws.StringValue(mover.Task.SourceLocationArn), mover.Source.Type); err != nil {
-		return err
+	mark := Tags{{Key: deletingTagKey, Value: name}}
+	if reportRoleArn != nil {
+		mark = append(mark, Tag{Key: deletingReportRoleTagKey, Value: aws.StringValue(reportRoleArn)})
 	}
 
-	if err := o.deleteDatasyncLocation(ctx, name, aws.StringValue(mover.Task.DestinationLocationArn), mover.Destination.Type); err != nil {
-		return err
+	steps := []*deleteStep{
+		{
+			msg: "marking data mover for deletion",
+			run: func(ctx context.Context) error {
+				for _, r := range marked {
+					if err := o.updateDatasyncTags(ctx, r, mark, nil); err != nil {
+						return err
+					}
+				}
+				return nil
+			},
+			rollback: func(ctx context.Context) error {
+				log.Errorf("rollback: removing deletion mark from data mover %s", name)
+
+				for _, r := range marked {
+					if err := o.updateDatasyncTags(ctx, r, nil, []string{deletingTagKey, deletingReportRoleTagKey}); err != nil {
+						log.Warnf("rollback: error removing deletion mark from %s: %s", r, err)
+						return err
+					}
+				}
+				return nil
+			},
+		},
+		{
+			resource: "datasync task " + taskArn,
+			msg:      fmt.Sprintf("requested deletion of datasync task %s", name),
+			run: func(ctx context.Context) error {
+				_, err := o.datasyncClient.DeleteDatasyncTask(ctx, &datasync.DeleteTaskInput{
+					TaskArn: mover.Task.TaskArn,
+				})
+				return err
+			},
+			// once the task is gone, the locations stay marked for a later delete to clean up
+			commit: true,
+		},
 	}
 
-	return nil
+	// clean up task report role
+	if reportRoleArn != nil {
+		steps = append(steps, o.deleteReportRoleStep(reportRoleArn))
+	}
+
+	steps = append(steps, o.deleteManifestRoleSteps(group, name)...)
+
+	if lgArn := aws.StringValue(mover.Task.CloudWatchLogGroupArn); lgArn != "" {
+		steps = append(steps, o.deleteLogGroupStep(lgArn))
+	}
+
+	var srcType, dstType LocationType
+	if mover.Source != nil {
+		srcType = mover.Source.Type
+	}
+	if mover.Destination != nil {
+		dstType = mover.Destination.Type
+	}
+
+	return append(steps,
+		o.deleteLocationStep(name, srcArn, srcType),
+		o.deleteLocationStep(name, dstArn, dstType),
+	)
+}
+
+// deleteReportRoleStep returns the step to delete the task report role of a data mover
+func (o *datasyncOrchestrator) deleteReportRoleStep(roleArn *string) *deleteStep {
+	return &deleteStep{
+		resource: "task report role " + aws.StringValue(roleArn),
+		msg:      "requested deletion of task report role",
+		run: func(ctx context.Context) error {
+			return o.deleteBucketAccessRole(ctx, roleArn)
+		},
+	}
+}
+
+// deleteManifestRoleSteps returns the steps to delete the manifest roles of a data mover
+func (o *datasyncOrchestrator) deleteManifestRoleSteps(group, name string) []*deleteStep {
+	steps := []*deleteStep{}
+	for _, role := range []string{manifestRoleName(group, name), manifestRunRoleName(group, name)} {
+		role := role
+		steps = append(steps, &deleteStep{
+			resource: "manifest role " + role,
+			msg:      "requested deletion of manifest role",
+			run: func(ctx context.Context) error {
+				return o.deleteManifestRole(ctx, role)
+			},
+		})
+	}
+
+	return steps
+}
+
+// deleteLogGroupStep returns the step to delete the log group of a data mover
+func (o *datasyncOrchestrator) deleteLogGroupStep(lgArn string) *deleteStep {
+	return &deleteStep{
+		resource: "log group " + lgArn,
+		msg:      "requested deletion of log group",
+		run: func(ctx context.Context) error {
+			return o.deleteLogGroup(ctx, lgArn)
+		},
+	}
+}
+
+// deleteLocationStep returns the step to delete a location and its bucket access role.  The role is deleted
+// first, so the location (still marked for deletion) is left behind for a later delete if that fails.
+func (o *datasyncOrchestrator) deleteLocationStep(name, lArn string, lType LocationType) *deleteStep {
+	return &deleteStep{
+		resource: "location " + lArn,
+		msg:      fmt.Sprintf("requested deletion of %s location %s", lType, lArn),
+		run: func(ctx context.Context) error {
+			return o.deleteDatasyncLocation(ctx, name, lArn, lType)
+		},
+	}
+}
+
+// moverLeftovers are the resources left behind by an earlier delete of a data mover
+type moverLeftovers struct {
+	// locations are the locations left behind, with their types
+	locations map[string]LocationType
+	// reportRoleArn is the task report role recorded on the locations, it may be left behind too
+	reportRoleArn string
+}
+
+// datamoverLeftovers returns the resources left behind by an earlier delete of a data mover
+func (o *datasyncOrchestrator) datamoverLeftovers(ctx context.Context, group, name string) (*moverLeftovers, error) {
+	filters := []*resourcegroupstaggingapi.TagFilter{
+		{
+			Key:   "spinup:org",
+			Value: []string{o.server.org},
+		},
+		{
+			Key:   "spinup:spaceid",
+			Value: []string{group},
+		},
+		{
+			Key:   deletingTagKey,
+			Value: []string{name},
+		},
+	}
+
+	out, err := o.rgClient.GetResourcesWithTags(ctx, []string{"datasync:location"}, filters)
+	if err != nil {
+		return nil, err
+	}
+
+	leftovers := &moverLeftovers{locations: map[string]LocationType{}}
+	if len(out) == 0 {
+		return leftovers, nil
+	}
+
+	locations, err := o.datasyncClient.ListDatasyncLocations(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, r := range out {
+		lArn := aws.StringValue(r.ResourceARN)
+
+		scheme, ok := locations[lArn]
+		if !ok {
+			log.Warnf("location %s left behind by data mover %s no longer exists", lArn, name)
+			continue
+		}
+
+		leftovers.locations[lArn] = locationTypeFromScheme(scheme)
+
+		for _, t := range r.Tags {
+			if aws.StringValue(t.Key) == deletingReportRoleTagKey {
+				leftovers.reportRoleArn = aws.StringValue(t.Value)
+			}
+		}
+	}
+
+	return leftovers, nil
 }
 
 // datamoverDescribe gets details about a specific data mover (task and locations)
@@ -159,15 +1004,17 @@ func (o *datasyncOrchestrator) datamoverDescribe(ctx context.Context, group, nam
 		return nil, err
 	}
 
-	srcLocationType, ok := locations[*task.SourceLocationArn]
+	srcLocationScheme, ok := locations[*task.SourceLocationArn]
 	if !ok {
 		log.Warn("unable to determine source location type")
 	}
+	srcLocationType := locationTypeFromScheme(srcLocationScheme)
 
-	dstLocationType, ok := locations[*task.DestinationLocationArn]
+	dstLocationScheme, ok := locations[*task.DestinationLocationArn]
 	if !ok {
 		log.Warn("unable to determine destination location type")
 	}
+	dstLocationType := locationTypeFromScheme(dstLocationScheme)
 
 	srcLocation, err := o.describeDatasyncLocation(ctx, srcLocationType, aws.StringValue(task.SourceLocationArn))
 	if err != nil {
@@ -183,12 +1030,47 @@ func (o *datasyncOrchestrator) datamoverDescribe(ctx context.Context, group, nam
 		Task:        task,
 		Source:      srcLocation,
 		Destination: dstLocation,
+		NextRun:     nextRun(task, time.Now().UTC()),
 		Tags:        tags,
 	}, nil
 }
 
-// datamoverList lists all data movers (tasks) in a group by querying the Resourcegroupstaggingapi
+// datamoverList lists all data movers (tasks) in a group, or in all groups when the group is empty
 func (o *datasyncOrchestrator) datamoverList(ctx context.Context, group string) ([]string, error) {
+	if group != "" {
+		log.Debugf("listing data movers in group %s", group)
+
+		entries, err := o.groupTasks(ctx, group)
+		if err != nil {
+			return nil, err
+		}
+
+		return taskNames(entries), nil
+	}
+
+	log.Debug("listing all data movers")
+
+	entries, err := o.allTasks(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return taskNames(entries), nil
+}
+
+// taskNames returns the names of the tasks in the index entries
+func taskNames(entries []*taskIndexEntry) []string {
+	names := make([]string, 0, len(entries))
+	for _, e := range entries {
+		names = append(names, e.Name)
+	}
+
+	return names
+}
+
+// moverTagFilters returns the tag filters to find data mover resources in a group, or in all
+// of the groups allowed in the account when the group is empty
+func (o *datasyncOrchestrator) moverTagFilters(group string) []*resourcegroupstaggingapi.TagFilter {
 	filters := []*resourcegroupstaggingapi.TagFilter{
 		{
 			Key:   "spinup:org",
@@ -199,88 +1081,94 @@ func (o *datasyncOrchestrator) datamoverList(ctx context.Context, group string)
 			Value: []string{"storage"},
 		},
 		{
-			Key:   "spinup:flavor",
-			Value: []string{"datamover"},
-		},
-	}
-
-	if group == "" {
-		log.Debug("listing all data movers")
-	} else {
-		log.Debugf("listing data movers in group %s", group)
-
-		filters = append(filters, &resourcegroupstaggingapi.TagFilter{
-			Key:   "spinup:spaceid",
-			Value: []string{group},
-		})
-	}
-
-	out, err := o.rgClient.GetResourcesWithTags(ctx, []string{"datasync"}, filters)
-	if err != nil {
-		return nil, err
+			Key:   "spinup:flavor",
+			Value: []string{"datamover"},
+		},
 	}
 
-	resources := make([]string, 0, len(out))
-	for _, r := range out {
-		a, err := arn.Parse(aws.StringValue(r.ResourceARN))
-		if err != nil {
-			return nil, apierror.New(apierror.ErrInternalError, "failed to parse ARN "+aws.StringValue(r.ResourceARN), err)
-		}
-
-		parts := strings.SplitN(a.Resource, "/", 2)
-		if len(parts) < 2 {
-			return nil, apierror.New(apierror.ErrInternalError, "failed to parse ARN "+aws.StringValue(r.ResourceARN), err)
-		}
-
-		// we get both tasks and locations back, and we only care about tasks
-		if parts[0] == "task" {
-			name, err := o.datamoverNameFromArn(ctx, aws.StringValue(r.ResourceARN))
-			if err != nil {
-				return nil, err
-			}
-			resources = append(resources, name)
-		}
+	if f := o.groupTagFilter(group); f != nil {
+		filters = append(filters, f)
 	}
 
-	return resources, nil
+	return filters
 }
 
 // describeDatasyncLocation returns information for the specific location type
-func (o *datasyncOrchestrator) describeDatasyncLocation(ctx context.Context, lType, lArn string) (*DatamoverLocationOutput, error) {
+func (o *datasyncOrchestrator) describeDatasyncLocation(ctx context.Context, lType LocationType, lArn string) (*DatamoverLocationOutput, error) {
 	if lType == "" || lArn == "" {
 		return nil, nil
 	}
 
 	log.Debugf("location %s is type %s", lArn, lType)
 
-	switch strings.ToUpper(lType) {
-	case S3.String():
+	switch lType {
+	case S3:
 		dstLocationS3, err := o.datasyncClient.DescribeDatasyncLocationS3(ctx, lArn)
 		if err != nil {
 			return nil, err
 		}
 		return &DatamoverLocationOutput{Type: S3, S3: dstLocationS3}, nil
-	case EFS.String():
+	case EFS:
 		dstLocationEfs, err := o.datasyncClient.DescribeDatasyncLocationEfs(ctx, lArn)
 		if err != nil {
 			return nil, err
 		}
 		return &DatamoverLocationOutput{Type: EFS, EFS: dstLocationEfs}, nil
-	case SMB.String():
+	case SMB:
 		dstLocationSmb, err := o.datasyncClient.DescribeDatasyncLocationSmb(ctx, lArn)
 		if err != nil {
 			return nil, err
 		}
 		return &DatamoverLocationOutput{Type: SMB, SMB: dstLocationSmb}, nil
-	case NFS.String():
+	case NFS:
 		dstLocationNfs, err := o.datasyncClient.DescribeDatasyncLocationNfs(ctx, lArn)
 		if err != nil {
 			return nil, err
 		}
 		return &DatamoverLocationOutput{Type: NFS, NFS: dstLocationNfs}, nil
+	case FSxWindows:
+		dstLocationFsxWindows, err := o.datasyncClient.DescribeDatasyncLocationFsxWindows(ctx, lArn)
+		if err != nil {
+			return nil, err
+		}
+		return &DatamoverLocationOutput{Type: FSxWindows, FSxWindows: dstLocationFsxWindows}, nil
+	case FSxLustre:
+		dstLocationFsxLustre, err := o.datasyncClient.DescribeDatasyncLocationFsxLustre(ctx, lArn)
+		if err != nil {
+			return nil, err
+		}
+		return &DatamoverLocationOutput{Type: FSxLustre, FSxLustre: dstLocationFsxLustre}, nil
+	case FSxOntap:
+		dstLocationFsxOntap, err := o.datasyncClient.DescribeDatasyncLocationFsxOntap(ctx, lArn)
+		if err != nil {
+			return nil, err
+		}
+		return &DatamoverLocationOutput{Type: FSxOntap, FSxOntap: dstLocationFsxOntap}, nil
+	case FSxOpenZfs:
+		dstLocationFsxOpenZfs, err := o.datasyncClient.DescribeDatasyncLocationFsxOpenZfs(ctx, lArn)
+		if err != nil {
+			return nil, err
+		}
+		return &DatamoverLocationOutput{Type: FSxOpenZfs, FSxOpenZfs: dstLocationFsxOpenZfs}, nil
+	case ObjectStorage:
+		dstLocationObjectStorage, err := o.datasyncClient.DescribeDatasyncLocationObjectStorage(ctx, lArn)
+		if err != nil {
+			return nil, err
+		}
+
+		// never return the access key to the client
+		dstLocationObjectStorage.AccessKey = nil
+
+		return &DatamoverLocationOutput{Type: ObjectStorage, ObjectStorage: dstLocationObjectStorage}, nil
+	case AzureBlob:
+		dstLocationAzureBlob, err := o.datasyncClient.DescribeDatasyncLocationAzureBlob(ctx, lArn)
+		if err != nil {
+			return nil, err
+		}
+		return &DatamoverLocationOutput{Type: AzureBlob, AzureBlob: dstLocationAzureBlob}, nil
 	default:
 		log.Warnf("type %s didn't match any supported location types", lType)
-		return nil, apierror.New(apierror.ErrInternalError, "unknown datasync location type "+lType, nil)
+		return nil, apierror.New(apierror.ErrInternalError, "unknown datasync location type "+lType.String(), nil)
 	}
 }
 
@@ -303,10 +1191,7 @@ func (o *datasyncOrchestrator) createDatasyncLocation(ctx context.Context, mover
 		// we need to generate that first, before creating the location
 
 		path := fmt.Sprintf("/spinup/%s/%s/", o.server.org, group)
-		// use an 8-char CRC32 hash based on the bucket name to limit the length of the role name
-		roleName := fmt.Sprintf("%s-%08x", mover, crc32.ChecksumIEEE([]byte(s3Arn.Resource)))
-
-		roleARN, err := o.bucketAccessRole(ctx, path, roleName, s3Arn.String(), tags)
+		roleARN, err := o.bucketAccessRole(ctx, path, bucketAccessRoleName(mover, s3Arn.Resource), s3Arn.String(), nil, tags)
 		if err != nil {
 			return "", err
 		}
@@ -364,6 +1249,234 @@ func (o *datasyncOrchestrator) createDatasyncLocation(ctx context.Context, mover
 
 		log.Infof("created location successfully: %s", aws.StringValue(l.LocationArn))
 
+		return aws.StringValue(l.LocationArn), nil
+	case SMB:
+		if input.SMB == nil {
+			return "", apierror.New(apierror.ErrBadRequest, "missing SMB location input", nil)
+		}
+
+		log.Info("creating SMB datasync location ...")
+
+		smbInput := &datasync.CreateLocationSmbInput{
+			AgentArns:      input.SMB.AgentArns,
+			Domain:         input.SMB.Domain,
+			Password:       input.SMB.Password,
+			ServerHostname: input.SMB.ServerHostname,
+			Subdirectory:   input.SMB.Subdirectory,
+			Tags:           tags.toDatasyncTags(),
+			User:           input.SMB.User,
+		}
+
+		if input.SMB.MountVersion != nil {
+			smbInput.MountOptions = &datasync.SmbMountOptions{Version: input.SMB.MountVersion}
+		}
+
+		l, err := o.datasyncClient.CreateDatasyncLocationSmb(ctx, smbInput)
+		if err != nil {
+			log.Debugf("got an error creating location: %s", err)
+			return "", err
+		}
+
+		log.Infof("created location successfully: %s", aws.StringValue(l.LocationArn))
+
+		return aws.StringValue(l.LocationArn), nil
+	case NFS:
+		if input.NFS == nil {
+			return "", apierror.New(apierror.ErrBadRequest, "missing NFS location input", nil)
+		}
+
+		log.Info("creating NFS datasync location ...")
+
+		nfsInput := &datasync.CreateLocationNfsInput{
+			OnPremConfig:   &datasync.OnPremConfig{AgentArns: input.NFS.AgentArns},
+			ServerHostname: input.NFS.ServerHostname,
+			Subdirectory:   input.NFS.Subdirectory,
+			Tags:           tags.toDatasyncTags(),
+		}
+
+		if input.NFS.MountVersion != nil {
+			nfsInput.MountOptions = &datasync.NfsMountOptions{Version: input.NFS.MountVersion}
+		}
+
+		l, err := o.datasyncClient.CreateDatasyncLocationNfs(ctx, nfsInput)
+		if err != nil {
+			log.Debugf("got an error creating location: %s", err)
+			return "", err
+		}
+
+		log.Infof("created location successfully: %s", aws.StringValue(l.LocationArn))
+
+		return aws.StringValue(l.LocationArn), nil
+	case FSxWindows:
+		if input.FSxWindows == nil {
+			return "", apierror.New(apierror.ErrBadRequest, "missing FSxWindows location input", nil)
+		}
+
+		log.Info("creating FSx for Windows datasync location ...")
+
+		l, err := o.datasyncClient.CreateDatasyncLocationFsxWindows(ctx, &datasync.CreateLocationFsxWindowsInput{
+			Domain:            input.FSxWindows.Domain,
+			FsxFilesystemArn:  input.FSxWindows.FsxFilesystemArn,
+			Password:          input.FSxWindows.Password,
+			SecurityGroupArns: input.FSxWindows.SecurityGroupArns,
+			Subdirectory:      input.FSxWindows.Subdirectory,
+			Tags:              tags.toDatasyncTags(),
+			User:              input.FSxWindows.User,
+		})
+		if err != nil {
+			log.Debugf("got an error creating location: %s", err)
+			return "", err
+		}
+
+		log.Infof("created location successfully: %s", aws.StringValue(l.LocationArn))
+
+		return aws.StringValue(l.LocationArn), nil
+	case FSxLustre:
+		if input.FSxLustre == nil {
+			return "", apierror.New(apierror.ErrBadRequest, "missing FSxLustre location input", nil)
+		}
+
+		log.Info("creating FSx for Lustre datasync location ...")
+
+		l, err := o.datasyncClient.CreateDatasyncLocationFsxLustre(ctx, &datasync.CreateLocationFsxLustreInput{
+			FsxFilesystemArn:  input.FSxLustre.FsxFilesystemArn,
+			SecurityGroupArns: input.FSxLustre.SecurityGroupArns,
+			Subdirectory:      input.FSxLustre.Subdirectory,
+			Tags:              tags.toDatasyncTags(),
+		})
+		if err != nil {
+			log.Debugf("got an error creating location: %s", err)
+			return "", err
+		}
+
+		log.Infof("created location successfully: %s", aws.StringValue(l.LocationArn))
+
+		return aws.StringValue(l.LocationArn), nil
+	case FSxOntap:
+		if input.FSxOntap == nil || input.FSxOntap.Protocol == nil {
+			return "", apierror.New(apierror.ErrBadRequest, "missing FSxOntap location input", nil)
+		}
+
+		log.Info("creating FSx for ONTAP datasync location ...")
+
+		protocol := &datasync.FsxProtocol{}
+		if p := input.FSxOntap.Protocol.NFS; p != nil {
+			protocol.NFS = &datasync.FsxProtocolNfs{}
+			if p.MountVersion != nil {
+				protocol.NFS.MountOptions = &datasync.NfsMountOptions{Version: p.MountVersion}
+			}
+		}
+
+		if p := input.FSxOntap.Protocol.SMB; p != nil {
+			protocol.SMB = &datasync.FsxProtocolSmb{
+				Domain:   p.Domain,
+				Password: p.Password,
+				User:     p.User,
+			}
+			if p.MountVersion != nil {
+				protocol.SMB.MountOptions = &datasync.SmbMountOptions{Version: p.MountVersion}
+			}
+		}
+
+		l, err := o.datasyncClient.CreateDatasyncLocationFsxOntap(ctx, &datasync.CreateLocationFsxOntapInput{
+			Protocol:                 protocol,
+			SecurityGroupArns:        input.FSxOntap.SecurityGroupArns,
+			StorageVirtualMachineArn: input.FSxOntap.StorageVirtualMachineArn,
+			Subdirectory:             input.FSxOntap.Subdirectory,
+			Tags:                     tags.toDatasyncTags(),
+		})
+		if err != nil {
+			log.Debugf("got an error creating location: %s", err)
+			return "", err
+		}
+
+		log.Infof("created location successfully: %s", aws.StringValue(l.LocationArn))
+
+		return aws.StringValue(l.LocationArn), nil
+	case FSxOpenZfs:
+		if input.FSxOpenZfs == nil {
+			return "", apierror.New(apierror.ErrBadRequest, "missing FSxOpenZfs location input", nil)
+		}
+
+		log.Info("creating FSx for OpenZFS datasync location ...")
+
+		// OpenZFS file systems are only accessible over NFS
+		protocol := &datasync.FsxProtocol{NFS: &datasync.FsxProtocolNfs{}}
+		if input.FSxOpenZfs.MountVersion != nil {
+			protocol.NFS.MountOptions = &datasync.NfsMountOptions{Version: input.FSxOpenZfs.MountVersion}
+		}
+
+		l, err := o.datasyncClient.CreateDatasyncLocationFsxOpenZfs(ctx, &datasync.CreateLocationFsxOpenZfsInput{
+			FsxFilesystemArn:  input.FSxOpenZfs.FsxFilesystemArn,
+			Protocol:          protocol,
+			SecurityGroupArns: input.FSxOpenZfs.SecurityGroupArns,
+			Subdirectory:      input.FSxOpenZfs.Subdirectory,
+			Tags:              tags.toDatasyncTags(),
+		})
+		if err != nil {
+			log.Debugf("got an error creating location: %s", err)
+			return "", err
+		}
+
+		log.Infof("created location successfully: %s", aws.StringValue(l.LocationArn))
+
+		return aws.StringValue(l.LocationArn), nil
+	case ObjectStorage:
+		if input.ObjectStorage == nil {
+			return "", apierror.New(apierror.ErrBadRequest, "missing ObjectStorage location input", nil)
+		}
+
+		log.Info("creating object storage datasync location ...")
+
+		objInput := &datasync.CreateLocationObjectStorageInput{
+			AccessKey:      input.ObjectStorage.AccessKey,
+			AgentArns:      input.ObjectStorage.AgentArns,
+			BucketName:     input.ObjectStorage.BucketName,
+			SecretKey:      input.ObjectStorage.SecretKey,
+			ServerHostname: input.ObjectStorage.ServerHostname,
+			ServerPort:     input.ObjectStorage.ServerPort,
+			ServerProtocol: input.ObjectStorage.ServerProtocol,
+			Subdirectory:   input.ObjectStorage.Subdirectory,
+			Tags:           tags.toDatasyncTags(),
+		}
+
+		if input.ObjectStorage.ServerCertificate != nil {
+			objInput.ServerCertificate = []byte(aws.StringValue(input.ObjectStorage.ServerCertificate))
+		}
+
+		l, err := o.datasyncClient.CreateDatasyncLocationObjectStorage(ctx, objInput)
+		if err != nil {
+			log.Debugf("got an error creating location: %s", err)
+			return "", err
+		}
+
+		log.Infof("created location successfully: %s", aws.StringValue(l.LocationArn))
+
+		return aws.StringValue(l.LocationArn), nil
+	case AzureBlob:
+		if input.AzureBlob == nil {
+			return "", apierror.New(apierror.ErrBadRequest, "missing AzureBlob location input", nil)
+		}
+
+		log.Info("creating Azure Blob datasync location ...")
+
+		l, err := o.datasyncClient.CreateDatasyncLocationAzureBlob(ctx, &datasync.CreateLocationAzureBlobInput{
+			AccessTier:         input.AzureBlob.AccessTier,
+			AgentArns:          input.AzureBlob.AgentArns,
+			AuthenticationType: aws.String(datasync.AzureBlobAuthenticationTypeSas),
+			BlobType:           input.AzureBlob.BlobType,
+			ContainerUrl:       input.AzureBlob.ContainerUrl,
+			SasConfiguration:   &datasync.AzureBlobSasConfiguration{Token: input.AzureBlob.SasToken},
+			Subdirectory:       input.AzureBlob.Subdirectory,
+			Tags:               tags.toDatasyncTags(),
+		})
+		if err != nil {
+			log.Debugf("got an error creating location: %s", err)
+			return "", err
+		}
+
+		log.Infof("created location successfully: %s", aws.StringValue(l.LocationArn))
+
 		return aws.StringValue(l.LocationArn), nil
 	default:
 		log.Warnf("type %s didn't match any supported location types", input.Type)
@@ -371,7 +1484,8 @@ func (o *datasyncOrchestrator) createDatasyncLocation(ctx context.Context, mover
 	}
 }
 
-// deleteDatasyncLocation deletes the specific location type and associated resources
+// deleteDatasyncLocation deletes the specific location type and associated resources.  The bucket access role is
+// deleted before the location, so the role can still be found from the location if deleting it fails.
 func (o *datasyncOrchestrator) deleteDatasyncLocation(ctx context.Context, mover, lArn string, lType LocationType) error {
 	if mover == "" || lType == "" || lArn == "" {
 		return apierror.New(apierror.ErrBadRequest, "invalid input", nil)
@@ -379,41 +1493,33 @@ func (o *datasyncOrchestrator) deleteDatasyncLocation(ctx context.Context, mover
 
 	log.Debugf("deleting data mover %s location type %s", mover, lType)
 
-	l, err := o.describeDatasyncLocation(ctx, lType.String(), lArn)
+	switch lType {
+	case S3, EFS, SMB, NFS, FSxWindows, FSxLustre, FSxOntap, FSxOpenZfs, ObjectStorage, AzureBlob:
+	default:
+		log.Warnf("type %s didn't match any supported location types", lType)
+		return apierror.New(apierror.ErrBadRequest, "invalid location type", nil)
+	}
+
+	l, err := o.describeDatasyncLocation(ctx, lType, lArn)
 	if err != nil {
 		return err
 	}
 
-	switch lType {
-	case S3:
-		if _, err := o.datasyncClient.DeleteDatasyncLocation(ctx, &datasync.DeleteLocationInput{
-			LocationArn: aws.String(lArn),
-		}); err != nil {
-			log.Warnf("error deleting location %s: %s", lArn, err)
-			return err
-		}
-
-		// clean up bucket access role
-		if l.S3.S3Config != nil {
-			if err := o.deleteBucketAccessRole(ctx, l.S3.S3Config.BucketAccessRoleArn); err != nil {
-				return err
-			}
-		}
-
-		return nil
-	case EFS:
-		if _, err := o.datasyncClient.DeleteDatasyncLocation(ctx, &datasync.DeleteLocationInput{
-			LocationArn: aws.String(lArn),
-		}); err != nil {
-			log.Warnf("error deleting location %s: %s", lArn, err)
+	// clean up bucket access role, it may be gone already if it's shared by both locations or an earlier delete failed
+	if lType == S3 && l.S3 != nil && l.S3.S3Config != nil && l.S3.S3Config.BucketAccessRoleArn != nil {
+		if err := ignoreNotFound(o.deleteBucketAccessRole(ctx, l.S3.S3Config.BucketAccessRoleArn)); err != nil {
 			return err
 		}
+	}
 
-		return nil
-	default:
-		log.Warnf("type %s didn't match any supported location types", lType)
-		return apierror.New(apierror.ErrBadRequest, "invalid location type", nil)
+	if _, err := o.datasyncClient.DeleteDatasyncLocation(ctx, &datasync.DeleteLocationInput{
+		LocationArn: aws.String(lArn),
+	}); err != nil {
+		log.Warnf("error deleting location %s: %s", lArn, err)
+		return err
 	}
+
+	return nil
 }
 
 // datamoverNameFromArn determines the name of a datamover (datasync task) from its ARN
@@ -434,53 +1540,35 @@ func (o *datasyncOrchestrator) datamoverNameFromArn(ctx context.Context, tArn st
 	return aws.StringValue(task.Name), nil
 }
 
-// taskDetailsFromName finds a datasync task based on its group/name and returns information about it
+// taskDetailsFromName finds a datasync task based on its group/name and returns information about it.  The
+// task ARN and tags come from the task index, so only the matching task is described.
 func (o *datasyncOrchestrator) taskDetailsFromName(ctx context.Context, group, name string) (*datasync.DescribeTaskOutput, Tags, error) {
 	if group == "" || name == "" {
 		return nil, nil, apierror.New(apierror.ErrBadRequest, "invalid input", nil)
 	}
 
-	filters := []*resourcegroupstaggingapi.TagFilter{
-		{
-			Key:   "spinup:org",
-			Value: []string{o.server.org},
-		},
-		{
-			Key:   "spinup:type",
-			Value: []string{"storage"},
-		},
-		{
-			Key:   "spinup:flavor",
-			Value: []string{"datamover"},
-		},
-		{
-			Key:   "spinup:spaceid",
-			Value: []string{group},
-		},
+	entry, err := o.taskEntryFromName(ctx, group, name)
+	if err != nil {
+		return nil, nil, err
 	}
 
-	// get a list of all datasync resources in the group
-	out, err := o.rgClient.GetResourcesWithTags(ctx, []string{"datasync:task"}, filters)
+	task, err := o.datasyncClient.DescribeDatasyncTask(ctx, entry.Arn)
 	if err != nil {
+		// the task may have been deleted since it was indexed
+		if aerr, ok := err.(apierror.Error); ok && aerr.Code == apierror.ErrNotFound {
+			o.server.invalidateTaskIndex(o.account, o.region, group)
+		}
+
 		return nil, nil, err
 	}
 
-	if len(out) == 0 {
+	// the task may have been renamed since it was indexed
+	if aws.StringValue(task.Name) != name {
+		o.server.invalidateTaskIndex(o.account, o.region, group)
 		return nil, nil, apierror.New(apierror.ErrNotFound, "datasync mover not found", nil)
 	}
 
-	for _, r := range out {
-		task, err := o.datasyncClient.DescribeDatasyncTask(ctx, aws.StringValue(r.ResourceARN))
-		if err != nil {
-			return nil, nil, err
-		}
-
-		if aws.StringValue(task.Name) == name {
-			return task, fromResourcegroupstaggingapiTags(r.Tags), nil
-		}
-	}
-
-	return nil, nil, apierror.New(apierror.ErrNotFound, "datasync mover not found", nil)
+	return task, entry.Tags, nil
 }
 
 // datamoverRunList returns a list of executions for a given task
@@ -517,11 +1605,16 @@ func (o *datasyncOrchestrator) datamoverRunDescribe(ctx context.Context, group,
 	}
 	execArn := fmt.Sprintf("%s/execution/%s", aws.StringValue(task.TaskArn), id)
 
-	exec, err := o.datasyncClient.DescribeTaskExecution(ctx, execArn)
+	exec, err := o.describeRun(ctx, execArn)
 	if err != nil {
 		return nil, err
 	}
 
+	return datamoverRunFromExecution(exec), nil
+}
+
+// datamoverRunFromExecution converts a DataSync task execution to a data mover run
+func datamoverRunFromExecution(exec *datasync.DescribeTaskExecutionOutput) *DatamoverRun {
 	return &DatamoverRun{
 		BytesTransferred:         exec.BytesTransferred,
 		BytesWritten:             exec.BytesWritten,
@@ -531,26 +1624,105 @@ func (o *datasyncOrchestrator) datamoverRunDescribe(ctx context.Context, group,
 		StartTime:                exec.StartTime,
 		Status:                   exec.Status,
 		Result:                   exec.Result,
-	}, nil
+		BytesPerSecond:           runBandwidth(exec),
+	}
+}
+
+// runBandwidth returns the bandwidth limit of a task execution, -1 is unlimited
+func runBandwidth(exec *datasync.DescribeTaskExecutionOutput) *int64 {
+	if exec.Options == nil || exec.Options.BytesPerSecond == nil {
+		return aws.Int64(-1)
+	}
+
+	return exec.Options.BytesPerSecond
 }
 
-// startTaskRun starts the execution for a given task
-func (o *datasyncOrchestrator) startTaskRun(ctx context.Context, group, name string) (string, error) {
+// datamoverRunUpdate changes the bandwidth limit of a running task execution without stopping it
+func (o *datasyncOrchestrator) datamoverRunUpdate(ctx context.Context, group, name, id string, req *DatamoverRunUpdateRequest) (*DatamoverRun, error) {
 	task, _, err := o.taskDetailsFromName(ctx, group, name)
+	if err != nil {
+		return nil, err
+	}
+
+	if id == "" {
+		return nil, apierror.New(apierror.ErrBadRequest, "invalid input, id is missing", nil)
+	}
+	execArn := fmt.Sprintf("%s/execution/%s", aws.StringValue(task.TaskArn), id)
+
+	exec, err := o.datasyncClient.DescribeTaskExecution(ctx, execArn)
+	if err != nil {
+		return nil, err
+	}
+
+	switch aws.StringValue(exec.Status) {
+	case datasync.TaskExecutionStatusSuccess, datasync.TaskExecutionStatusError:
+		return nil, apierror.New(apierror.ErrConflict, "datasync mover run is not running", nil)
+	}
+
+	log.Infof("updating bandwidth of data mover %s run %s to %d", name, id, aws.Int64Value(req.BytesPerSecond))
+
+	if err := o.datasyncClient.UpdateTaskExecution(ctx, &datasync.UpdateTaskExecutionInput{
+		TaskExecutionArn: aws.String(execArn),
+		Options:          &datasync.Options{BytesPerSecond: req.BytesPerSecond},
+	}); err != nil {
+		return nil, err
+	}
+
+	return o.datamoverRunDescribe(ctx, group, name, id)
+}
+
+// startTaskRun starts the execution for a given task, optionally overriding the filters and manifest for this run
+func (o *datasyncOrchestrator) startTaskRun(ctx context.Context, group, name string, overrides *DatamoverRunOverrides) (string, error) {
+	task, tags, err := o.taskDetailsFromName(ctx, group, name)
 	if err != nil {
 		return "", err
 	}
 
 	if aws.StringValue(task.Status) == "AVAILABLE" {
-		out, err := o.datasyncClient.StartTaskExecution(ctx, aws.StringValue(task.TaskArn))
-		if err != nil {
-			return "", err
+		// watch the mover before starting, so the new run is followed instead of being counted as an earlier one
+		if err := o.server.notifier.watch(ctx, o, group, name, aws.StringValue(task.TaskArn), webhookUrls(tags)); err != nil {
+			log.Warnf("failed to watch data mover %s for run notifications: %s", name, err)
+		}
+
+		input := &datasync.StartTaskExecutionInput{TaskArn: task.TaskArn}
+		if overrides != nil {
+			input.Excludes = filterRules(overrides.Excludes)
+			input.Includes = filterRules(overrides.Includes)
+		}
+
+		var out *datasync.StartTaskExecutionOutput
+		if overrides != nil && overrides.Manifest != nil {
+			if err := o.bucketInGroup(ctx, group, aws.StringValue(overrides.Manifest.S3BucketArn)); err != nil {
+				return "", err
+			}
+
+			// the run reads its manifest with its own role, so the mover's manifest role is left alone
+			input.ManifestConfig, err = o.manifestConfig(ctx, group, manifestRunRoleName(group, name), overrides.Manifest, tags)
+			if err != nil {
+				return "", err
+			}
+
+			// the manifest role may have just been created, so retry briefly while DataSync can't assume it
+			if err := retryRolePropagation(func() error {
+				var err error
+				out, err = o.datasyncClient.StartTaskExecution(ctx, input)
+				return err
+			}); err != nil {
+				return "", err
+			}
+		} else {
+			out, err = o.datasyncClient.StartTaskExecution(ctx, input)
+			if err != nil {
+				return "", err
+			}
 		}
 
 		if out.TaskExecutionArn == nil {
 			return "", apierror.New(apierror.ErrInternalError, "unable to get task execution id", nil)
 		}
 
+		o.server.notifier.follow(aws.StringValue(task.TaskArn), aws.StringValue(out.TaskExecutionArn))
+
 		parts := strings.Split(*out.TaskExecutionArn, "/")
 		id := parts[len(parts)-1]
 
@@ -6,12 +6,18 @@ import (
 	"time"
 
 	"github.com/YaleSpinup/apierror"
+	yiam "github.com/YaleSpinup/aws-go/services/iam"
 	yresourcegroupstaggingapi "github.com/YaleSpinup/aws-go/services/resourcegroupstaggingapi"
+	ycloudwatchlogs "github.com/YaleSpinup/datasync-api/cloudwatchlogs"
 	ydatasync "github.com/YaleSpinup/datasync-api/datasync"
+	ys3 "github.com/YaleSpinup/datasync-api/s3"
 	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
 	"github.com/aws/aws-sdk-go/aws/request"
 	"github.com/aws/aws-sdk-go/service/datasync"
 	"github.com/aws/aws-sdk-go/service/datasync/datasynciface"
+	"github.com/aws/aws-sdk-go/service/iam"
+	"github.com/aws/aws-sdk-go/service/iam/iamiface"
 	"github.com/aws/aws-sdk-go/service/resourcegroupstaggingapi"
 	"github.com/aws/aws-sdk-go/service/resourcegroupstaggingapi/resourcegroupstaggingapiiface"
 	"github.com/stretchr/testify/assert"
@@ -21,6 +27,11 @@ type mockDataSync struct {
 	datasynciface.DataSyncAPI
 	t   *testing.T
 	err error
+
+	// startInput is the last input passed to StartTaskExecutionWithContext
+	startInput *datasync.StartTaskExecutionInput
+	// updateExecInput is the last input passed to UpdateTaskExecutionWithContext
+	updateExecInput *datasync.UpdateTaskExecutionInput
 }
 
 type mockRGClient struct {
@@ -34,6 +45,7 @@ func (d *mockDataSync) StartTaskExecutionWithContext(ctx context.Context, input
 	if d.err != nil {
 		return nil, d.err
 	}
+	d.startInput = input
 	out := &datasync.StartTaskExecutionOutput{
 		TaskExecutionArn: aws.String("arn:aws:datasync:us-east-1:012345678901:task/task-05cd6f77d7b5d15ac/execution/exec-086d6c629a6bf3581"),
 	}
@@ -51,6 +63,65 @@ func (d *mockDataSync) CancelTaskExecutionWithContext(ctx context.Context, input
 	return out, nil
 }
 
+func (d *mockDataSync) UpdateTaskExecutionWithContext(ctx context.Context, input *datasync.UpdateTaskExecutionInput, opts ...request.Option) (*datasync.UpdateTaskExecutionOutput, error) {
+	if d.err != nil {
+		return nil, d.err
+	}
+	d.updateExecInput = input
+
+	return &datasync.UpdateTaskExecutionOutput{}, nil
+}
+
+func (d *mockDataSync) CreateLocationSmbWithContext(ctx context.Context, input *datasync.CreateLocationSmbInput, opts ...request.Option) (*datasync.CreateLocationSmbOutput, error) {
+	if d.err != nil {
+		return nil, d.err
+	}
+
+	return &datasync.CreateLocationSmbOutput{
+		LocationArn: aws.String("arn:aws:datasync:us-east-1:012345678901:location/loc-0126cee0d76502bb1"),
+	}, nil
+}
+
+func (d *mockDataSync) CreateLocationNfsWithContext(ctx context.Context, input *datasync.CreateLocationNfsInput, opts ...request.Option) (*datasync.CreateLocationNfsOutput, error) {
+	if d.err != nil {
+		return nil, d.err
+	}
+
+	return &datasync.CreateLocationNfsOutput{
+		LocationArn: aws.String("arn:aws:datasync:us-east-1:012345678901:location/loc-0a2b3c4d5e6f70819"),
+	}, nil
+}
+
+func (d *mockDataSync) DescribeLocationObjectStorageWithContext(ctx context.Context, input *datasync.DescribeLocationObjectStorageInput, opts ...request.Option) (*datasync.DescribeLocationObjectStorageOutput, error) {
+	if d.err != nil {
+		return nil, d.err
+	}
+
+	return &datasync.DescribeLocationObjectStorageOutput{
+		AccessKey:   aws.String("AKIAEXAMPLE"),
+		LocationArn: input.LocationArn,
+		LocationUri: aws.String("object-storage://objects.example.com/bucket/"),
+	}, nil
+}
+
+func (d *mockDataSync) ListLocationsPagesWithContext(ctx context.Context, input *datasync.ListLocationsInput, fn func(*datasync.ListLocationsOutput, bool) bool, opts ...request.Option) error {
+	if d.err != nil {
+		return d.err
+	}
+
+	// the mock resource groups client returns this ARN for every query
+	fn(&datasync.ListLocationsOutput{
+		Locations: []*datasync.LocationListEntry{
+			{
+				LocationArn: aws.String("arn:aws:datasync:us-east-1:012345678901:task/task-05cd6f77d7b5d15ac/execution/exec-086d6c629a6bf3581"),
+				LocationUri: aws.String("s3://bucket/"),
+			},
+		},
+	}, true)
+
+	return nil
+}
+
 var is_running = false
 
 func (d *mockDataSync) ListTaskExecutionsPagesWithContext(ctx context.Context, input *datasync.ListTaskExecutionsInput, callback func(*datasync.ListTaskExecutionsOutput, bool) bool, opts ...request.Option) error {
@@ -140,14 +211,20 @@ func newMockRGClient(t *testing.T, err error) resourcegroupstaggingapiiface.Reso
 
 func newMockDataSyncOrchestrator(t *testing.T) *datasyncOrchestrator {
 	return &datasyncOrchestrator{
-		server: &server{},
+		server: &server{store: newMockStore()},
 		sp:     &sessionParams{},
 		datasyncClient: ydatasync.Datasync{
 			Service: newMockDataSync(t, nil),
 		},
+		logsClient: ycloudwatchlogs.CloudWatchLogs{
+			Service: newMockCloudWatchLogs(t, nil),
+		},
 		rgClient: yresourcegroupstaggingapi.ResourceGroupsTaggingAPI{
 			Service: newMockRGClient(t, nil),
 		},
+		s3Client: ys3.S3{
+			Service: newMockS3(t, nil),
+		},
 	}
 }
 
@@ -178,7 +255,7 @@ func TestStartTaskRun(t *testing.T) {
 	for _, test := range cases {
 		t.Log(test.message)
 		is_running = test.isRunning
-		resp, err := o.startTaskRun(test.ctx, test.group, test.name)
+		resp, err := o.startTaskRun(test.ctx, test.group, test.name, nil)
 		if test.isNegative {
 			if err == nil {
 				t.Error("expected error , got no error")
@@ -241,6 +318,7 @@ func Test_datamoverRunDescribe(t *testing.T) {
 		Status:                   aws.String("RUNNING"),
 		Result:                   &datasync.TaskExecutionResultDetail{},
 		StartTime:                aws.Time(testTime),
+		BytesPerSecond:           aws.Int64(-1),
 	}
 
 	type input struct {
@@ -347,3 +425,509 @@ func Test_datamoverList(t *testing.T) {
 		})
 	}
 }
+
+func Test_createDatasyncLocation(t *testing.T) {
+	type output struct {
+		isErr bool
+		res   string
+	}
+	tests := []struct {
+		name  string
+		input *DatamoverLocationInput
+		exp   output
+	}{
+		{"nil input", nil, output{true, ""}},
+		{"invalid type", &DatamoverLocationInput{Type: "FOO"}, output{true, ""}},
+		{"missing SMB input", &DatamoverLocationInput{Type: SMB}, output{true, ""}},
+		{
+			"valid SMB",
+			&DatamoverLocationInput{
+				Type: SMB,
+				SMB: &DatamoverLocationSMBInput{
+					AgentArns:      []*string{aws.String("arn:aws:datasync:us-east-1:012345678901:agent/agent-0914d8e6e0674c8b7")},
+					MountVersion:   aws.String("SMB3"),
+					Password:       aws.String("secret"),
+					ServerHostname: aws.String("storage.example.com"),
+					Subdirectory:   aws.String("/home"),
+					User:           aws.String("tester"),
+				},
+			},
+			output{false, "arn:aws:datasync:us-east-1:012345678901:location/loc-0126cee0d76502bb1"},
+		},
+		{"missing NFS input", &DatamoverLocationInput{Type: NFS}, output{true, ""}},
+		{
+			"valid NFS",
+			&DatamoverLocationInput{
+				Type: NFS,
+				NFS: &DatamoverLocationNFSInput{
+					AgentArns:      []*string{aws.String("arn:aws:datasync:us-east-1:012345678901:agent/agent-0914d8e6e0674c8b7")},
+					MountVersion:   aws.String("NFS4_1"),
+					ServerHostname: aws.String("nfs.example.com"),
+					Subdirectory:   aws.String("/exports/data"),
+				},
+			},
+			output{false, "arn:aws:datasync:us-east-1:012345678901:location/loc-0a2b3c4d5e6f70819"},
+		},
+	}
+
+	o := newMockDataSyncOrchestrator(t)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := o.createDatasyncLocation(context.Background(), "mover1", "group1", tt.input, Tags{})
+			if tt.exp.isErr && err == nil {
+				t.Error("expected error but did not receive error")
+			} else if !tt.exp.isErr && err != nil {
+				t.Errorf("received unexpected error %v", err)
+			}
+			assert.Equal(t, tt.exp.res, got)
+		})
+	}
+}
+
+func Test_describeDatasyncLocation(t *testing.T) {
+	lArn := "arn:aws:datasync:us-east-1:012345678901:location/loc-0126cee0d76502bb1"
+
+	o := newMockDataSyncOrchestrator(t)
+
+	got, err := o.describeDatasyncLocation(context.Background(), ObjectStorage, lArn)
+	if err != nil {
+		t.Fatalf("received unexpected error %v", err)
+	}
+
+	assert.Equal(t, ObjectStorage, got.Type)
+	assert.Nil(t, got.ObjectStorage.AccessKey, "expected access key to be removed from the output")
+
+	if _, err := o.describeDatasyncLocation(context.Background(), "FOO", lArn); err == nil {
+		t.Error("expected error for unknown location type but did not receive error")
+	}
+}
+
+func Test_datasyncOptions(t *testing.T) {
+	defaults := &datasync.Options{
+		PreserveDeletedFiles: aws.String("PRESERVE"),
+		TransferMode:         aws.String("CHANGED"),
+		VerifyMode:           aws.String("ONLY_FILES_TRANSFERRED"),
+	}
+	assert.Equal(t, defaults, datasyncOptions(nil))
+
+	got := datasyncOptions(&DatamoverOptions{
+		OverwriteMode:        aws.String("NEVER"),
+		PreserveDeletedFiles: aws.String("REMOVE"),
+	})
+	assert.Equal(t, &datasync.Options{
+		OverwriteMode:        aws.String("NEVER"),
+		PreserveDeletedFiles: aws.String("REMOVE"),
+		TransferMode:         aws.String("CHANGED"),
+		VerifyMode:           aws.String("ONLY_FILES_TRANSFERRED"),
+	}, got)
+}
+
+func Test_startTaskRunOverrides(t *testing.T) {
+	is_running = false
+
+	o := newMockDataSyncOrchestrator(t)
+	if _, err := o.startTaskRun(context.Background(), "group1", "name1", &DatamoverRunOverrides{
+		Includes: []string{"/project1", "/project2"},
+		Excludes: []string{"*.tmp"},
+	}); err != nil {
+		t.Fatalf("received unexpected error %v", err)
+	}
+
+	input := o.datasyncClient.Service.(*mockDataSync).startInput
+	assert.Equal(t, filterRules([]string{"/project1", "/project2"}), input.Includes)
+	assert.Equal(t, filterRules([]string{"*.tmp"}), input.Excludes)
+}
+
+func Test_filterRules(t *testing.T) {
+	assert.Nil(t, filterRules(nil))
+	assert.Equal(t, []*datasync.FilterRule{
+		{FilterType: aws.String("SIMPLE_PATTERN"), Value: aws.String("/folder1|/folder2")},
+	}, filterRules([]string{"/folder1", "/folder2"}))
+}
+
+func Test_updateFilterRules(t *testing.T) {
+	assert.Nil(t, updateFilterRules(nil))
+	assert.Equal(t, []*datasync.FilterRule{}, updateFilterRules([]string{}))
+	assert.Equal(t, filterRules([]string{"/folder1"}), updateFilterRules([]string{"/folder1"}))
+}
+
+func Test_updateTaskInput(t *testing.T) {
+	o := newMockDataSyncOrchestrator(t)
+	task := &datasync.DescribeTaskOutput{
+		Name:    aws.String("name1"),
+		TaskArn: aws.String("arn:aws:datasync:us-east-1:012345678901:task/task-05cd6f77d7b5d15ac"),
+		Options: &datasync.Options{
+			Atime:                aws.String("BEST_EFFORT"),
+			Mtime:                aws.String("PRESERVE"),
+			PreserveDeletedFiles: aws.String("PRESERVE"),
+			TransferMode:         aws.String("CHANGED"),
+			VerifyMode:           aws.String("ONLY_FILES_TRANSFERRED"),
+		},
+	}
+
+	// only tags changed
+	input, err := o.updateTaskInput(context.TODO(), "group1", task, &DatamoverUpdateRequest{Tags: Tags{}})
+	assert.NoError(t, err)
+	assert.Nil(t, input)
+
+	// renaming to a new name and to an existing mover
+	if _, err := o.updateTaskInput(context.TODO(), "group1", task, &DatamoverUpdateRequest{Name: aws.String("name1-copy")}); err != nil {
+		t.Errorf("expected no error renaming to a new name, got %s", err)
+	}
+
+	other := &datasync.DescribeTaskOutput{Name: aws.String("name2"), TaskArn: task.TaskArn}
+	if _, err := o.updateTaskInput(context.TODO(), "group1", other, &DatamoverUpdateRequest{Name: aws.String("name1")}); err == nil {
+		t.Error("expected conflict error renaming to an existing mover, got nil")
+	}
+
+	// options are merged with the current task options and validated
+	input, err = o.updateTaskInput(context.TODO(), "group1", task, &DatamoverUpdateRequest{
+		Options:        &DatamoverOptions{OverwriteMode: aws.String("NEVER")},
+		BytesPerSecond: aws.Int64(1048576),
+		Schedule:       aws.String("rate(1 day)"),
+		Excludes:       []string{},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, &datasync.UpdateTaskInput{
+		TaskArn: task.TaskArn,
+		Name:    task.Name,
+		Options: &datasync.Options{
+			Atime:                aws.String("BEST_EFFORT"),
+			BytesPerSecond:       aws.Int64(1048576),
+			Mtime:                aws.String("PRESERVE"),
+			OverwriteMode:        aws.String("NEVER"),
+			PreserveDeletedFiles: aws.String("PRESERVE"),
+			TransferMode:         aws.String("CHANGED"),
+			VerifyMode:           aws.String("ONLY_FILES_TRANSFERRED"),
+		},
+		Schedule: &datasync.TaskSchedule{ScheduleExpression: aws.String("rate(1 day)")},
+		Excludes: []*datasync.FilterRule{},
+	}, input)
+
+	if _, err := o.updateTaskInput(context.TODO(), "group1", task, &DatamoverUpdateRequest{
+		Options: &DatamoverOptions{Mtime: aws.String("NONE")},
+	}); err == nil {
+		t.Error("expected error for Mtime NONE with the current Atime BEST_EFFORT, got nil")
+	}
+
+	if _, err := o.updateTaskInput(context.TODO(), "group1", task, &DatamoverUpdateRequest{
+		Options: &DatamoverOptions{TransferMode: aws.String("ALL"), PreserveDeletedFiles: aws.String("REMOVE")},
+	}); err == nil {
+		t.Error("expected error for PreserveDeletedFiles REMOVE with TransferMode ALL, got nil")
+	}
+}
+
+func Test_revertTaskInput(t *testing.T) {
+	task := &datasync.DescribeTaskOutput{
+		Name:     aws.String("name1"),
+		TaskArn:  aws.String("arn:aws:datasync:us-east-1:012345678901:task/task-05cd6f77d7b5d15ac"),
+		Options:  &datasync.Options{TransferMode: aws.String("CHANGED")},
+		Includes: filterRules([]string{"/project1"}),
+	}
+
+	got := revertTaskInput(task, &datasync.UpdateTaskInput{
+		TaskArn:  task.TaskArn,
+		Name:     aws.String("name2"),
+		Schedule: &datasync.TaskSchedule{ScheduleExpression: aws.String("rate(1 day)")},
+		Includes: []*datasync.FilterRule{},
+		Excludes: filterRules([]string{"*.tmp"}),
+	})
+
+	assert.Equal(t, &datasync.UpdateTaskInput{
+		TaskArn:  task.TaskArn,
+		Name:     aws.String("name1"),
+		Schedule: &datasync.TaskSchedule{ScheduleExpression: aws.String("")},
+		Includes: filterRules([]string{"/project1"}),
+		Excludes: []*datasync.FilterRule{},
+	}, got)
+}
+
+func Test_runBandwidth(t *testing.T) {
+	assert.Equal(t, aws.Int64(-1), runBandwidth(&datasync.DescribeTaskExecutionOutput{}))
+	assert.Equal(t, aws.Int64(-1), runBandwidth(&datasync.DescribeTaskExecutionOutput{Options: &datasync.Options{}}))
+	assert.Equal(t, aws.Int64(1024), runBandwidth(&datasync.DescribeTaskExecutionOutput{Options: &datasync.Options{BytesPerSecond: aws.Int64(1024)}}))
+}
+
+func Test_datamoverRunUpdate(t *testing.T) {
+	o := newMockDataSyncOrchestrator(t)
+
+	if _, err := o.datamoverRunUpdate(context.TODO(), "group1", "name1", "", &DatamoverRunUpdateRequest{BytesPerSecond: aws.Int64(1048576)}); err == nil {
+		t.Error("expected error for empty id, got nil")
+	}
+
+	if _, err := o.datamoverRunUpdate(context.TODO(), "group1", "name1", "exec-086d6c629a6bf3585", &DatamoverRunUpdateRequest{BytesPerSecond: aws.Int64(1048576)}); err != nil {
+		t.Fatalf("expected nil error, got %s", err)
+	}
+
+	assert.Equal(t, &datasync.UpdateTaskExecutionInput{
+		TaskExecutionArn: aws.String("arn:aws:datasync:us-east-1:012345678901:task/task-05cd6f77d7b5d15ac/execution/exec-086d6c629a6bf3581/execution/exec-086d6c629a6bf3585"),
+		Options:          &datasync.Options{BytesPerSecond: aws.Int64(1048576)},
+	}, o.datasyncClient.Service.(*mockDataSync).updateExecInput)
+}
+
+func Test_datamoverCreateDuplicateName(t *testing.T) {
+	o := newMockDataSyncOrchestrator(t)
+
+	_, err := o.datamoverCreate(context.TODO(), "group1", &DatamoverCreateRequest{
+		Name:        aws.String("name1"),
+		Source:      &DatamoverLocationInput{Type: S3},
+		Destination: &DatamoverLocationInput{Type: S3},
+	})
+
+	aerr, ok := err.(apierror.Error)
+	if !ok || aerr.Code != apierror.ErrConflict {
+		t.Errorf("expected conflict error, got %v", err)
+	}
+
+	// the name is released when the create fails
+	if err := o.reserveMoverName(context.TODO(), "group1", "name1"); err != nil {
+		t.Errorf("expected nil error, got %s", err)
+	}
+}
+
+func Test_datamoverCreateReservedName(t *testing.T) {
+	o := newMockDataSyncOrchestrator(t)
+	o.account = "012345678901"
+
+	// a create of the name is in progress (on this or another instance of the API)
+	if err := o.reserveMoverName(context.TODO(), "group1", "name2"); err != nil {
+		t.Fatalf("expected nil error, got %s", err)
+	}
+
+	_, err := o.datamoverCreate(context.TODO(), "group1", &DatamoverCreateRequest{
+		Name:        aws.String("name2"),
+		Source:      &DatamoverLocationInput{Type: S3},
+		Destination: &DatamoverLocationInput{Type: S3},
+	})
+
+	aerr, ok := err.(apierror.Error)
+	if !ok || aerr.Code != apierror.ErrConflict {
+		t.Errorf("expected conflict error, got %v", err)
+	}
+
+	// names are reserved per group
+	if err := o.reserveMoverName(context.TODO(), "group2", "name2"); err != nil {
+		t.Errorf("expected nil error, got %s", err)
+	}
+
+	o.releaseMoverName(context.TODO(), "group1", "name2")
+	if err := o.reserveMoverName(context.TODO(), "group1", "name2"); err != nil {
+		t.Errorf("expected nil error after release, got %s", err)
+	}
+}
+
+func Test_datamoverDeleteSteps(t *testing.T) {
+	o := newMockDataSyncOrchestrator(t)
+
+	mover := &DatamoverResponse{
+		Task: &datasync.DescribeTaskOutput{
+			TaskArn:                aws.String("arn:aws:datasync:us-east-1:012345678901:task/task-05cd6f77d7b5d15ac"),
+			SourceLocationArn:      aws.String("arn:aws:datasync:us-east-1:012345678901:location/loc-src"),
+			DestinationLocationArn: aws.String("arn:aws:datasync:us-east-1:012345678901:location/loc-dst"),
+			CloudWatchLogGroupArn:  aws.String("arn:aws:logs:us-east-1:012345678901:log-group:/aws/datasync/spinup/org/group1/name1"),
+			TaskReportConfig: &datasync.TaskReportConfig{
+				Destination: &datasync.ReportDestination{
+					S3: &datasync.ReportDestinationS3{
+						BucketAccessRoleArn: aws.String("arn:aws:iam::012345678901:role/spinup/org/group1/name1-report-12345678"),
+					},
+				},
+			},
+		},
+		Source:      &DatamoverLocationOutput{Type: S3},
+		Destination: &DatamoverLocationOutput{Type: EFS},
+	}
+
+	steps := o.datamoverDeleteSteps("group1", "name1", mover)
+
+	resources := []string{}
+	for _, s := range steps {
+		resources = append(resources, s.resource)
+	}
+
+	assert.Equal(t, []string{
+		"",
+		"datasync task arn:aws:datasync:us-east-1:012345678901:task/task-05cd6f77d7b5d15ac",
+		"task report role arn:aws:iam::012345678901:role/spinup/org/group1/name1-report-12345678",
+		"manifest role " + manifestRoleName("group1", "name1"),
+		"manifest role " + manifestRunRoleName("group1", "name1"),
+		"log group arn:aws:logs:us-east-1:012345678901:log-group:/aws/datasync/spinup/org/group1/name1",
+		"location arn:aws:datasync:us-east-1:012345678901:location/loc-src",
+		"location arn:aws:datasync:us-east-1:012345678901:location/loc-dst",
+	}, resources)
+
+	// the mover is marked (with a rollback) and the task is deleted first, which commits the delete
+	if steps[0].rollback == nil || steps[0].commit {
+		t.Error("expected the mark step to have a rollback")
+	}
+
+	if !steps[1].commit {
+		t.Error("expected the task delete step to commit")
+	}
+
+	for _, s := range steps[2:] {
+		if s.rollback != nil || s.commit {
+			t.Errorf("expected %s to be deleted after the task without a rollback", s.resource)
+		}
+	}
+
+	// the report role is recorded on the marked locations, so a later delete can find it
+	wds := &mockWebhookDataSync{}
+	o.datasyncClient = ydatasync.Datasync{Service: wds}
+	if err := steps[0].run(context.TODO()); err != nil {
+		t.Fatalf("expected nil error, got %s", err)
+	}
+
+	mark := Tags{
+		{Key: deletingTagKey, Value: "name1"},
+		{Key: deletingReportRoleTagKey, Value: "arn:aws:iam::012345678901:role/spinup/org/group1/name1-report-12345678"},
+	}
+	assert.Equal(t, append(append(append(Tags{}, mark...), mark...), mark...), wds.tagged)
+
+	if err := steps[0].rollback(context.TODO()); err != nil {
+		t.Fatalf("expected nil error, got %s", err)
+	}
+	assert.Equal(t, []string{deletingTagKey, deletingReportRoleTagKey, deletingTagKey, deletingReportRoleTagKey, deletingTagKey, deletingReportRoleTagKey}, wds.untagged)
+	o.datasyncClient = ydatasync.Datasync{Service: newMockDataSync(t, nil)}
+
+	// without logging or reports, only the manifest roles, task and locations are deleted
+	steps = o.datamoverDeleteSteps("group1", "name1", &DatamoverResponse{
+		Task: &datasync.DescribeTaskOutput{
+			TaskArn:                aws.String("arn:aws:datasync:us-east-1:012345678901:task/task-05cd6f77d7b5d15ac"),
+			SourceLocationArn:      aws.String("arn:aws:datasync:us-east-1:012345678901:location/loc-src"),
+			DestinationLocationArn: aws.String("arn:aws:datasync:us-east-1:012345678901:location/loc-dst"),
+		},
+	})
+
+	if len(steps) != 6 {
+		t.Errorf("expected 6 steps, got %d", len(steps))
+	}
+}
+
+type mockDeleteLocationDataSync struct {
+	datasynciface.DataSyncAPI
+	// deleted counts the calls to DeleteLocationWithContext
+	deleted int
+}
+
+func (d *mockDeleteLocationDataSync) DescribeLocationS3WithContext(ctx context.Context, input *datasync.DescribeLocationS3Input, opts ...request.Option) (*datasync.DescribeLocationS3Output, error) {
+	return &datasync.DescribeLocationS3Output{
+		LocationArn: input.LocationArn,
+		LocationUri: aws.String("s3://bucket1/"),
+		S3Config: &datasync.S3Config{
+			BucketAccessRoleArn: aws.String("arn:aws:iam::012345678901:role/spinup/org/group1/name1-src-12345678"),
+		},
+	}, nil
+}
+
+func (d *mockDeleteLocationDataSync) DeleteLocationWithContext(ctx context.Context, input *datasync.DeleteLocationInput, opts ...request.Option) (*datasync.DeleteLocationOutput, error) {
+	d.deleted++
+	return &datasync.DeleteLocationOutput{}, nil
+}
+
+type mockDeleteRoleIAM struct {
+	iamiface.IAMAPI
+	err error
+	// deleted counts the calls to DeleteRoleWithContext
+	deleted int
+}
+
+func (m *mockDeleteRoleIAM) ListRolePoliciesWithContext(ctx context.Context, input *iam.ListRolePoliciesInput, opts ...request.Option) (*iam.ListRolePoliciesOutput, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+
+	return &iam.ListRolePoliciesOutput{PolicyNames: []*string{}}, nil
+}
+
+func (m *mockDeleteRoleIAM) DeleteRoleWithContext(ctx context.Context, input *iam.DeleteRoleInput, opts ...request.Option) (*iam.DeleteRoleOutput, error) {
+	m.deleted++
+	return &iam.DeleteRoleOutput{}, nil
+}
+
+func Test_deleteLocationStep(t *testing.T) {
+	lArn := "arn:aws:datasync:us-east-1:012345678901:location/loc-src"
+	ds := &mockDeleteLocationDataSync{}
+	mi := &mockDeleteRoleIAM{err: awserr.New(iam.ErrCodeServiceFailureException, "boom", nil)}
+
+	o := newMockDataSyncOrchestrator(t)
+	o.datasyncClient = ydatasync.Datasync{Service: ds}
+	o.iamClient = yiam.IAM{Service: mi}
+
+	step := o.deleteLocationStep("name1", lArn, S3)
+	assert.Equal(t, "location "+lArn, step.resource)
+
+	// the role can't be deleted, so the location is left behind to find it later
+	if err := step.run(context.TODO()); err == nil {
+		t.Fatal("expected error deleting the bucket access role")
+	}
+
+	assert.Equal(t, 0, ds.deleted)
+	assert.Equal(t, 0, mi.deleted)
+
+	// running the step again deletes the role, then the location
+	mi.err = nil
+	if err := step.run(context.TODO()); err != nil {
+		t.Fatalf("expected nil error, got %s", err)
+	}
+
+	assert.Equal(t, 1, ds.deleted)
+	assert.Equal(t, 1, mi.deleted)
+
+	// a role that's already gone doesn't stop the location from being deleted
+	mi.err = awserr.New(iam.ErrCodeNoSuchEntityException, "role not found", nil)
+	if err := step.run(context.TODO()); err != nil {
+		t.Fatalf("expected nil error, got %s", err)
+	}
+
+	assert.Equal(t, 2, ds.deleted)
+}
+
+// mockLeftoversRGClient returns a location marked for deletion with the tags
+type mockLeftoversRGClient struct {
+	resourcegroupstaggingapiiface.ResourceGroupsTaggingAPIAPI
+	tags []*resourcegroupstaggingapi.Tag
+}
+
+func (r *mockLeftoversRGClient) GetResourcesWithContext(ctx context.Context, input *resourcegroupstaggingapi.GetResourcesInput, opts ...request.Option) (*resourcegroupstaggingapi.GetResourcesOutput, error) {
+	return &resourcegroupstaggingapi.GetResourcesOutput{
+		PaginationToken: new(string),
+		ResourceTagMappingList: []*resourcegroupstaggingapi.ResourceTagMapping{
+			{
+				ResourceARN: aws.String("arn:aws:datasync:us-east-1:012345678901:task/task-05cd6f77d7b5d15ac/execution/exec-086d6c629a6bf3581"),
+				Tags:        r.tags,
+			},
+		},
+	}, nil
+}
+
+func Test_datamoverLeftovers(t *testing.T) {
+	o := newMockDataSyncOrchestrator(t)
+
+	got, err := o.datamoverLeftovers(context.TODO(), "group1", "name1")
+	if err != nil {
+		t.Fatalf("expected nil error, got %s", err)
+	}
+
+	assert.Equal(t, &moverLeftovers{
+		locations: map[string]LocationType{
+			"arn:aws:datasync:us-east-1:012345678901:task/task-05cd6f77d7b5d15ac/execution/exec-086d6c629a6bf3581": S3,
+		},
+	}, got)
+
+	// the task report role recorded on the location is left behind too
+	o.rgClient = yresourcegroupstaggingapi.ResourceGroupsTaggingAPI{Service: &mockLeftoversRGClient{
+		tags: []*resourcegroupstaggingapi.Tag{
+			{Key: aws.String(deletingTagKey), Value: aws.String("name1")},
+			{Key: aws.String(deletingReportRoleTagKey), Value: aws.String("arn:aws:iam::012345678901:role/spinup/org/group1/name1-report-12345678")},
+		},
+	}}
+
+	got, err = o.datamoverLeftovers(context.TODO(), "group1", "name1")
+	if err != nil {
+		t.Fatalf("expected nil error, got %s", err)
+	}
+
+	assert.Equal(t, "arn:aws:iam::012345678901:role/spinup/org/group1/name1-report-12345678", got.reportRoleArn)
+	assert.Len(t, got.locations, 1)
+}
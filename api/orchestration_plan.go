@@ -0,0 +1,181 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/YaleSpinup/apierror"
+	yiam "github.com/YaleSpinup/aws-go/services/iam"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/arn"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	planActionCreate = "create"
+	planActionUpdate = "update"
+	planActionReuse  = "reuse"
+)
+
+// datamoverPlan returns the resources that creating a data mover would create, update or reuse.  It
+// makes the same checks as datamoverCreate but only reads from AWS, so it's safe to call with read-only
+// credentials.
+func (o *datasyncOrchestrator) datamoverPlan(ctx context.Context, group string, req *DatamoverCreateRequest) (*DatamoverPlan, error) {
+	name := aws.StringValue(req.Name)
+
+	log.Infof("planning data mover %s with source %s and destination %s", name, req.Source.Type, req.Destination.Type)
+
+	if err := o.moverNameAvailable(ctx, group, name); err != nil {
+		return nil, err
+	}
+
+	if req.Manifest != nil {
+		if err := o.bucketInGroup(ctx, group, aws.StringValue(req.Manifest.S3BucketArn)); err != nil {
+			return nil, err
+		}
+	}
+
+	plan := &DatamoverPlan{
+		Name:      name,
+		Group:     group,
+		Resources: []*DatamoverPlanResource{},
+	}
+
+	path := fmt.Sprintf("/spinup/%s/%s/", o.server.org, group)
+
+	if req.Logging != nil {
+		lgName := o.logGroupName(group, name)
+
+		// creating the log group fails if it already exists, ie. left behind by a mover with the same name
+		if _, err := o.logsClient.DescribeLogGroup(ctx, lgName); err == nil {
+			return nil, apierror.New(apierror.ErrConflict, "log group "+lgName+" already exists", nil)
+		} else if aerr, ok := err.(apierror.Error); !ok || aerr.Code != apierror.ErrNotFound {
+			return nil, err
+		}
+
+		plan.Resources = append(plan.Resources,
+			&DatamoverPlanResource{
+				Type:        "LogGroup",
+				Action:      planActionCreate,
+				Name:        lgName,
+				Description: "CloudWatch log group for the mover",
+			},
+			&DatamoverPlanResource{
+				Type:        "LogResourcePolicy",
+				Action:      planActionUpdate,
+				Name:        o.logsResourcePolicyName(),
+				Description: "CloudWatch Logs resource policy allowing DataSync to write to the mover log groups",
+			},
+		)
+	}
+
+	if req.Report != nil {
+		bucketArn, err := arn.Parse(aws.StringValue(req.Report.S3BucketArn))
+		if err != nil {
+			return nil, apierror.New(apierror.ErrBadRequest, "failed to parse ARN "+aws.StringValue(req.Report.S3BucketArn), err)
+		}
+
+		res, err := o.bucketAccessRolePlan(ctx, path, reportRoleName(group, name, bucketArn.Resource), bucketArn.String(), reportScope(req.Report))
+		if err != nil {
+			return nil, err
+		}
+		res.Description = "role allowing DataSync to write task reports"
+
+		plan.Resources = append(plan.Resources, res)
+	}
+
+	if req.Manifest != nil {
+		res, err := o.bucketAccessRolePlan(ctx, path, manifestRoleName(group, name), aws.StringValue(req.Manifest.S3BucketArn), &bucketAccessScope{manifestObject: req.Manifest.ObjectPath})
+		if err != nil {
+			return nil, err
+		}
+		res.Description = "role allowing DataSync to read the manifest"
+
+		plan.Resources = append(plan.Resources, res)
+	}
+
+	for _, l := range []struct {
+		kind  string
+		input *DatamoverLocationInput
+	}{
+		{"source", req.Source},
+		{"destination", req.Destination},
+	} {
+		if l.input.Type == S3 {
+			bucketArn, err := arn.Parse(aws.StringValue(l.input.S3.S3BucketArn))
+			if err != nil {
+				return nil, apierror.New(apierror.ErrBadRequest, "failed to parse ARN "+aws.StringValue(l.input.S3.S3BucketArn), err)
+			}
+
+			res, err := o.bucketAccessRolePlan(ctx, path, bucketAccessRoleName(name, bucketArn.Resource), bucketArn.String(), nil)
+			if err != nil {
+				return nil, err
+			}
+			res.Description = fmt.Sprintf("role allowing DataSync to access the %s bucket", l.kind)
+
+			plan.Resources = append(plan.Resources, res)
+		}
+
+		plan.Resources = append(plan.Resources, &DatamoverPlanResource{
+			Type:        "Location",
+			Action:      planActionCreate,
+			Name:        string(l.input.Type),
+			Description: fmt.Sprintf("DataSync %s location", l.kind),
+		})
+	}
+
+	plan.Resources = append(plan.Resources, &DatamoverPlanResource{
+		Type:        "Task",
+		Action:      planActionCreate,
+		Name:        name,
+		Description: "DataSync task",
+	})
+
+	return plan, nil
+}
+
+// bucketAccessRolePlan returns what bucketAccessRole would do with a role: create it, update its inline
+// policy or reuse it as is
+func (o *datasyncOrchestrator) bucketAccessRolePlan(ctx context.Context, path, role, bucketArn string, scope *bucketAccessScope) (*DatamoverPlanResource, error) {
+	policy := bucketAccessPolicy(bucketArn, scope)
+
+	res := &DatamoverPlanResource{
+		Type:   "IAMRole",
+		Action: planActionCreate,
+		Name:   path + role,
+		Policy: &policy,
+	}
+
+	out, err := o.iamClient.GetRole(ctx, role)
+	if err != nil {
+		if aerr, ok := err.(apierror.Error); ok && aerr.Code == apierror.ErrNotFound {
+			return res, nil
+		}
+
+		return nil, err
+	}
+
+	res.Arn = out.Arn
+	res.Action = planActionUpdate
+
+	currentDoc, err := o.iamClient.GetRolePolicy(ctx, role, "DataSyncBucketAccessPolicy")
+	if err != nil {
+		if aerr, ok := err.(apierror.Error); ok && aerr.Code == apierror.ErrNotFound {
+			return res, nil
+		}
+
+		return nil, err
+	}
+
+	var currentPolicy yiam.PolicyDocument
+	if err := json.Unmarshal([]byte(currentDoc), &currentPolicy); err != nil {
+		return nil, apierror.New(apierror.ErrInternalError, "failed to parse inline policy for role "+role, err)
+	}
+
+	if yiam.PolicyDeepEqual(policy, currentPolicy) {
+		res.Action = planActionReuse
+	}
+
+	return res, nil
+}
@@ -0,0 +1,159 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/YaleSpinup/apierror"
+	yiam "github.com/YaleSpinup/aws-go/services/iam"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/iam"
+	"github.com/aws/aws-sdk-go/service/iam/iamiface"
+	"github.com/stretchr/testify/assert"
+)
+
+type mockIAM struct {
+	iamiface.IAMAPI
+	t *testing.T
+	// roles maps role names to their inline policy, roles without an inline policy have an empty policy
+	roles map[string]string
+}
+
+func (m *mockIAM) GetRoleWithContext(ctx context.Context, input *iam.GetRoleInput, opts ...request.Option) (*iam.GetRoleOutput, error) {
+	if _, ok := m.roles[aws.StringValue(input.RoleName)]; !ok {
+		return nil, awserr.New(iam.ErrCodeNoSuchEntityException, "role not found", nil)
+	}
+
+	return &iam.GetRoleOutput{
+		Role: &iam.Role{
+			Arn:      aws.String("arn:aws:iam::012345678901:role/spinup/org/group1/" + aws.StringValue(input.RoleName)),
+			RoleName: input.RoleName,
+		},
+	}, nil
+}
+
+func (m *mockIAM) GetRolePolicyWithContext(ctx context.Context, input *iam.GetRolePolicyInput, opts ...request.Option) (*iam.GetRolePolicyOutput, error) {
+	doc := m.roles[aws.StringValue(input.RoleName)]
+	if doc == "" {
+		return nil, awserr.New(iam.ErrCodeNoSuchEntityException, "policy not found", nil)
+	}
+
+	return &iam.GetRolePolicyOutput{
+		PolicyDocument: aws.String(doc),
+		PolicyName:     input.PolicyName,
+		RoleName:       input.RoleName,
+	}, nil
+}
+
+func (m *mockIAM) PutRolePolicyWithContext(ctx context.Context, input *iam.PutRolePolicyInput, opts ...request.Option) (*iam.PutRolePolicyOutput, error) {
+	m.roles[aws.StringValue(input.RoleName)] = aws.StringValue(input.PolicyDocument)
+	return &iam.PutRolePolicyOutput{}, nil
+}
+
+func (m *mockIAM) TagRoleWithContext(ctx context.Context, input *iam.TagRoleInput, opts ...request.Option) (*iam.TagRoleOutput, error) {
+	return &iam.TagRoleOutput{}, nil
+}
+
+func newMockIAM(t *testing.T, roles map[string]string) iamiface.IAMAPI {
+	return &mockIAM{t: t, roles: roles}
+}
+
+func Test_bucketAccessRolePlan(t *testing.T) {
+	current, err := json.Marshal(bucketAccessPolicy("arn:aws:s3:::bucket1", nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	o := newMockDataSyncOrchestrator(t)
+	o.iamClient = yiam.IAM{Service: newMockIAM(t, map[string]string{
+		"current":  string(current),
+		"outdated": `{"Version":"2012-10-17","Statement":[]}`,
+		"nopolicy": "",
+	})}
+
+	tests := []struct {
+		role   string
+		action string
+		arn    bool
+	}{
+		{"missing", planActionCreate, false},
+		{"current", planActionReuse, true},
+		{"outdated", planActionUpdate, true},
+		{"nopolicy", planActionUpdate, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.role, func(t *testing.T) {
+			got, err := o.bucketAccessRolePlan(context.TODO(), "/spinup/org/group1/", tt.role, "arn:aws:s3:::bucket1", nil)
+			if err != nil {
+				t.Fatalf("expected nil error, got %s", err)
+			}
+
+			assert.Equal(t, tt.action, got.Action)
+			assert.Equal(t, "/spinup/org/group1/"+tt.role, got.Name)
+			assert.Equal(t, tt.arn, got.Arn != nil)
+
+			want := bucketAccessPolicy("arn:aws:s3:::bucket1", nil)
+			assert.Equal(t, &want, got.Policy)
+		})
+	}
+}
+
+func Test_datamoverPlan(t *testing.T) {
+	o := newMockDataSyncOrchestrator(t)
+	o.server.org = "org"
+	o.iamClient = yiam.IAM{Service: newMockIAM(t, map[string]string{
+		bucketAccessRoleName("name2", "bucket1"): "",
+	})}
+
+	req := &DatamoverCreateRequest{
+		Name: aws.String("name2"),
+		Source: &DatamoverLocationInput{
+			Type: S3,
+			S3:   &DatamoverLocationS3Input{S3BucketArn: aws.String("arn:aws:s3:::bucket1")},
+		},
+		Destination: &DatamoverLocationInput{
+			Type: S3,
+			S3:   &DatamoverLocationS3Input{S3BucketArn: aws.String("arn:aws:s3:::bucket2")},
+		},
+	}
+
+	plan, err := o.datamoverPlan(context.TODO(), "group1", req)
+	if err != nil {
+		t.Fatalf("expected nil error, got %s", err)
+	}
+
+	type resource struct{ Type, Action, Name string }
+	got := []resource{}
+	for _, r := range plan.Resources {
+		got = append(got, resource{r.Type, r.Action, r.Name})
+	}
+
+	assert.Equal(t, []resource{
+		{"IAMRole", planActionUpdate, "/spinup/org/group1/" + bucketAccessRoleName("name2", "bucket1")},
+		{"Location", planActionCreate, "S3"},
+		{"IAMRole", planActionCreate, "/spinup/org/group1/" + bucketAccessRoleName("name2", "bucket2")},
+		{"Location", planActionCreate, "S3"},
+		{"Task", planActionCreate, "name2"},
+	}, got)
+
+	// the log group already exists in the mock
+	req.Logging = &DatamoverLoggingInput{}
+	if _, err := o.datamoverPlan(context.TODO(), "group1", req); err == nil {
+		t.Error("expected error for existing log group, got nil")
+	} else if aerr, ok := err.(apierror.Error); !ok || aerr.Code != apierror.ErrConflict {
+		t.Errorf("expected conflict error, got %s", err)
+	}
+
+	// the mover name already exists in the mock
+	req.Logging = nil
+	req.Name = aws.String("name1")
+	if _, err := o.datamoverPlan(context.TODO(), "group1", req); err == nil {
+		t.Error("expected error for existing mover, got nil")
+	} else if aerr, ok := err.(apierror.Error); !ok || aerr.Code != apierror.ErrConflict {
+		t.Errorf("expected conflict error, got %s", err)
+	}
+}
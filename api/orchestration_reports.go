@@ -0,0 +1,236 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"hash/crc32"
+	"strings"
+
+	"github.com/YaleSpinup/apierror"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/arn"
+	"github.com/aws/aws-sdk-go/service/datasync"
+	log "github.com/sirupsen/logrus"
+)
+
+// maxReportFailures is the maximum number of failed objects returned in a run report
+const maxReportFailures = 100
+
+// reportCategories are the lists of objects in the DataSync task report files
+var reportCategories = []string{"Transferred", "Skipped", "Verified", "Deleted"}
+
+// reportEntry is an object in a DataSync task report file
+type reportEntry struct {
+	RelativePath string
+	ErrorCode    string
+	ErrorDetail  string
+}
+
+// reportPrefix returns the normalized prefix of the task reports in the bucket, ie. "reports/"
+func reportPrefix(subdir *string) string {
+	p := strings.Trim(aws.StringValue(subdir), "/")
+	if p == "" {
+		return ""
+	}
+
+	return p + "/"
+}
+
+// createReportRole generates the role DataSync uses to write the task reports for a mover and returns the ARN
+func (o *datasyncOrchestrator) createReportRole(ctx context.Context, group, mover string, input *DatamoverReportInput, tags Tags) (string, error) {
+	if input == nil {
+		return "", apierror.New(apierror.ErrBadRequest, "invalid input", nil)
+	}
+
+	bucketArn, err := arn.Parse(aws.StringValue(input.S3BucketArn))
+	if err != nil {
+		return "", apierror.New(apierror.ErrBadRequest, "failed to parse ARN "+aws.StringValue(input.S3BucketArn), err)
+	}
+
+	path := fmt.Sprintf("/spinup/%s/%s/", o.server.org, group)
+	return o.bucketAccessRole(ctx, path, reportRoleName(group, mover, bucketArn.Resource), bucketArn.String(), reportScope(input), tags)
+}
+
+// reportRoleName returns the name of the role DataSync uses to write the task reports of a mover, an 8-char
+// CRC32 hash based on the group and bucket name limits the length of the role name.  The group is included,
+// so movers with the same name in different groups don't share a role.
+func reportRoleName(group, mover, bucket string) string {
+	return fmt.Sprintf("%s-report-%08x", mover, crc32.ChecksumIEEE([]byte(group+"/"+bucket)))
+}
+
+// reportScope limits a task report role to writing under the report prefix
+func reportScope(input *DatamoverReportInput) *bucketAccessScope {
+	return &bucketAccessScope{reportPrefix: aws.String(reportPrefix(input.Subdirectory))}
+}
+
+// taskReportConfig converts the mover report configuration to a DataSync task report configuration,
+// reports default to the STANDARD output type
+func taskReportConfig(input *DatamoverReportInput, roleArn string) *datasync.TaskReportConfig {
+	if input == nil {
+		return nil
+	}
+
+	outputType := input.OutputType
+	if outputType == nil {
+		outputType = aws.String(datasync.ReportOutputTypeStandard)
+	}
+
+	return &datasync.TaskReportConfig{
+		Destination: &datasync.ReportDestination{
+			S3: &datasync.ReportDestinationS3{
+				BucketAccessRoleArn: aws.String(roleArn),
+				S3BucketArn:         input.S3BucketArn,
+				Subdirectory:        input.Subdirectory,
+			},
+		},
+		ObjectVersionIds: input.ObjectVersionIds,
+		OutputType:       outputType,
+		ReportLevel:      input.ReportLevel,
+	}
+}
+
+// datamoverRunReport returns a summary of the task report for a data mover run
+func (o *datasyncOrchestrator) datamoverRunReport(ctx context.Context, group, name, id string) (*DatamoverRunReport, error) {
+	task, _, err := o.taskDetailsFromName(ctx, group, name)
+	if err != nil {
+		return nil, err
+	}
+
+	if id == "" {
+		return nil, apierror.New(apierror.ErrBadRequest, "invalid input, id is missing", nil)
+	}
+
+	exec, err := o.datasyncClient.DescribeTaskExecution(ctx, fmt.Sprintf("%s/execution/%s", aws.StringValue(task.TaskArn), id))
+	if err != nil {
+		return nil, err
+	}
+
+	// the execution has the report configuration it ran with, fall back to the task configuration
+	config := exec.TaskReportConfig
+	if config == nil {
+		config = task.TaskReportConfig
+	}
+
+	if config == nil || config.Destination == nil || config.Destination.S3 == nil {
+		return nil, apierror.New(apierror.ErrNotFound, "task reports are not enabled for datasync mover", nil)
+	}
+
+	bucketArn, err := arn.Parse(aws.StringValue(config.Destination.S3.S3BucketArn))
+	if err != nil {
+		return nil, apierror.New(apierror.ErrInternalError, "failed to parse ARN "+aws.StringValue(config.Destination.S3.S3BucketArn), err)
+	}
+
+	taskArn, err := arn.Parse(aws.StringValue(task.TaskArn))
+	if err != nil {
+		return nil, apierror.New(apierror.ErrInternalError, "failed to parse ARN "+aws.StringValue(task.TaskArn), err)
+	}
+
+	parts := strings.Split(taskArn.Resource, "/")
+	if len(parts) < 2 {
+		return nil, apierror.New(apierror.ErrInternalError, "failed to parse datasync task id "+aws.StringValue(task.TaskArn), nil)
+	}
+
+	report, err := o.readRunReport(ctx, bucketArn.Resource, reportPrefix(config.Destination.S3.Subdirectory), parts[1], id)
+	if err != nil {
+		return nil, err
+	}
+
+	if exec.ReportResult != nil {
+		report.Status = exec.ReportResult.Status
+	}
+
+	return report, nil
+}
+
+// readRunReport reads the task report files for a task execution from the report bucket.  Reports are
+// written to {prefix}Detailed-Reports/{task id}/{execution id}/ and {prefix}Summary-Reports/{task id}/{execution id}/,
+// the detailed reports are counted when they exist, otherwise the summary reports are counted.
+func (o *datasyncOrchestrator) readRunReport(ctx context.Context, bucket, prefix, taskID, execID string) (*DatamoverRunReport, error) {
+	report := &DatamoverRunReport{
+		Failures: []*DatamoverReportFailure{},
+		Files:    []string{},
+	}
+
+	var counted []string
+	for _, folder := range []string{"Detailed-Reports", "Summary-Reports"} {
+		keys, err := o.s3Client.ListObjects(ctx, bucket, fmt.Sprintf("%s%s/%s/%s/", prefix, folder, taskID, execID))
+		if err != nil {
+			return nil, err
+		}
+
+		report.Files = append(report.Files, keys...)
+		if counted == nil && len(keys) > 0 {
+			counted = keys
+		}
+	}
+
+	for _, key := range counted {
+		if !strings.HasSuffix(key, ".json") {
+			continue
+		}
+
+		body, err := o.s3Client.GetObject(ctx, bucket, key)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := parseReport(body, report); err != nil {
+			return nil, apierror.New(apierror.ErrInternalError, "failed to parse task report "+key, err)
+		}
+	}
+
+	return report, nil
+}
+
+// parseReport adds the objects in a task report file to the run report, objects with an error code are
+// counted as failed regardless of their category
+func parseReport(body []byte, report *DatamoverRunReport) error {
+	doc := map[string]json.RawMessage{}
+	if err := json.Unmarshal(body, &doc); err != nil {
+		return err
+	}
+
+	for _, category := range reportCategories {
+		raw, ok := doc[category]
+		if !ok {
+			continue
+		}
+
+		var entries []reportEntry
+		if err := json.Unmarshal(raw, &entries); err != nil {
+			return err
+		}
+
+		for _, e := range entries {
+			if e.ErrorCode != "" {
+				report.Failed++
+				if len(report.Failures) < maxReportFailures {
+					report.Failures = append(report.Failures, &DatamoverReportFailure{
+						Category:     category,
+						RelativePath: e.RelativePath,
+						ErrorCode:    e.ErrorCode,
+						ErrorDetail:  e.ErrorDetail,
+					})
+				}
+				continue
+			}
+
+			switch category {
+			case "Transferred":
+				report.Transferred++
+			case "Skipped":
+				report.Skipped++
+			case "Verified":
+				report.Verified++
+			case "Deleted":
+				report.Deleted++
+			}
+		}
+	}
+
+	log.Debugf("parsed task report: %d transferred, %d skipped, %d verified, %d deleted, %d failed",
+		report.Transferred, report.Skipped, report.Verified, report.Deleted, report.Failed)
+
+	return nil
+}
@@ -0,0 +1,249 @@
+package api
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/YaleSpinup/apierror"
+	yiam "github.com/YaleSpinup/aws-go/services/iam"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/datasync"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3iface"
+	"github.com/stretchr/testify/assert"
+)
+
+type mockS3 struct {
+	s3iface.S3API
+	t   *testing.T
+	err error
+
+	// objects are the keys and contents of the objects in the bucket
+	objects map[string]string
+}
+
+func newMockS3(t *testing.T, err error) s3iface.S3API {
+	return &mockS3{
+		t:       t,
+		err:     err,
+		objects: map[string]string{},
+	}
+}
+
+func (m *mockS3) ListObjectsV2PagesWithContext(ctx context.Context, input *s3.ListObjectsV2Input, fn func(*s3.ListObjectsV2Output, bool) bool, opts ...request.Option) error {
+	if m.err != nil {
+		return m.err
+	}
+
+	out := &s3.ListObjectsV2Output{}
+	for k := range m.objects {
+		if strings.HasPrefix(k, aws.StringValue(input.Prefix)) {
+			out.Contents = append(out.Contents, &s3.Object{Key: aws.String(k)})
+		}
+	}
+
+	fn(out, true)
+	return nil
+}
+
+func (m *mockS3) GetObjectWithContext(ctx context.Context, input *s3.GetObjectInput, opts ...request.Option) (*s3.GetObjectOutput, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+
+	body, ok := m.objects[aws.StringValue(input.Key)]
+	if !ok {
+		return nil, awserr.New(s3.ErrCodeNoSuchKey, "not found", nil)
+	}
+
+	return &s3.GetObjectOutput{Body: io.NopCloser(strings.NewReader(body))}, nil
+}
+
+func Test_reportRoleName(t *testing.T) {
+	a := reportRoleName("group1", "mover1", "reports")
+
+	if a == reportRoleName("group2", "mover1", "reports") {
+		t.Errorf("expected different role names for movers in different groups, got %s", a)
+	}
+
+	if a == reportRoleName("group1", "mover1", "other-reports") {
+		t.Errorf("expected different role names for different buckets, got %s", a)
+	}
+
+	if a != reportRoleName("group1", "mover1", "reports") {
+		t.Errorf("expected the same role name for the same mover")
+	}
+
+	if len(reportRoleName("group1", "abcdefghijklmnopqrstuvwxyz01234567890123", "reports")) > 64 {
+		t.Errorf("expected role name to be at most 64 characters")
+	}
+}
+
+func Test_reportPrefix(t *testing.T) {
+	tests := []struct {
+		subdir *string
+		want   string
+	}{
+		{nil, ""},
+		{aws.String(""), ""},
+		{aws.String("/"), ""},
+		{aws.String("reports"), "reports/"},
+		{aws.String("/datasync/reports/"), "datasync/reports/"},
+	}
+
+	for _, tt := range tests {
+		if got := reportPrefix(tt.subdir); got != tt.want {
+			t.Errorf("reportPrefix(%v) = %s, want %s", aws.StringValue(tt.subdir), got, tt.want)
+		}
+	}
+}
+
+func Test_taskReportConfig(t *testing.T) {
+	if got := taskReportConfig(nil, "arn:aws:iam::012345678901:role/foo"); got != nil {
+		t.Errorf("expected nil report config for nil input, got %+v", got)
+	}
+
+	got := taskReportConfig(&DatamoverReportInput{
+		S3BucketArn:  aws.String("arn:aws:s3:::reports"),
+		Subdirectory: aws.String("datasync"),
+		ReportLevel:  aws.String("ERRORS_ONLY"),
+	}, "arn:aws:iam::012345678901:role/spinup/org/group/mover-report-12345678")
+
+	assert.Equal(t, &datasync.TaskReportConfig{
+		Destination: &datasync.ReportDestination{
+			S3: &datasync.ReportDestinationS3{
+				BucketAccessRoleArn: aws.String("arn:aws:iam::012345678901:role/spinup/org/group/mover-report-12345678"),
+				S3BucketArn:         aws.String("arn:aws:s3:::reports"),
+				Subdirectory:        aws.String("datasync"),
+			},
+		},
+		OutputType:  aws.String("STANDARD"),
+		ReportLevel: aws.String("ERRORS_ONLY"),
+	}, got)
+}
+
+func Test_bucketAccessPolicyReports(t *testing.T) {
+	policy := bucketAccessPolicy("arn:aws:s3:::reports", &bucketAccessScope{reportPrefix: aws.String("datasync/")})
+
+	assert.Equal(t, []yiam.StatementEntry{
+		{
+			Sid:    "ListBucket",
+			Effect: "Allow",
+			Action: []string{
+				"s3:GetBucketLocation",
+				"s3:ListBucket",
+				"s3:ListBucketMultipartUploads",
+			},
+			Resource: []string{"arn:aws:s3:::reports"},
+		},
+		{
+			Sid:    "PutTaskReports",
+			Effect: "Allow",
+			Action: []string{
+				"s3:AbortMultipartUpload",
+				"s3:ListMultipartUploadParts",
+				"s3:PutObject",
+			},
+			Resource: []string{"arn:aws:s3:::reports/datasync/*"},
+		},
+	}, policy.Statement)
+
+	// without a report prefix, the role has full access to the bucket objects
+	policy = bucketAccessPolicy("arn:aws:s3:::data", nil)
+	if len(policy.Statement) != 2 || policy.Statement[1].Sid != "GetBucketObjects" {
+		t.Errorf("expected bucket access policy, got %+v", policy.Statement)
+	}
+}
+
+func Test_parseReport(t *testing.T) {
+	report := &DatamoverRunReport{Failures: []*DatamoverReportFailure{}}
+
+	transferred := `{
+		"TaskExecutionId": "exec-086d6c629a6bf3585",
+		"Transferred": [
+			{"RelativePath": "/a.txt", "TransferType": "CONTENT_AND_METADATA", "ErrorCode": null, "ErrorDetail": null},
+			{"RelativePath": "/b.txt", "TransferType": "CONTENT_AND_METADATA", "ErrorCode": "FileNotFound", "ErrorDetail": "file was removed"}
+		]
+	}`
+
+	verified := `{
+		"Verified": [
+			{"RelativePath": "/a.txt", "ErrorCode": null}
+		],
+		"Skipped": [
+			{"RelativePath": "/c.txt"},
+			{"RelativePath": "/d.txt"}
+		]
+	}`
+
+	for _, r := range []string{transferred, verified} {
+		if err := parseReport([]byte(r), report); err != nil {
+			t.Fatalf("expected nil error, got %s", err)
+		}
+	}
+
+	assert.Equal(t, &DatamoverRunReport{
+		Transferred: 1,
+		Skipped:     2,
+		Verified:    1,
+		Failed:      1,
+		Failures: []*DatamoverReportFailure{
+			{
+				Category:     "Transferred",
+				RelativePath: "/b.txt",
+				ErrorCode:    "FileNotFound",
+				ErrorDetail:  "file was removed",
+			},
+		},
+	}, report)
+
+	if err := parseReport([]byte(`not json`), report); err == nil {
+		t.Error("expected error for invalid report, got nil")
+	}
+}
+
+func Test_readRunReport(t *testing.T) {
+	o := newMockDataSyncOrchestrator(t)
+	o.s3Client.Service.(*mockS3).objects = map[string]string{
+		"reports/Detailed-Reports/task-1/exec-1/exec-1.files-transferred-v1-00001.json": `{"Transferred": [{"RelativePath": "/a.txt"}, {"RelativePath": "/b.txt"}]}`,
+		"reports/Detailed-Reports/task-1/exec-1/exec-1.files-verified-v1-00001.json":    `{"Verified": [{"RelativePath": "/a.txt"}, {"RelativePath": "/b.txt", "ErrorCode": "VerificationFailed"}]}`,
+		"reports/Summary-Reports/task-1/exec-1/exec-1.summary-v1.json":                  `{"Transferred": [{"RelativePath": "/a.txt"}]}`,
+		"reports/Detailed-Reports/task-1/exec-2/exec-2.files-transferred-v1-00001.json": `{"Transferred": [{"RelativePath": "/c.txt"}]}`,
+	}
+
+	got, err := o.readRunReport(context.TODO(), "reports-bucket", "reports/", "task-1", "exec-1")
+	if err != nil {
+		t.Fatalf("expected nil error, got %s", err)
+	}
+
+	// only the detailed reports are counted when they exist
+	assert.Equal(t, int64(2), got.Transferred)
+	assert.Equal(t, int64(1), got.Verified)
+	assert.Equal(t, int64(1), got.Failed)
+	assert.ElementsMatch(t, []string{
+		"reports/Detailed-Reports/task-1/exec-1/exec-1.files-transferred-v1-00001.json",
+		"reports/Detailed-Reports/task-1/exec-1/exec-1.files-verified-v1-00001.json",
+		"reports/Summary-Reports/task-1/exec-1/exec-1.summary-v1.json",
+	}, got.Files)
+
+	// no reports have been written yet
+	got, err = o.readRunReport(context.TODO(), "reports-bucket", "reports/", "task-1", "exec-3")
+	if err != nil {
+		t.Fatalf("expected nil error, got %s", err)
+	}
+
+	assert.Equal(t, &DatamoverRunReport{Failures: []*DatamoverReportFailure{}, Files: []string{}}, got)
+}
+
+func Test_datamoverRunReportNotEnabled(t *testing.T) {
+	o := newMockDataSyncOrchestrator(t)
+
+	_, err := o.datamoverRunReport(context.TODO(), "group1", "name1", "exec-086d6c629a6bf3585")
+	if aerr, ok := err.(apierror.Error); !ok || aerr.Code != apierror.ErrNotFound {
+		t.Errorf("expected not found error, got %v", err)
+	}
+}
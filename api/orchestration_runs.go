@@ -0,0 +1,233 @@
+package api
+
+import (
+	"context"
+	"encoding/base64"
+	"strings"
+	"time"
+
+	"github.com/YaleSpinup/apierror"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/datasync"
+	"github.com/patrickmn/go-cache"
+	log "github.com/sirupsen/logrus"
+)
+
+// finishedRunTTL is how long the descriptions of finished runs are cached, they don't change
+const finishedRunTTL = 24 * time.Hour
+
+// encodeRunCursor returns the continuation token of the page after a run
+func encodeRunCursor(id string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(id))
+}
+
+// decodeRunCursor returns the id of the last run of the previous page
+func decodeRunCursor(cursor string) (string, error) {
+	b, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return "", apierror.New(apierror.ErrBadRequest, "invalid continuation token", err)
+	}
+
+	if len(b) == 0 {
+		return "", apierror.New(apierror.ErrBadRequest, "invalid continuation token", nil)
+	}
+
+	return string(b), nil
+}
+
+// runID returns the id of a task execution from its ARN
+func runID(execArn string) string {
+	parts := strings.Split(execArn, "/")
+	return parts[len(parts)-1]
+}
+
+// describeRun describes a task execution, finished executions are cached since they don't change
+func (o *datasyncOrchestrator) describeRun(ctx context.Context, execArn string) (*datasync.DescribeTaskExecutionOutput, error) {
+	if o.server.finishedRuns != nil {
+		if v, ok := o.server.finishedRuns.Get(execArn); ok {
+			if exec, ok := v.(*datasync.DescribeTaskExecutionOutput); ok {
+				return exec, nil
+			}
+		}
+	}
+
+	exec, err := o.datasyncClient.DescribeTaskExecution(ctx, execArn)
+	if err != nil {
+		return nil, err
+	}
+
+	switch aws.StringValue(exec.Status) {
+	case datasync.TaskExecutionStatusSuccess, datasync.TaskExecutionStatusError:
+		if o.server.finishedRuns != nil {
+			o.server.finishedRuns.Set(execArn, exec, cache.DefaultExpiration)
+		}
+	}
+
+	return exec, nil
+}
+
+// datamoverRunListPage returns a page of the runs of a mover matching the filters, newest first, and the
+// continuation token of the next page.  Runs are paged in the order DataSync lists them and filtered by status
+// before they're described.  Only the runs of the page are described when they're expanded, runs filtered by
+// start time are described in batches until the page is full.
+func (o *datasyncOrchestrator) datamoverRunListPage(ctx context.Context, group, name string, input *DatamoverRunListInput) ([]*DatamoverRunSummary, string, error) {
+	task, _, err := o.taskDetailsFromName(ctx, group, name)
+	if err != nil {
+		return nil, "", err
+	}
+
+	var afterID string
+	if input.Next != "" {
+		if afterID, err = decodeRunCursor(input.Next); err != nil {
+			return nil, "", err
+		}
+	}
+
+	entries, err := o.datasyncClient.ListDatasyncTaskExecutionEntries(ctx, aws.StringValue(task.TaskArn))
+	if err != nil {
+		return nil, "", err
+	}
+
+	// executions are listed oldest first
+	ordered := make([]*datasync.TaskExecutionListEntry, 0, len(entries))
+	for i := len(entries) - 1; i >= 0; i-- {
+		ordered = append(ordered, entries[i])
+	}
+
+	// the page starts after the last run of the previous page, whatever its status is now
+	if afterID != "" {
+		found := false
+		for i, e := range ordered {
+			if runID(aws.StringValue(e.TaskExecutionArn)) == afterID {
+				ordered, found = ordered[i+1:], true
+				break
+			}
+		}
+
+		if !found {
+			return nil, "", apierror.New(apierror.ErrBadRequest, "invalid continuation token", nil)
+		}
+	}
+
+	candidates := []*datasync.TaskExecutionListEntry{}
+	for _, e := range ordered {
+		if input.Status == "" || aws.StringValue(e.Status) == input.Status {
+			candidates = append(candidates, e)
+		}
+	}
+
+	var runs []*DatamoverRunSummary
+	if input.Since == nil && input.Until == nil {
+		page := candidates
+		if input.Limit > 0 && len(page) > input.Limit {
+			page = page[:input.Limit]
+		}
+
+		if runs, err = o.runSummaries(ctx, page, input.Expand); err != nil {
+			return nil, "", err
+		}
+
+		next := ""
+		if len(page) < len(candidates) {
+			next = encodeRunCursor(runs[len(runs)-1].Id)
+		}
+
+		log.Debugf("listed %d of %d data mover runs, next token: %q", len(runs), len(entries), next)
+
+		return runs, next, nil
+	}
+
+	// runs that haven't started yet are in the page until they start
+	now := time.Now().UTC()
+
+	runs = []*DatamoverRunSummary{}
+	for len(candidates) > 0 && (input.Limit == 0 || len(runs) <= input.Limit) {
+		n := taskDescribeConcurrency
+		if n > len(candidates) {
+			n = len(candidates)
+		}
+
+		batch, err := o.runSummaries(ctx, candidates[:n], true)
+		if err != nil {
+			return nil, "", err
+		}
+		candidates = candidates[n:]
+
+		older := false
+		for _, run := range batch {
+			start := now
+			if run.StartTime != nil {
+				start = aws.TimeValue(run.StartTime)
+			}
+
+			if input.Since != nil && start.Before(*input.Since) {
+				older = true
+				continue
+			}
+
+			if input.Until != nil && start.After(*input.Until) {
+				continue
+			}
+
+			runs = append(runs, run)
+		}
+
+		// runs are started in the order they're listed, so the rest of the runs started before since
+		if older {
+			candidates = nil
+		}
+	}
+
+	next := ""
+	if input.Limit > 0 && len(runs) > input.Limit {
+		runs = runs[:input.Limit]
+		next = encodeRunCursor(runs[len(runs)-1].Id)
+	}
+
+	log.Debugf("listed %d of %d data mover runs, next token: %q", len(runs), len(entries), next)
+
+	return runs, next, nil
+}
+
+// runSummaries returns the summaries of task executions, in order.  The executions are described
+// concurrently when describe is true, otherwise the summaries only have the id and status.
+func (o *datasyncOrchestrator) runSummaries(ctx context.Context, entries []*datasync.TaskExecutionListEntry, describe bool) ([]*DatamoverRunSummary, error) {
+	runs := make([]*DatamoverRunSummary, len(entries))
+	if !describe {
+		for i, e := range entries {
+			runs[i] = &DatamoverRunSummary{
+				Id:     runID(aws.StringValue(e.TaskExecutionArn)),
+				Status: e.Status,
+			}
+		}
+
+		return runs, nil
+	}
+
+	if err := concurrently(len(entries), taskDescribeConcurrency, func(i int) error {
+		execArn := aws.StringValue(entries[i].TaskExecutionArn)
+
+		exec, err := o.describeRun(ctx, execArn)
+		if err != nil {
+			return err
+		}
+
+		runs[i] = &DatamoverRunSummary{
+			Id:               runID(execArn),
+			StartTime:        exec.StartTime,
+			Status:           exec.Status,
+			BytesTransferred: exec.BytesTransferred,
+			FilesTransferred: exec.FilesTransferred,
+		}
+
+		if exec.Result != nil {
+			runs[i].Duration = exec.Result.TotalDuration
+		}
+
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return runs, nil
+}
@@ -0,0 +1,142 @@
+package api
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/datasync"
+	"github.com/patrickmn/go-cache"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_runCursor(t *testing.T) {
+	id, err := decodeRunCursor(encodeRunCursor("exec-1"))
+	if err != nil {
+		t.Fatalf("expected nil error, got %s", err)
+	}
+	assert.Equal(t, "exec-1", id)
+
+	for _, c := range []string{"!!!", ""} {
+		if _, err := decodeRunCursor(c); err == nil {
+			t.Errorf("expected error for token %q, got nil", c)
+		}
+	}
+}
+
+func Test_datamoverRunListPage(t *testing.T) {
+	o, ds := newMockIndexOrchestrator(t, 1)
+	o.server.finishedRuns = cache.New(finishedRunTTL, time.Hour)
+
+	ids := func(runs []*DatamoverRunSummary) []string {
+		out := []string{}
+		for _, r := range runs {
+			out = append(out, r.Id)
+		}
+		return out
+	}
+
+	// runs aren't described unless they're expanded or filtered by start time
+	runs, next, err := o.datamoverRunListPage(context.TODO(), "group1", "mover0", &DatamoverRunListInput{})
+	if err != nil {
+		t.Fatalf("expected nil error, got %s", err)
+	}
+	assert.Equal(t, []string{"exec-3", "exec-2", "exec-1"}, ids(runs))
+	assert.Equal(t, "", next)
+	assert.Equal(t, 0, ds.execCalls)
+
+	// runs are sorted newest first
+	runs, next, err = o.datamoverRunListPage(context.TODO(), "group1", "mover0", &DatamoverRunListInput{Expand: true})
+	if err != nil {
+		t.Fatalf("expected nil error, got %s", err)
+	}
+	assert.Equal(t, []string{"exec-3", "exec-2", "exec-1"}, ids(runs))
+	assert.Equal(t, "", next)
+	assert.Equal(t, &DatamoverRunSummary{
+		Id:               "exec-2",
+		StartTime:        aws.Time(testTime.Add(2 * time.Hour)),
+		Status:           aws.String(datasync.TaskExecutionStatusSuccess),
+		BytesTransferred: aws.Int64(2048),
+		FilesTransferred: aws.Int64(2),
+		Duration:         aws.Int64(60000),
+	}, runs[1])
+	assert.Equal(t, 3, ds.execCalls)
+
+	// finished runs are cached
+	if _, _, err := o.datamoverRunListPage(context.TODO(), "group1", "mover0", &DatamoverRunListInput{Expand: true}); err != nil {
+		t.Fatalf("expected nil error, got %s", err)
+	}
+	assert.Equal(t, 4, ds.execCalls)
+
+	// only the runs of the page are described
+	runs, _, err = o.datamoverRunListPage(context.TODO(), "group1", "mover0", &DatamoverRunListInput{Expand: true, Limit: 1})
+	if err != nil {
+		t.Fatalf("expected nil error, got %s", err)
+	}
+	assert.Equal(t, []string{"exec-3"}, ids(runs))
+	assert.Equal(t, 5, ds.execCalls)
+
+	// pages follow the continuation token
+	got := []string{}
+	input := &DatamoverRunListInput{Limit: 2}
+	for pages := 0; pages < 3; pages++ {
+		runs, next, err = o.datamoverRunListPage(context.TODO(), "group1", "mover0", input)
+		if err != nil {
+			t.Fatalf("expected nil error, got %s", err)
+		}
+		got = append(got, ids(runs)...)
+
+		if next == "" {
+			break
+		}
+		input.Next = next
+	}
+	assert.Equal(t, []string{"exec-3", "exec-2", "exec-1"}, got)
+	assert.Equal(t, 5, ds.execCalls)
+
+	// pages filtered by start time follow the continuation token too
+	got = []string{}
+	input = &DatamoverRunListInput{Since: aws.Time(testTime), Limit: 2}
+	for pages := 0; pages < 3; pages++ {
+		runs, next, err = o.datamoverRunListPage(context.TODO(), "group1", "mover0", input)
+		if err != nil {
+			t.Fatalf("expected nil error, got %s", err)
+		}
+		got = append(got, ids(runs)...)
+
+		if next == "" {
+			break
+		}
+		input.Next = next
+	}
+	assert.Equal(t, []string{"exec-3", "exec-2", "exec-1"}, got)
+
+	tests := []struct {
+		name  string
+		input *DatamoverRunListInput
+		want  []string
+	}{
+		{"status", &DatamoverRunListInput{Status: datasync.TaskExecutionStatusError}, []string{"exec-1"}},
+		{"since", &DatamoverRunListInput{Since: aws.Time(testTime.Add(2 * time.Hour))}, []string{"exec-3", "exec-2"}},
+		{"until", &DatamoverRunListInput{Until: aws.Time(testTime.Add(90 * time.Minute))}, []string{"exec-1"}},
+		{"since and until", &DatamoverRunListInput{Since: aws.Time(testTime.Add(90 * time.Minute)), Until: aws.Time(testTime.Add(150 * time.Minute))}, []string{"exec-2"}},
+		{"no matches", &DatamoverRunListInput{Status: datasync.TaskExecutionStatusQueued}, []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			runs, _, err := o.datamoverRunListPage(context.TODO(), "group1", "mover0", tt.input)
+			if err != nil {
+				t.Fatalf("expected nil error, got %s", err)
+			}
+			assert.Equal(t, tt.want, ids(runs))
+		})
+	}
+
+	for _, token := range []string{"!!!", encodeRunCursor("exec-9")} {
+		if _, _, err := o.datamoverRunListPage(context.TODO(), "group1", "mover0", &DatamoverRunListInput{Next: token}); err == nil {
+			t.Errorf("expected error for invalid token %s, got nil", token)
+		}
+	}
+}
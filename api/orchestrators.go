@@ -2,40 +2,75 @@ package api
 
 import (
 	"context"
+	"fmt"
 	"strconv"
 	"time"
 
 	"github.com/YaleSpinup/aws-go/services/iam"
 	"github.com/YaleSpinup/aws-go/services/resourcegroupstaggingapi"
+	"github.com/YaleSpinup/datasync-api/cloudwatchlogs"
 	"github.com/YaleSpinup/datasync-api/common"
 	"github.com/YaleSpinup/datasync-api/datasync"
+	"github.com/YaleSpinup/datasync-api/s3"
 	"github.com/YaleSpinup/flywheel"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
 	log "github.com/sirupsen/logrus"
 )
 
+// flywheelTasks counts the async flywheel tasks by outcome (completed or failed)
+var flywheelTasks = promauto.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "datasync_api",
+	Name:      "flywheel_tasks_total",
+	Help:      "Number of async flywheel tasks, by outcome (completed or failed).",
+}, []string{"outcome"})
+
 type datasyncOrchestrator struct {
 	account        string
+	accountConfig  *accountConfig
+	region         string
 	server         *server
 	sp             *sessionParams
 	datasyncClient datasync.Datasync
 	iamClient      iam.IAM
+	logsClient     cloudwatchlogs.CloudWatchLogs
 	rgClient       resourcegroupstaggingapi.ResourceGroupsTaggingAPI
+	s3Client       s3.S3
 }
 
 // sessionParams stores all required parameters to initialize the connection session
 type sessionParams struct {
+	// region is the region of the session, the default region of the account is used when it's empty
+	region string
+	// role and externalId are set from the account configuration
 	role         string
+	externalId   string
 	inlinePolicy string
 	policyArns   []string
 }
 
-// newDatasyncOrchestrator creates a new session and initializes all clients
+// newDatasyncOrchestrator creates a new session in an account, by ID or alias, and initializes all clients.  The
+// role, external ID and regions come from the account configuration.
 func (s *server) newDatasyncOrchestrator(ctx context.Context, account string, sp *sessionParams) (*datasyncOrchestrator, error) {
 	log.Debug("initializing datasyncOrchestrator")
 
+	acct, err := s.accountConfig(account)
+	if err != nil {
+		return nil, err
+	}
+
+	region, err := acct.region(sp.region)
+	if err != nil {
+		return nil, err
+	}
+	sp.region = region
+	sp.role = fmt.Sprintf("arn:aws:iam::%s:role/%s", acct.id, acct.role)
+	sp.externalId = acct.externalId
+
 	sess, err := s.assumeRole(
 		ctx,
-		s.session.ExternalID,
+		sp.externalId,
+		sp.region,
 		sp.role,
 		sp.inlinePolicy,
 		sp.policyArns...,
@@ -45,22 +80,71 @@ func (s *server) newDatasyncOrchestrator(ctx context.Context, account string, sp
 	}
 
 	return &datasyncOrchestrator{
-		account:        account,
+		account:        acct.id,
+		accountConfig:  acct,
+		region:         sp.region,
 		server:         s,
 		sp:             sp,
 		datasyncClient: datasync.New(datasync.WithSession(sess.Session)),
 		iamClient:      iam.New(iam.WithSession(sess.Session)),
+		logsClient:     cloudwatchlogs.New(cloudwatchlogs.WithSession(sess.Session)),
 		rgClient:       resourcegroupstaggingapi.New(resourcegroupstaggingapi.WithSession(sess.Session)),
+		s3Client:       s3.New(s3.WithSession(sess.Session)),
 	}, nil
 }
 
+// newRegionalOrchestrators returns an orchestrator for the region of the session params, or an orchestrator
+// for each of the regions of the account when the region is empty
+func (s *server) newRegionalOrchestrators(ctx context.Context, account string, sp *sessionParams) ([]*datasyncOrchestrator, error) {
+	if sp.region != "" {
+		o, err := s.newDatasyncOrchestrator(ctx, account, sp)
+		if err != nil {
+			return nil, err
+		}
+
+		return []*datasyncOrchestrator{o}, nil
+	}
+
+	acct, err := s.accountConfig(account)
+	if err != nil {
+		return nil, err
+	}
+
+	orchs := make([]*datasyncOrchestrator, 0, len(acct.regions))
+	for _, r := range acct.regions {
+		rsp := *sp
+		rsp.region = r
+
+		o, err := s.newDatasyncOrchestrator(ctx, account, &rsp)
+		if err != nil {
+			return nil, err
+		}
+		orchs = append(orchs, o)
+	}
+
+	return orchs, nil
+}
+
+// newReadOnlyOrchestrator returns an orchestrator with read-only access to DataSync and resource tags in a region,
+// it's used by the background workers that follow runs
+func (s *server) newReadOnlyOrchestrator(ctx context.Context, account, region string) (*datasyncOrchestrator, error) {
+	return s.newDatasyncOrchestrator(ctx, account, &sessionParams{
+		region: region,
+		policyArns: []string{
+			"arn:aws:iam::aws:policy/AWSDataSyncReadOnlyAccess",
+			"arn:aws:iam::aws:policy/ResourceGroupsandTagEditorReadOnlyAccess",
+		},
+	})
+}
+
 // refreshSession refreshes the session for all client connections
 func (o *datasyncOrchestrator) refreshSession(ctx context.Context) error {
 	log.Debug("refreshing datasyncOrchestrator session")
 
 	sess, err := o.server.assumeRole(
 		ctx,
-		o.server.session.ExternalID,
+		o.sp.externalId,
+		o.sp.region,
 		o.sp.role,
 		o.sp.inlinePolicy,
 		o.sp.policyArns...,
@@ -70,11 +154,21 @@ func (o *datasyncOrchestrator) refreshSession(ctx context.Context) error {
 	}
 
 	o.datasyncClient = datasync.New(datasync.WithSession(sess.Session))
+	o.logsClient = cloudwatchlogs.New(cloudwatchlogs.WithSession(sess.Session))
 	o.rgClient = resourcegroupstaggingapi.New(resourcegroupstaggingapi.WithSession(sess.Session))
+	o.s3Client = s3.New(s3.WithSession(sess.Session))
 
 	return nil
 }
 
+// inRegion returns an orchestrator with the same session params in another region
+func (o *datasyncOrchestrator) inRegion(ctx context.Context, region string) (*datasyncOrchestrator, error) {
+	sp := *o.sp
+	sp.region = region
+
+	return o.server.newDatasyncOrchestrator(ctx, o.account, &sp)
+}
+
 // startTask starts the flywheel task and receives messages on the channels
 func (o *datasyncOrchestrator) startTask(ctx context.Context, task *flywheel.Task) (chan<- string, chan<- error) {
 	msgChan := make(chan string)
@@ -103,6 +197,7 @@ func (o *datasyncOrchestrator) startTask(ctx context.Context, task *flywheel.Tas
 				}
 			case err := <-errChan:
 				log.Error(err)
+				flywheelTasks.WithLabelValues("failed").Inc()
 
 				if ferr := o.server.flywheel.Fail(taskCtx, task.ID, err.Error()); ferr != nil {
 					log.Errorf("failed to fail flywheel task %s: %s", task.ID, ferr)
@@ -111,6 +206,7 @@ func (o *datasyncOrchestrator) startTask(ctx context.Context, task *flywheel.Tas
 				return
 			case <-ctx.Done():
 				log.Infof("marking task %s complete", task.ID)
+				flywheelTasks.WithLabelValues("completed").Inc()
 
 				if ferr := o.server.flywheel.Complete(taskCtx, task.ID); ferr != nil {
 					log.Errorf("failed to complete flywheel task %s: %s", task.ID, ferr)
@@ -42,6 +42,59 @@ func (s *server) moverCreatePolicy() (string, error) {
 					fmt.Sprintf("arn:aws:iam::*:role/spinup/%s/*", s.org),
 				},
 			},
+			{
+				Sid:    "CreateLogGroup",
+				Effect: "Allow",
+				Action: []string{
+					"logs:CreateLogGroup",
+					"logs:DeleteLogGroup",
+					"logs:PutRetentionPolicy",
+					"logs:TagLogGroup",
+					"logs:TagResource",
+				},
+				Resource: []string{
+					fmt.Sprintf("arn:aws:logs:*:*:log-group:/aws/datasync/spinup/%s/*", s.org),
+				},
+			},
+			{
+				Sid:    "LogResourcePolicy",
+				Effect: "Allow",
+				Action: []string{
+					"logs:DescribeLogGroups",
+					"logs:PutResourcePolicy",
+				},
+				Resource: []string{"*"},
+			},
+		},
+	}
+
+	j, err := json.Marshal(policy)
+	if err != nil {
+		return "", err
+	}
+
+	return string(j), nil
+}
+
+// moverManifestPolicy returns the IAM inline policy for creating or updating the manifest role of a mover
+func (s *server) moverManifestPolicy() (string, error) {
+	policy := &iam.PolicyDocument{
+		Version: "2012-10-17",
+		Statement: []iam.StatementEntry{
+			{
+				Sid:    "ManifestRole",
+				Effect: "Allow",
+				Action: []string{
+					"iam:CreateRole",
+					"iam:GetRole",
+					"iam:GetRolePolicy",
+					"iam:PutRolePolicy",
+					"iam:TagRole",
+				},
+				Resource: []string{
+					fmt.Sprintf("arn:aws:iam::*:role/spinup/%s/*", s.org),
+				},
+			},
 		},
 	}
 
@@ -74,6 +127,16 @@ func (s *server) moverDeletePolicy() (string, error) {
 					fmt.Sprintf("arn:aws:iam::*:role/spinup/%s/*", s.org),
 				},
 			},
+			{
+				Sid:    "DeleteLogGroup",
+				Effect: "Allow",
+				Action: []string{
+					"logs:DeleteLogGroup",
+				},
+				Resource: []string{
+					fmt.Sprintf("arn:aws:logs:*:*:log-group:/aws/datasync/spinup/%s/*", s.org),
+				},
+			},
 		},
 	}
 
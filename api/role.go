@@ -12,15 +12,25 @@ import (
 	"github.com/aws/aws-sdk-go/service/sts"
 	"github.com/google/uuid"
 	"github.com/patrickmn/go-cache"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
 	log "github.com/sirupsen/logrus"
 )
 
-// assumeRole assumes the passed role arn.  if an externalId is set in the account to be accessed, it can be passed with the request. inline
-// policy can be passed to limit the access for the session.  policy arns can also be passed to limit access for the session.
+// sessionCacheLookups counts the assumed role session cache lookups by result (hit or miss)
+var sessionCacheLookups = promauto.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "datasync_api",
+	Name:      "session_cache_lookups_total",
+	Help:      "Number of lookups in the assumed role session cache, by result (hit or miss).",
+}, []string{"result"})
+
+// assumeRole assumes the passed role arn and returns a session in the region.  if an externalId is set in the account to be accessed, it can be
+// passed with the request. inline policy can be passed to limit the access for the session.  policy arns can also be passed to limit access for the session.
 // Note: sessions live for 900s and will be cached for 600 seconds, giving a 300s buffer to avoid terminated sessions inside of orchestration
-func (s *server) assumeRole(ctx context.Context, externalId, roleArn, inlinePolicy string, policyArns ...string) (*session.Session, error) {
+func (s *server) assumeRole(ctx context.Context, externalId, region, roleArn, inlinePolicy string, policyArns ...string) (*session.Session, error) {
 	contextLogger := log.WithFields(log.Fields{
-		"role": roleArn,
+		"role":   roleArn,
+		"region": region,
 	})
 
 	start := time.Now()
@@ -47,7 +57,7 @@ func (s *server) assumeRole(ctx context.Context, externalId, roleArn, inlinePoli
 		},
 	}
 
-	cacheKey := fmt.Sprintf("spinup_%s_%s", s.org, roleArn)
+	cacheKey := fmt.Sprintf("spinup_%s_%s_%s", s.org, region, roleArn)
 
 	if externalId != "" {
 		input.SetExternalId(externalId)
@@ -77,10 +87,13 @@ func (s *server) assumeRole(ctx context.Context, externalId, roleArn, inlinePoli
 	if found {
 		if sess, ok := item.(*session.Session); ok {
 			contextLogger.Infof("using cached session (expire: %s)", expire.String())
+			sessionCacheLookups.WithLabelValues("hit").Inc()
 			return sess, nil
 		}
 	}
 
+	sessionCacheLookups.WithLabelValues("miss").Inc()
+
 	contextLogger.Debugf("assuming role %s with input %+v", roleArn, input)
 
 	out, err := stsService.AssumeRole(ctx, &input)
@@ -99,7 +112,7 @@ func (s *server) assumeRole(ctx context.Context, externalId, roleArn, inlinePoli
 			aws.StringValue(out.Credentials.SecretAccessKey),
 			aws.StringValue(out.Credentials.SessionToken),
 		),
-		session.WithRegion("us-east-1"),
+		session.WithRegion(region),
 	)
 
 	contextLogger.Debugf("caching session with cache key: '%s'", cacheKey)
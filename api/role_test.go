@@ -0,0 +1,29 @@
+package api
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/YaleSpinup/datasync-api/session"
+	"github.com/patrickmn/go-cache"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAssumeRoleCached(t *testing.T) {
+	s := &server{org: "org", sessionCache: cache.New(600*time.Second, 900*time.Second)}
+
+	cached := &session.Session{}
+	s.sessionCache.Set("spinup_org_us-west-2_arn:aws:iam::012345678901:role/SpinupRole_ext_arn:aws:iam::aws:policy/AWSDataSyncReadOnlyAccess", cached, cache.DefaultExpiration)
+
+	hits := testutil.ToFloat64(sessionCacheLookups.WithLabelValues("hit"))
+
+	sess, err := s.assumeRole(context.TODO(), "ext", "us-west-2", "arn:aws:iam::012345678901:role/SpinupRole", "", "arn:aws:iam::aws:policy/AWSDataSyncReadOnlyAccess")
+	if err != nil {
+		t.Fatalf("expected nil error, got %s", err)
+	}
+
+	assert.Same(t, cached, sess)
+	assert.Equal(t, hits+1, testutil.ToFloat64(sessionCacheLookups.WithLabelValues("hit")))
+}
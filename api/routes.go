@@ -24,6 +24,7 @@ import (
 
 func (s *server) routes() {
 	api := s.router.PathPrefix("/v1/datasync").Subrouter()
+	api.Use(MetricsMiddleware, s.GroupMiddleware)
 
 	api.HandleFunc("/ping", s.PingHandler).Methods(http.MethodGet)
 	api.HandleFunc("/version", s.VersionHandler).Methods(http.MethodGet)
@@ -38,8 +39,21 @@ func (s *server) routes() {
 	api.HandleFunc("/{account}/movers/{group}/{name}", s.MoverDeleteHandler).Methods(http.MethodDelete)
 
 	api.HandleFunc("/{account}/movers/{group}/{name}", s.MoverUpdateHandler).Methods(http.MethodPut)
+	api.HandleFunc("/{account}/movers/{group}/{name}", s.MoverPatchHandler).Methods(http.MethodPatch)
+
+	api.HandleFunc("/{account}/movers/{group}/{name}/webhooks", s.WebhooksShowHandler).Methods(http.MethodGet)
+	api.HandleFunc("/{account}/movers/{group}/{name}/webhooks", s.WebhooksUpdateHandler).Methods(http.MethodPut)
 
 	api.HandleFunc("/{account}/movers/{group}/{name}/runs", s.RunListHandler).Methods(http.MethodGet)
 	api.HandleFunc("/{account}/movers/{group}/{name}/runs/{id}", s.RunShowHandler).Methods(http.MethodGet)
-
+	api.HandleFunc("/{account}/movers/{group}/{name}/runs/{id}", s.RunUpdateHandler).Methods(http.MethodPatch)
+	api.HandleFunc("/{account}/movers/{group}/{name}/runs/{id}/events", s.RunEventsHandler).Methods(http.MethodGet)
+	api.HandleFunc("/{account}/movers/{group}/{name}/runs/{id}/logs", s.RunLogsHandler).Methods(http.MethodGet)
+	api.HandleFunc("/{account}/movers/{group}/{name}/runs/{id}/report", s.RunReportHandler).Methods(http.MethodGet)
+
+	api.HandleFunc("/{account}/agents", s.AgentListHandler).Methods(http.MethodGet)
+	api.HandleFunc("/{account}/agents/{group}", s.AgentCreateHandler).Methods(http.MethodPost)
+	api.HandleFunc("/{account}/agents/{group}", s.AgentListHandler).Methods(http.MethodGet)
+	api.HandleFunc("/{account}/agents/{group}/{name}", s.AgentShowHandler).Methods(http.MethodGet)
+	api.HandleFunc("/{account}/agents/{group}/{name}", s.AgentDeleteHandler).Methods(http.MethodDelete)
 }
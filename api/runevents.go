@@ -0,0 +1,354 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/YaleSpinup/apierror"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/datasync"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// runWatchMinInterval is the polling interval of a run while its progress is changing
+	runWatchMinInterval = 2 * time.Second
+	// runWatchMaxInterval is the longest polling interval, the interval doubles each time the progress doesn't change
+	runWatchMaxInterval = 30 * time.Second
+	// runWatchMaxErrors is the number of consecutive failed polls that end the stream
+	runWatchMaxErrors = 5
+	// runEventsKeepalive is how often a comment is sent on an idle stream so proxies don't close it
+	runEventsKeepalive = 15 * time.Second
+)
+
+// runEvent is a snapshot of a run sent to the subscribers of a run watcher, the final
+// event is sent when the run finishes or can't be polled anymore
+type runEvent struct {
+	Run   *DatamoverRun
+	Final bool
+	Err   error
+}
+
+// runDescribeFunc describes the task execution watched by a run watcher
+type runDescribeFunc func(ctx context.Context) (*datasync.DescribeTaskExecutionOutput, error)
+
+// runWatchers tracks the run watchers by task execution ARN, so subscribers to the same
+// execution share a single poller
+type runWatchers struct {
+	minInterval time.Duration
+	maxInterval time.Duration
+
+	mu       sync.Mutex
+	watchers map[string]*runWatcher
+}
+
+// runWatcher polls a task execution and publishes a snapshot to its subscribers when the progress changes
+type runWatcher struct {
+	describe runDescribeFunc
+	cancel   context.CancelFunc
+	// wake triggers a poll right away, ie. when a state change event is received
+	wake chan struct{}
+
+	mu          sync.Mutex
+	subscribers map[chan *runEvent]struct{}
+	last        *runEvent
+	done        bool
+}
+
+// newRunWatchers returns an empty run watcher registry with the default polling intervals
+func newRunWatchers() *runWatchers {
+	return &runWatchers{
+		minInterval: runWatchMinInterval,
+		maxInterval: runWatchMaxInterval,
+		watchers:    map[string]*runWatcher{},
+	}
+}
+
+// subscribe returns a channel of events for a task execution and a function to unsubscribe.  The
+// execution is polled with describe, unless it's already watched for another subscriber.  The channel
+// is closed after the final event.
+func (r *runWatchers) subscribe(execArn string, describe runDescribeFunc) (<-chan *runEvent, func()) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	w, ok := r.watchers[execArn]
+	if !ok {
+		log.Debugf("starting run watcher for %s", execArn)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		w = &runWatcher{
+			describe:    describe,
+			cancel:      cancel,
+			wake:        make(chan struct{}, 1),
+			subscribers: map[chan *runEvent]struct{}{},
+		}
+		r.watchers[execArn] = w
+
+		go func() {
+			w.run(ctx, r.minInterval, r.maxInterval)
+			r.remove(execArn, w)
+		}()
+	}
+
+	ch := w.add()
+
+	return ch, func() { r.unsubscribe(execArn, w, ch) }
+}
+
+// unsubscribe removes a subscriber and stops the watcher when it was the last one
+func (r *runWatchers) unsubscribe(execArn string, w *runWatcher, ch chan *runEvent) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if w.remove(ch) == 0 && r.watchers[execArn] == w {
+		log.Debugf("stopping run watcher for %s, no subscribers left", execArn)
+
+		delete(r.watchers, execArn)
+		w.cancel()
+	}
+}
+
+// wake makes the watcher of a task execution poll right away, if it's watched
+func (r *runWatchers) wake(execArn string) {
+	r.mu.Lock()
+	w, ok := r.watchers[execArn]
+	r.mu.Unlock()
+
+	if !ok {
+		return
+	}
+
+	select {
+	case w.wake <- struct{}{}:
+	default:
+	}
+}
+
+// remove forgets a watcher that stopped polling
+func (r *runWatchers) remove(execArn string, w *runWatcher) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.watchers[execArn] == w {
+		delete(r.watchers, execArn)
+	}
+}
+
+// add returns a new subscriber channel, the latest snapshot is sent right away
+func (w *runWatcher) add() chan *runEvent {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	ch := make(chan *runEvent, 1)
+	if w.last != nil {
+		ch <- w.last
+	}
+
+	if w.done {
+		close(ch)
+		return ch
+	}
+
+	w.subscribers[ch] = struct{}{}
+
+	return ch
+}
+
+// remove closes a subscriber channel and returns the number of remaining subscribers
+func (w *runWatcher) remove(ch chan *runEvent) int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if _, ok := w.subscribers[ch]; ok {
+		delete(w.subscribers, ch)
+		close(ch)
+	}
+
+	return len(w.subscribers)
+}
+
+// publish sends an event to the subscribers.  Subscribers only need the latest snapshot, so a
+// snapshot that hasn't been read yet is replaced instead of blocking the poller.
+func (w *runWatcher) publish(ev *runEvent) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.last = ev
+	for ch := range w.subscribers {
+		select {
+		case ch <- ev:
+		default:
+			select {
+			case <-ch:
+			default:
+			}
+			ch <- ev
+		}
+
+		if ev.Final {
+			delete(w.subscribers, ch)
+			close(ch)
+		}
+	}
+
+	if ev.Final {
+		w.done = true
+	}
+}
+
+// run polls the task execution until it finishes or the context is cancelled.  The interval is reset
+// when the progress changes and doubles up to the max interval when it doesn't, or when polling fails.
+func (w *runWatcher) run(ctx context.Context, minInterval, maxInterval time.Duration) {
+	interval := minInterval
+	errs := 0
+	for {
+		exec, err := w.describe(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+
+		changed := false
+		if err != nil {
+			errs++
+			log.Warnf("failed to poll datasync mover run (%d/%d): %s", errs, runWatchMaxErrors, err)
+
+			if errs >= runWatchMaxErrors {
+				w.publish(&runEvent{Err: err, Final: true})
+				return
+			}
+		} else {
+			errs = 0
+
+			ev := &runEvent{Run: datamoverRunFromExecution(exec), Final: runFinished(exec.Status)}
+			if ev.Final || w.changed(ev.Run) {
+				w.publish(ev)
+				changed = true
+			}
+
+			if ev.Final {
+				return
+			}
+		}
+
+		if changed {
+			interval = minInterval
+		} else if interval *= 2; interval > maxInterval {
+			interval = maxInterval
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-w.wake:
+			interval = minInterval
+		case <-time.After(interval):
+		}
+	}
+}
+
+// changed returns true if the run progress differs from the last published snapshot
+func (w *runWatcher) changed(run *DatamoverRun) bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.last == nil || w.last.Run == nil {
+		return true
+	}
+
+	return runProgressChanged(w.last.Run, run)
+}
+
+// runFinished returns true for terminal task execution statuses
+func runFinished(status *string) bool {
+	switch aws.StringValue(status) {
+	case datasync.TaskExecutionStatusSuccess, datasync.TaskExecutionStatusError:
+		return true
+	}
+
+	return false
+}
+
+// runProgressChanged compares the transferred bytes and files and the status of each phase of two runs
+func runProgressChanged(a, b *DatamoverRun) bool {
+	if aws.Int64Value(a.BytesTransferred) != aws.Int64Value(b.BytesTransferred) ||
+		aws.Int64Value(a.FilesTransferred) != aws.Int64Value(b.FilesTransferred) ||
+		aws.StringValue(a.Status) != aws.StringValue(b.Status) {
+		return true
+	}
+
+	ra, rb := a.Result, b.Result
+	if ra == nil {
+		ra = &datasync.TaskExecutionResultDetail{}
+	}
+	if rb == nil {
+		rb = &datasync.TaskExecutionResultDetail{}
+	}
+
+	return aws.StringValue(ra.PrepareStatus) != aws.StringValue(rb.PrepareStatus) ||
+		aws.StringValue(ra.TransferStatus) != aws.StringValue(rb.TransferStatus) ||
+		aws.StringValue(ra.VerifyStatus) != aws.StringValue(rb.VerifyStatus)
+}
+
+// writeRunEvent writes an event in the Server-Sent Events format.  Snapshots are "run" events, the
+// snapshot of a finished run is a "done" event and a run that can't be polled ends with an "error" event.
+func writeRunEvent(w io.Writer, ev *runEvent) error {
+	name := "run"
+	var data interface{} = ev.Run
+	switch {
+	case ev.Err != nil:
+		name = "error"
+		data = struct{ Error string }{ev.Err.Error()}
+	case ev.Final:
+		name = "done"
+	}
+
+	j, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(w, "event: %s\ndata: %s\n\n", name, j)
+	return err
+}
+
+// datamoverRunEvents subscribes to the progress of a data mover run.  The run is described once, so
+// an unknown run fails before the stream starts.
+func (o *datasyncOrchestrator) datamoverRunEvents(ctx context.Context, group, name, id string) (<-chan *runEvent, func(), error) {
+	if o.server.runWatchers == nil {
+		return nil, nil, apierror.New(apierror.ErrInternalError, "run events are not available", nil)
+	}
+
+	task, _, err := o.taskDetailsFromName(ctx, group, name)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if id == "" {
+		return nil, nil, apierror.New(apierror.ErrBadRequest, "invalid input, id is missing", nil)
+	}
+	execArn := fmt.Sprintf("%s/execution/%s", aws.StringValue(task.TaskArn), id)
+
+	if _, err := o.datasyncClient.DescribeTaskExecution(ctx, execArn); err != nil {
+		return nil, nil, err
+	}
+
+	log.Infof("subscribing to progress of data mover %s run %s", name, id)
+
+	events, unsubscribe := o.server.runWatchers.subscribe(execArn, func(ctx context.Context) (*datasync.DescribeTaskExecutionOutput, error) {
+		exec, err := o.datasyncClient.DescribeTaskExecution(ctx, execArn)
+		if err != nil && ctx.Err() == nil {
+			// the watcher outlives the request that started it, so the session may have expired
+			if rerr := o.refreshSession(ctx); rerr != nil {
+				log.Warnf("failed to refresh session for run watcher: %s", rerr)
+			}
+		}
+
+		return exec, err
+	})
+
+	return events, unsubscribe, nil
+}
@@ -0,0 +1,282 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/datasync"
+	"github.com/stretchr/testify/assert"
+)
+
+// mockRunDescriber returns the executions in order, the last one is repeated
+type mockRunDescriber struct {
+	mu    sync.Mutex
+	execs []*datasync.DescribeTaskExecutionOutput
+	err   error
+	calls int
+	// release blocks the first call until it's closed
+	release chan struct{}
+}
+
+func (m *mockRunDescriber) describe(ctx context.Context) (*datasync.DescribeTaskExecutionOutput, error) {
+	if m.release != nil {
+		<-m.release
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.calls++
+	if m.err != nil {
+		return nil, m.err
+	}
+
+	i := m.calls - 1
+	if i >= len(m.execs) {
+		i = len(m.execs) - 1
+	}
+
+	return m.execs[i], nil
+}
+
+func testExecution(status string, bytes int64) *datasync.DescribeTaskExecutionOutput {
+	return &datasync.DescribeTaskExecutionOutput{
+		BytesTransferred: aws.Int64(bytes),
+		FilesTransferred: aws.Int64(bytes / 1024),
+		Status:           aws.String(status),
+	}
+}
+
+func newTestRunWatchers() *runWatchers {
+	r := newRunWatchers()
+	r.minInterval = time.Millisecond
+	r.maxInterval = 4 * time.Millisecond
+	return r
+}
+
+// collectRunEvents reads the events until the channel is closed
+func collectRunEvents(t *testing.T, events <-chan *runEvent) []*runEvent {
+	out := []*runEvent{}
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case ev, ok := <-events:
+			if !ok {
+				return out
+			}
+			out = append(out, ev)
+		case <-timeout:
+			t.Fatal("timed out waiting for run events")
+		}
+	}
+}
+
+func Test_runProgressChanged(t *testing.T) {
+	base := func() *DatamoverRun {
+		return &DatamoverRun{
+			BytesTransferred: aws.Int64(1024),
+			FilesTransferred: aws.Int64(1),
+			Status:           aws.String(datasync.TaskExecutionStatusTransferring),
+			Result:           &datasync.TaskExecutionResultDetail{PrepareStatus: aws.String(datasync.PhaseStatusSuccess)},
+			BytesPerSecond:   aws.Int64(-1),
+		}
+	}
+
+	tests := []struct {
+		name   string
+		modify func(*DatamoverRun)
+		want   bool
+	}{
+		{"unchanged", func(r *DatamoverRun) {}, false},
+		{"bandwidth", func(r *DatamoverRun) { r.BytesPerSecond = aws.Int64(1024) }, false},
+		{"bytes", func(r *DatamoverRun) { r.BytesTransferred = aws.Int64(2048) }, true},
+		{"files", func(r *DatamoverRun) { r.FilesTransferred = aws.Int64(2) }, true},
+		{"status", func(r *DatamoverRun) { r.Status = aws.String(datasync.TaskExecutionStatusVerifying) }, true},
+		{"phase", func(r *DatamoverRun) { r.Result.TransferStatus = aws.String(datasync.PhaseStatusSuccess) }, true},
+		{"no result", func(r *DatamoverRun) { r.Result = nil }, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			run := base()
+			tt.modify(run)
+			assert.Equal(t, tt.want, runProgressChanged(base(), run))
+		})
+	}
+}
+
+func Test_writeRunEvent(t *testing.T) {
+	tests := []struct {
+		name string
+		ev   *runEvent
+		want string
+	}{
+		{
+			name: "snapshot",
+			ev:   &runEvent{Run: &DatamoverRun{Status: aws.String("TRANSFERRING")}},
+			want: "event: run\ndata: {\"BytesTransferred\":null,\"BytesWritten\":null,\"EstimatedBytesToTransfer\":null,\"EstimatedFilesToTransfer\":null,\"FilesTransferred\":null,\"StartTime\":null,\"Status\":\"TRANSFERRING\",\"Result\":null,\"BytesPerSecond\":null}\n\n",
+		},
+		{
+			name: "final",
+			ev:   &runEvent{Run: &DatamoverRun{Status: aws.String("SUCCESS")}, Final: true},
+			want: "event: done\ndata: {\"BytesTransferred\":null,\"BytesWritten\":null,\"EstimatedBytesToTransfer\":null,\"EstimatedFilesToTransfer\":null,\"FilesTransferred\":null,\"StartTime\":null,\"Status\":\"SUCCESS\",\"Result\":null,\"BytesPerSecond\":null}\n\n",
+		},
+		{
+			name: "error",
+			ev:   &runEvent{Err: errors.New("boom"), Final: true},
+			want: "event: error\ndata: {\"Error\":\"boom\"}\n\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			if err := writeRunEvent(buf, tt.ev); err != nil {
+				t.Fatalf("expected nil error, got %s", err)
+			}
+			assert.Equal(t, tt.want, buf.String())
+		})
+	}
+}
+
+func Test_runWatchersShared(t *testing.T) {
+	r := newTestRunWatchers()
+	m := &mockRunDescriber{
+		release: make(chan struct{}),
+		execs: []*datasync.DescribeTaskExecutionOutput{
+			testExecution(datasync.TaskExecutionStatusTransferring, 1024),
+			testExecution(datasync.TaskExecutionStatusTransferring, 1024),
+			testExecution(datasync.TaskExecutionStatusTransferring, 2048),
+			testExecution(datasync.TaskExecutionStatusSuccess, 4096),
+		},
+	}
+
+	first, unsubscribeFirst := r.subscribe("exec-1", m.describe)
+	defer unsubscribeFirst()
+
+	second, unsubscribeSecond := r.subscribe("exec-1", func(ctx context.Context) (*datasync.DescribeTaskExecutionOutput, error) {
+		t.Error("expected the second subscriber to share the first poller")
+		return nil, errors.New("unexpected describe")
+	})
+	defer unsubscribeSecond()
+
+	r.mu.Lock()
+	assert.Len(t, r.watchers, 1)
+	r.mu.Unlock()
+
+	close(m.release)
+
+	for _, events := range [][]*runEvent{collectRunEvents(t, first), collectRunEvents(t, second)} {
+		if len(events) == 0 {
+			t.Fatal("expected run events, got none")
+		}
+
+		// intermediate snapshots may be replaced, but the stream always ends with the final snapshot
+		last := events[len(events)-1]
+		assert.True(t, last.Final)
+		assert.Nil(t, last.Err)
+		assert.Equal(t, datasync.TaskExecutionStatusSuccess, aws.StringValue(last.Run.Status))
+		assert.Equal(t, int64(4096), aws.Int64Value(last.Run.BytesTransferred))
+
+		for _, ev := range events[:len(events)-1] {
+			assert.False(t, ev.Final)
+		}
+	}
+
+	m.mu.Lock()
+	assert.Equal(t, 4, m.calls)
+	m.mu.Unlock()
+
+	// a subscriber to a finished watcher that hasn't been removed yet gets the final snapshot, otherwise
+	// a new watcher polls the finished run once
+	late, unsubscribeLate := r.subscribe("exec-1", m.describe)
+	defer unsubscribeLate()
+	events := collectRunEvents(t, late)
+	if assert.Len(t, events, 1) {
+		assert.True(t, events[0].Final)
+	}
+}
+
+func Test_runWatchersErrors(t *testing.T) {
+	r := newTestRunWatchers()
+	m := &mockRunDescriber{err: errors.New("boom")}
+
+	events, unsubscribe := r.subscribe("exec-1", m.describe)
+	defer unsubscribe()
+
+	got := collectRunEvents(t, events)
+	if assert.Len(t, got, 1) {
+		assert.True(t, got[0].Final)
+		assert.EqualError(t, got[0].Err, "boom")
+	}
+
+	m.mu.Lock()
+	assert.Equal(t, runWatchMaxErrors, m.calls)
+	m.mu.Unlock()
+}
+
+func Test_runWatchersUnsubscribe(t *testing.T) {
+	r := newTestRunWatchers()
+	m := &mockRunDescriber{
+		execs: []*datasync.DescribeTaskExecutionOutput{
+			testExecution(datasync.TaskExecutionStatusTransferring, 1024),
+		},
+	}
+
+	first, unsubscribeFirst := r.subscribe("exec-1", m.describe)
+	_, unsubscribeSecond := r.subscribe("exec-1", m.describe)
+
+	select {
+	case ev := <-first:
+		assert.False(t, ev.Final)
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for run event")
+	}
+
+	// the watcher keeps polling while anyone is subscribed
+	unsubscribeFirst()
+	r.mu.Lock()
+	assert.Len(t, r.watchers, 1)
+	r.mu.Unlock()
+
+	_, ok := <-first
+	assert.False(t, ok, "expected the channel to be closed")
+
+	unsubscribeSecond()
+	r.mu.Lock()
+	assert.Len(t, r.watchers, 0)
+	r.mu.Unlock()
+
+	// unsubscribing twice is harmless
+	unsubscribeSecond()
+}
+
+func Test_datamoverRunEvents(t *testing.T) {
+	o, _ := newMockIndexOrchestrator(t, 1)
+	o.server.runWatchers = newTestRunWatchers()
+
+	events, unsubscribe, err := o.datamoverRunEvents(context.TODO(), "group1", "mover0", "exec-2")
+	if err != nil {
+		t.Fatalf("expected nil error, got %s", err)
+	}
+	defer unsubscribe()
+
+	got := collectRunEvents(t, events)
+	if assert.Len(t, got, 1) {
+		assert.True(t, got[0].Final)
+		assert.Equal(t, datasync.TaskExecutionStatusSuccess, aws.StringValue(got[0].Run.Status))
+	}
+
+	if _, _, err := o.datamoverRunEvents(context.TODO(), "group1", "mover0", "bogus"); err == nil {
+		t.Error("expected error for unknown run, got nil")
+	}
+
+	if _, _, err := o.datamoverRunEvents(context.TODO(), "group1", "missing", "exec-2"); err == nil {
+		t.Error("expected error for unknown mover, got nil")
+	}
+}
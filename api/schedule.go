@@ -0,0 +1,352 @@
+package api
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/YaleSpinup/apierror"
+)
+
+// schedule is a parsed DataSync schedule expression
+type schedule interface {
+	// next returns the next time the schedule runs after t, given the task creation time.  The
+	// zero time is returned if the schedule never runs again.
+	next(t, created time.Time) time.Time
+}
+
+// rateSchedule runs at a fixed interval, starting from the creation of the task
+type rateSchedule struct {
+	interval time.Duration
+}
+
+func (r rateSchedule) next(t, created time.Time) time.Time {
+	if created.IsZero() || created.After(t) {
+		return t.Add(r.interval).Truncate(time.Minute)
+	}
+
+	n := t.Sub(created)/r.interval + 1
+	return created.Add(n * r.interval)
+}
+
+// cronSchedule runs at the times matching an AWS cron expression (in UTC)
+// https://docs.aws.amazon.com/AmazonCloudWatch/latest/events/ScheduledEvents.html#CronExpressions
+type cronSchedule struct {
+	minutes map[int]bool
+	hours   map[int]bool
+	months  map[int]bool
+	years   map[int]bool
+	// day matches the day of month and day of week fields
+	day func(t time.Time) bool
+}
+
+func (c cronSchedule) next(t, _ time.Time) time.Time {
+	t = t.UTC().Truncate(time.Minute).Add(time.Minute)
+
+	// look ahead up to 5 years, a cron schedule that doesn't run in that time is effectively disabled
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	for end := day.AddDate(5, 0, 0); !day.After(end); day = day.AddDate(0, 0, 1) {
+		if !c.years[day.Year()] || !c.months[int(day.Month())] || !c.day(day) {
+			continue
+		}
+
+		for h := 0; h < 24; h++ {
+			if !c.hours[h] {
+				continue
+			}
+
+			for m := 0; m < 60; m++ {
+				if !c.minutes[m] {
+					continue
+				}
+
+				if n := day.Add(time.Duration(h)*time.Hour + time.Duration(m)*time.Minute); !n.Before(t) {
+					return n
+				}
+			}
+		}
+	}
+
+	return time.Time{}
+}
+
+var (
+	monthNames = map[string]int{"JAN": 1, "FEB": 2, "MAR": 3, "APR": 4, "MAY": 5, "JUN": 6, "JUL": 7, "AUG": 8, "SEP": 9, "OCT": 10, "NOV": 11, "DEC": 12}
+	dayNames   = map[string]int{"SUN": 1, "MON": 2, "TUE": 3, "WED": 4, "THU": 5, "FRI": 6, "SAT": 7}
+)
+
+// parseSchedule parses a DataSync schedule expression, ie. "rate(12 hours)" or "cron(0 2 * * ? *)".
+// DataSync tasks cannot be scheduled more often than once an hour.
+func parseSchedule(expr string) (schedule, error) {
+	expr = strings.TrimSpace(expr)
+
+	switch {
+	case strings.HasPrefix(expr, "rate(") && strings.HasSuffix(expr, ")"):
+		return parseRate(strings.TrimSuffix(strings.TrimPrefix(expr, "rate("), ")"))
+	case strings.HasPrefix(expr, "cron(") && strings.HasSuffix(expr, ")"):
+		return parseCron(strings.TrimSuffix(strings.TrimPrefix(expr, "cron("), ")"))
+	default:
+		return nil, apierror.New(apierror.ErrBadRequest, "schedule must be a rate() or cron() expression", nil)
+	}
+}
+
+func parseRate(expr string) (schedule, error) {
+	parts := strings.Fields(expr)
+	if len(parts) != 2 {
+		return nil, apierror.New(apierror.ErrBadRequest, "rate expression must be 'rate(value unit)'", nil)
+	}
+
+	value, err := strconv.Atoi(parts[0])
+	if err != nil || value < 1 {
+		return nil, apierror.New(apierror.ErrBadRequest, "rate value must be a positive integer", nil)
+	}
+
+	// units are singular for a value of 1 and plural otherwise
+	unit := parts[1]
+	if (value == 1) == strings.HasSuffix(unit, "s") {
+		return nil, apierror.New(apierror.ErrBadRequest, "invalid rate unit "+unit, nil)
+	}
+
+	var interval time.Duration
+	switch strings.TrimSuffix(unit, "s") {
+	case "minute":
+		interval = time.Duration(value) * time.Minute
+	case "hour":
+		interval = time.Duration(value) * time.Hour
+	case "day":
+		interval = time.Duration(value) * 24 * time.Hour
+	default:
+		return nil, apierror.New(apierror.ErrBadRequest, "invalid rate unit "+unit, nil)
+	}
+
+	if interval < time.Hour {
+		return nil, apierror.New(apierror.ErrBadRequest, "schedule cannot run more often than once an hour", nil)
+	}
+
+	return rateSchedule{interval: interval}, nil
+}
+
+func parseCron(expr string) (schedule, error) {
+	fields := strings.Fields(expr)
+	if len(fields) != 6 {
+		return nil, apierror.New(apierror.ErrBadRequest, "cron expression must have 6 fields: minutes hours day-of-month month day-of-week year", nil)
+	}
+
+	minutes, err := parseCronField(fields[0], 0, 59, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(minutes) != 1 {
+		return nil, apierror.New(apierror.ErrBadRequest, "schedule cannot run more often than once an hour", nil)
+	}
+
+	hours, err := parseCronField(fields[1], 0, 23, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	months, err := parseCronField(fields[3], 1, 12, monthNames)
+	if err != nil {
+		return nil, err
+	}
+
+	years, err := parseCronField(fields[5], 1970, 2199, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	dom, dow := fields[2], fields[4]
+	if (dom == "?") == (dow == "?") {
+		return nil, apierror.New(apierror.ErrBadRequest, "exactly one of day-of-month or day-of-week must be '?'", nil)
+	}
+
+	var day func(t time.Time) bool
+	if dow == "?" {
+		day, err = parseDayOfMonth(dom)
+	} else {
+		day, err = parseDayOfWeek(dow)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return cronSchedule{
+		minutes: minutes,
+		hours:   hours,
+		months:  months,
+		years:   years,
+		day:     day,
+	}, nil
+}
+
+// parseCronField parses a cron field made up of comma separated values, ranges (1-5),
+// increments (0/15, 1-10/2) and wildcards (*) between min and max, with optional names
+func parseCronField(field string, min, max int, names map[string]int) (map[int]bool, error) {
+	values := map[int]bool{}
+
+	value := func(s string) (int, error) {
+		if v, ok := names[strings.ToUpper(s)]; ok {
+			return v, nil
+		}
+
+		v, err := strconv.Atoi(s)
+		if err != nil || v < min || v > max {
+			return 0, apierror.New(apierror.ErrBadRequest, fmt.Sprintf("invalid cron value '%s', must be between %d and %d", s, min, max), nil)
+		}
+
+		return v, nil
+	}
+
+	for _, part := range strings.Split(field, ",") {
+		step := 1
+		if i := strings.Index(part, "/"); i >= 0 {
+			s, err := strconv.Atoi(part[i+1:])
+			if err != nil || s < 1 {
+				return nil, apierror.New(apierror.ErrBadRequest, "invalid cron increment in '"+part+"'", nil)
+			}
+			step = s
+			part = part[:i]
+		}
+
+		start, end := min, max
+		switch {
+		case part == "*":
+		case strings.Contains(part, "-"):
+			r := strings.SplitN(part, "-", 2)
+
+			var err error
+			if start, err = value(r[0]); err != nil {
+				return nil, err
+			}
+
+			if end, err = value(r[1]); err != nil {
+				return nil, err
+			}
+
+			if start > end {
+				return nil, apierror.New(apierror.ErrBadRequest, "invalid cron range '"+part+"'", nil)
+			}
+		default:
+			v, err := value(part)
+			if err != nil {
+				return nil, err
+			}
+
+			start = v
+			if step == 1 {
+				end = v
+			}
+		}
+
+		for v := start; v <= end; v += step {
+			values[v] = true
+		}
+	}
+
+	return values, nil
+}
+
+// parseDayOfMonth parses the day-of-month field, which supports L (last day of the month),
+// W (nearest weekday to the given day) and LW (last weekday of the month)
+func parseDayOfMonth(field string) (func(t time.Time) bool, error) {
+	lastDay := func(t time.Time) int {
+		return time.Date(t.Year(), t.Month()+1, 0, 0, 0, 0, 0, time.UTC).Day()
+	}
+
+	// nearestWeekday returns the weekday closest to the given day, without leaving the month
+	nearestWeekday := func(t time.Time, d int) int {
+		day := time.Date(t.Year(), t.Month(), d, 0, 0, 0, 0, time.UTC)
+		switch day.Weekday() {
+		case time.Saturday:
+			if d == 1 {
+				return d + 2
+			}
+			return d - 1
+		case time.Sunday:
+			if d == lastDay(t) {
+				return d - 2
+			}
+			return d + 1
+		}
+		return d
+	}
+
+	switch {
+	case field == "L":
+		return func(t time.Time) bool { return t.Day() == lastDay(t) }, nil
+	case field == "LW":
+		return func(t time.Time) bool { return t.Day() == nearestWeekday(t, lastDay(t)) }, nil
+	case strings.HasSuffix(field, "W"):
+		d, err := strconv.Atoi(strings.TrimSuffix(field, "W"))
+		if err != nil || d < 1 || d > 31 {
+			return nil, apierror.New(apierror.ErrBadRequest, "invalid day-of-month '"+field+"'", nil)
+		}
+
+		return func(t time.Time) bool {
+			if d > lastDay(t) {
+				return false
+			}
+			return t.Day() == nearestWeekday(t, d)
+		}, nil
+	}
+
+	days, err := parseCronField(field, 1, 31, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(t time.Time) bool { return days[t.Day()] }, nil
+}
+
+// parseDayOfWeek parses the day-of-week field (1-7 or SUN-SAT), which supports nL (last given
+// weekday of the month) and n#k (the kth given weekday of the month)
+func parseDayOfWeek(field string) (func(t time.Time) bool, error) {
+	weekday := func(s string) (int, error) {
+		v, err := parseCronField(s, 1, 7, dayNames)
+		if err != nil || len(v) != 1 {
+			return 0, apierror.New(apierror.ErrBadRequest, "invalid day-of-week '"+field+"'", nil)
+		}
+
+		for d := range v {
+			return d, nil
+		}
+		return 0, nil
+	}
+
+	switch {
+	case strings.Contains(field, "#"):
+		parts := strings.SplitN(field, "#", 2)
+
+		d, err := weekday(parts[0])
+		if err != nil {
+			return nil, err
+		}
+
+		k, err := strconv.Atoi(parts[1])
+		if err != nil || k < 1 || k > 5 {
+			return nil, apierror.New(apierror.ErrBadRequest, "invalid day-of-week '"+field+"'", nil)
+		}
+
+		return func(t time.Time) bool {
+			return int(t.Weekday())+1 == d && (t.Day()-1)/7+1 == k
+		}, nil
+	case len(field) > 1 && strings.HasSuffix(field, "L"):
+		d, err := weekday(strings.TrimSuffix(field, "L"))
+		if err != nil {
+			return nil, err
+		}
+
+		return func(t time.Time) bool {
+			return int(t.Weekday())+1 == d && t.AddDate(0, 0, 7).Month() != t.Month()
+		}, nil
+	}
+
+	days, err := parseCronField(field, 1, 7, dayNames)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(t time.Time) bool { return days[int(t.Weekday())+1] }, nil
+}
@@ -0,0 +1,108 @@
+package api
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/datasync"
+)
+
+func Test_parseSchedule(t *testing.T) {
+	tests := []struct {
+		expr    string
+		wantErr bool
+	}{
+		{"", true},
+		{"daily", true},
+		{"rate(1 hour)", false},
+		{"rate(12 hours)", false},
+		{"rate(1 day)", false},
+		{"rate(90 minutes)", false},
+		{"rate(30 minutes)", true},
+		{"rate(1 hours)", true},
+		{"rate(2 hour)", true},
+		{"rate(0 days)", true},
+		{"rate(1 week)", true},
+		{"cron(0 2 * * ? *)", false},
+		{"cron(15 12 ? * MON-FRI *)", false},
+		{"cron(0 0 L * ? *)", false},
+		{"cron(0 0 15W * ? *)", false},
+		{"cron(0 0 ? * 6L *)", false},
+		{"cron(0 0 ? * 2#1 *)", false},
+		{"cron(0 0/6 1,15 JAN-JUN ? 2026-2030)", false},
+		{"cron(0 2 * * *)", true},
+		{"cron(0/15 * * * ? *)", true},
+		{"cron(0 2 * * * *)", true},
+		{"cron(0 2 ? * ? *)", true},
+		{"cron(0 24 * * ? *)", true},
+		{"cron(0 2 32 * ? *)", true},
+		{"cron(0 2 ? * 8 *)", true},
+		{"cron(0 2 ? * 2#6 *)", true},
+		{"cron(0 2 * FOO ? *)", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			if _, err := parseSchedule(tt.expr); (err != nil) != tt.wantErr {
+				t.Errorf("parseSchedule() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func Test_scheduleNext(t *testing.T) {
+	// Friday, 16 October 2026
+	now := time.Date(2026, 10, 16, 14, 30, 0, 0, time.UTC)
+	created := time.Date(2026, 10, 1, 0, 10, 0, 0, time.UTC)
+
+	tests := []struct {
+		expr string
+		want time.Time
+	}{
+		{"rate(1 hour)", time.Date(2026, 10, 16, 15, 10, 0, 0, time.UTC)},
+		{"rate(1 day)", time.Date(2026, 10, 17, 0, 10, 0, 0, time.UTC)},
+		{"cron(0 2 * * ? *)", time.Date(2026, 10, 17, 2, 0, 0, 0, time.UTC)},
+		{"cron(45 14 * * ? *)", time.Date(2026, 10, 16, 14, 45, 0, 0, time.UTC)},
+		{"cron(30 14 * * ? *)", time.Date(2026, 10, 17, 14, 30, 0, 0, time.UTC)},
+		{"cron(0 9 ? * MON-FRI *)", time.Date(2026, 10, 19, 9, 0, 0, 0, time.UTC)},
+		{"cron(0 0 L * ? *)", time.Date(2026, 10, 31, 0, 0, 0, 0, time.UTC)},
+		{"cron(0 0 LW * ? *)", time.Date(2026, 10, 30, 0, 0, 0, 0, time.UTC)},
+		{"cron(0 0 1W * ? *)", time.Date(2026, 11, 2, 0, 0, 0, 0, time.UTC)},
+		{"cron(0 0 ? * 6L *)", time.Date(2026, 10, 30, 0, 0, 0, 0, time.UTC)},
+		{"cron(0 0 ? * 3#1 *)", time.Date(2026, 11, 3, 0, 0, 0, 0, time.UTC)},
+		{"cron(0 0 1 JAN ? 2027)", time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{"cron(0 0 1 JAN ? 2020)", time.Time{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			s, err := parseSchedule(tt.expr)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if got := s.next(now, created); !got.Equal(tt.want) {
+				t.Errorf("next() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_nextRun(t *testing.T) {
+	now := time.Date(2026, 10, 16, 14, 30, 0, 0, time.UTC)
+	want := time.Date(2026, 10, 17, 2, 0, 0, 0, time.UTC)
+
+	if got := nextRun(&datasync.DescribeTaskOutput{}, now); got != nil {
+		t.Errorf("expected nil next run without a schedule, got %s", got)
+	}
+
+	if got := nextRun(&datasync.DescribeTaskOutput{Schedule: &datasync.TaskSchedule{ScheduleExpression: aws.String("bad")}}, now); got != nil {
+		t.Errorf("expected nil next run for an invalid schedule, got %s", got)
+	}
+
+	got := nextRun(&datasync.DescribeTaskOutput{Schedule: &datasync.TaskSchedule{ScheduleExpression: aws.String("cron(0 2 * * ? *)")}}, now)
+	if got == nil || !got.Equal(want) {
+		t.Errorf("nextRun() = %v, want %s", got, want)
+	}
+}
@@ -23,11 +23,14 @@ import (
 	"math/rand"
 	"net/http"
 	"os"
+	"sync"
 	"time"
 
+	"github.com/YaleSpinup/apierror"
 	"github.com/YaleSpinup/datasync-api/common"
 	"github.com/YaleSpinup/datasync-api/iam"
 	"github.com/YaleSpinup/datasync-api/session"
+	"github.com/YaleSpinup/datasync-api/sqs"
 	"github.com/YaleSpinup/flywheel"
 	"github.com/gorilla/handlers"
 	"github.com/gorilla/mux"
@@ -56,9 +59,24 @@ type server struct {
 	context      context.Context
 	session      session.Session
 	sessionCache *cache.Cache
-	flywheel     *flywheel.Manager
-	orgPolicy    string
-	org          string
+	// store is shared by all instances of the API, it keeps the mover names reserved by in-flight
+	// creates and maps Idempotency-Keys to the flywheel tasks they started
+	store sharedStore
+	// taskIndex maps account/region/group to the names, ARNs and tags of the datasync tasks in the group
+	taskIndex *cache.Cache
+	// finishedRuns maps task execution ARNs to the descriptions of finished runs
+	finishedRuns *cache.Cache
+	// runWatchers polls the task executions streamed to run event subscribers
+	runWatchers *runWatchers
+	// notifier notifies the webhooks of the movers when their runs finish, it's nil when webhooks aren't configured
+	notifier *notifier
+	// accounts maps account IDs and aliases to the configured member accounts
+	accounts map[string]*accountConfig
+	// defaultAccount is the configuration of all accounts when no accounts are configured
+	defaultAccount accountConfig
+	flywheel       *flywheel.Manager
+	orgPolicy      string
+	org            string
 }
 
 // NewServer creates a new server and starts it
@@ -72,10 +90,14 @@ func NewServer(config common.Config) error {
 	}
 
 	s := server{
-		router:       mux.NewRouter(),
-		context:      ctx,
-		org:          config.Org,
-		sessionCache: cache.New(600*time.Second, 900*time.Second),
+		router:         mux.NewRouter(),
+		context:        ctx,
+		org:            config.Org,
+		sessionCache:   cache.New(600*time.Second, 900*time.Second),
+		taskIndex:      cache.New(taskIndexTTL, 2*taskIndexTTL),
+		finishedRuns:   cache.New(finishedRunTTL, time.Hour),
+		runWatchers:    newRunWatchers(),
+		defaultAccount: defaultAccountConfig(config.Account),
 	}
 
 	s.version = &apiVersion{
@@ -84,6 +106,16 @@ func NewServer(config common.Config) error {
 		BuildStamp: config.Version.BuildStamp,
 	}
 
+	accounts, err := newAccountConfigs(config)
+	if err != nil {
+		return err
+	}
+	s.accounts = accounts
+
+	if len(accounts) == 0 {
+		log.Warn("no accounts are configured, any account can be managed with the default role")
+	}
+
 	orgPolicy, err := orgTagAccessPolicy(config.Org)
 	if err != nil {
 		return err
@@ -96,6 +128,19 @@ func NewServer(config common.Config) error {
 	}
 	s.flywheel = manager
 
+	store, err := newRedisStore(config.Flywheel)
+	if err != nil {
+		return err
+	}
+	s.store = store
+
+	if config.Webhooks.Secret != "" {
+		s.notifier = newNotifier(ctx, config.Webhooks.Secret, s.store, s.runWatchers, s.newReadOnlyOrchestrator)
+		go s.notifier.run(ctx)
+	} else {
+		log.Warn("webhooks secret is not configured, run notifications are disabled")
+	}
+
 	// Create a new session used for authentication and assuming cross account roles
 	log.Debugf("Creating new session with key '%s' in region '%s'", config.Account.Akid, config.Account.Region)
 	s.session = session.New(
@@ -105,6 +150,18 @@ func NewServer(config common.Config) error {
 		session.WithExternalRoleName(config.Account.Role),
 	)
 
+	// movers with webhooks are watched again after a restart
+	if s.notifier != nil {
+		go func() {
+			s.notifier.restore(ctx, s.webhookAccounts(ctx))
+		}()
+	}
+
+	if config.Events.QueueUrl != "" {
+		queue := sqs.New(sqs.WithSession(s.session.Session), sqs.WithQueueUrl(config.Events.QueueUrl))
+		go newEventConsumer(&s, &queue, s.newReadOnlyOrchestrator).run(ctx)
+	}
+
 	publicURLs := map[string]string{
 		"/v1/datasync/ping":    "public",
 		"/v1/datasync/version": "public",
@@ -133,11 +190,41 @@ func NewServer(config common.Config) error {
 	return nil
 }
 
+// moverRegions returns the regions movers are managed in from the account configuration, the
+// default region (us-east-1 when it isn't configured) comes first
+func moverRegions(account common.Account) []string {
+	def := account.Region
+	if def == "" {
+		def = "us-east-1"
+	}
+
+	return uniqueRegions(append([]string{def}, account.Regions...))
+}
+
+// uniqueRegions returns the regions without duplicates or empty regions, in order
+func uniqueRegions(regions []string) []string {
+	unique := []string{}
+	seen := map[string]bool{}
+	for _, r := range regions {
+		if r != "" && !seen[r] {
+			unique = append(unique, r)
+			seen[r] = true
+		}
+	}
+
+	return unique
+}
+
 // LogWriter is an http.ResponseWriter
 type LogWriter struct {
 	http.ResponseWriter
 }
 
+// Unwrap returns the underlying http.ResponseWriter, so http.ResponseController can flush streamed responses
+func (w LogWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
 // Write log message if http response writer returns an error
 func (w LogWriter) Write(p []byte) (n int, err error) {
 	n, err = w.ResponseWriter.Write(p)
@@ -214,6 +301,66 @@ func retry(attempts int, doubling int, sleep time.Duration, f func() error) erro
 	return nil
 }
 
+// retryTransient retries f a few times when it fails with a transient error (throttling, service or
+// internal errors), other errors are returned right away
+func retryTransient(f func() error) error {
+	return retry(4, 2, 2*time.Second, func() error {
+		err := f()
+		if err == nil {
+			return nil
+		}
+
+		if aerr, ok := err.(apierror.Error); ok {
+			switch aerr.Code {
+			case apierror.ErrLimitExceeded, apierror.ErrServiceUnavailable, apierror.ErrInternalError:
+				log.Warnf("retrying after transient error: %s", err)
+				return err
+			}
+		}
+
+		return stop{err}
+	})
+}
+
+// ignoreNotFound returns nil for not found errors, for deleting resources that may already be gone
+func ignoreNotFound(err error) error {
+	if aerr, ok := err.(apierror.Error); ok && aerr.Code == apierror.ErrNotFound {
+		log.Debugf("ignoring not found error: %s", err)
+		return nil
+	}
+
+	return err
+}
+
+// concurrently calls f for each index up to count, running at most limit calls at a time,
+// and returns the first error in index order
+func concurrently(count, limit int, f func(i int) error) error {
+	errs := make([]error, count)
+
+	sem := make(chan struct{}, limit)
+	var wg sync.WaitGroup
+	for i := 0; i < count; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			errs[i] = f(i)
+		}(i)
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 // orgTagAccessPolicy generates the org tag conditional policy to be passed inline when assuming a role
 func orgTagAccessPolicy(org string) (string, error) {
 	log.Debugf("generating org policy document")
@@ -4,8 +4,12 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"reflect"
 	"testing"
 	"time"
+
+	"github.com/YaleSpinup/apierror"
+	"github.com/YaleSpinup/datasync-api/common"
 )
 
 func TestRollback(t *testing.T) {
@@ -79,3 +83,66 @@ func TestRetry(t *testing.T) {
 		t.Errorf("unexpected error for successful retry, got %s", err)
 	}
 }
+
+func TestRetryTransient(t *testing.T) {
+	calls := 0
+	if err := retryTransient(func() error {
+		calls++
+		return apierror.New(apierror.ErrBadRequest, "boom", nil)
+	}); err == nil {
+		t.Error("expected error for bad request, got nil")
+	}
+
+	if calls != 1 {
+		t.Errorf("expected 1 call for a non-transient error, got %d", calls)
+	}
+
+	calls = 0
+	if err := retryTransient(func() error {
+		calls++
+		if calls == 1 {
+			return apierror.New(apierror.ErrLimitExceeded, "slow down", nil)
+		}
+		return nil
+	}); err != nil {
+		t.Errorf("unexpected error for successful retry, got %s", err)
+	}
+
+	if calls != 2 {
+		t.Errorf("expected 2 calls for a transient error, got %d", calls)
+	}
+}
+
+func TestIgnoreNotFound(t *testing.T) {
+	if err := ignoreNotFound(nil); err != nil {
+		t.Errorf("expected nil error, got %s", err)
+	}
+
+	if err := ignoreNotFound(apierror.New(apierror.ErrNotFound, "gone", nil)); err != nil {
+		t.Errorf("expected nil error for not found, got %s", err)
+	}
+
+	if err := ignoreNotFound(apierror.New(apierror.ErrForbidden, "nope", nil)); err == nil {
+		t.Error("expected error for forbidden, got nil")
+	}
+}
+
+func TestMoverRegions(t *testing.T) {
+	tests := []struct {
+		name    string
+		account common.Account
+		want    []string
+	}{
+		{"defaults", common.Account{}, []string{"us-east-1"}},
+		{"region", common.Account{Region: "us-west-2"}, []string{"us-west-2"}},
+		{"default first", common.Account{Region: "us-west-2", Regions: []string{"us-east-1", "us-west-2", "", "us-east-1"}}, []string{"us-west-2", "us-east-1"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := moverRegions(tt.account); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("moverRegions() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
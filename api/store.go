@@ -0,0 +1,84 @@
+package api
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/YaleSpinup/datasync-api/common"
+	"github.com/go-redis/redis/v8"
+)
+
+// sharedStore is a key value store shared by all instances of the API, so requests handled by different
+// instances see the same mover name reservations and Idempotency-Keys, and webhooks are only notified once
+type sharedStore interface {
+	// setNX sets the key if it doesn't exist and returns whether it was set
+	setNX(ctx context.Context, key, value string, ttl time.Duration) (bool, error)
+	// get returns the value of the key and whether the key exists
+	get(ctx context.Context, key string) (string, bool, error)
+	set(ctx context.Context, key, value string, ttl time.Duration) error
+	del(ctx context.Context, key string) error
+	// sadd adds a member to the set stored at the key
+	sadd(ctx context.Context, key, member string) error
+	// smembers returns the members of the set stored at the key
+	smembers(ctx context.Context, key string) ([]string, error)
+}
+
+// redisStore is a sharedStore in the flywheel redis, keys are prefixed with the flywheel namespace
+type redisStore struct {
+	client *redis.Client
+	prefix string
+}
+
+// newRedisStore returns a sharedStore using the redis configured for flywheel
+func newRedisStore(config common.Flywheel) (*redisStore, error) {
+	opts := &redis.Options{
+		Addr:     config.RedisAddress,
+		Username: config.RedisUsername,
+		Password: config.RedisPassword,
+	}
+
+	if config.RedisDatabase != "" {
+		db, err := strconv.Atoi(config.RedisDatabase)
+		if err != nil {
+			return nil, err
+		}
+		opts.DB = db
+	}
+
+	return &redisStore{
+		client: redis.NewClient(opts),
+		prefix: config.Namespace + ":",
+	}, nil
+}
+
+func (r *redisStore) setNX(ctx context.Context, key, value string, ttl time.Duration) (bool, error) {
+	return r.client.SetNX(ctx, r.prefix+key, value, ttl).Result()
+}
+
+func (r *redisStore) get(ctx context.Context, key string) (string, bool, error) {
+	v, err := r.client.Get(ctx, r.prefix+key).Result()
+	if err == redis.Nil {
+		return "", false, nil
+	} else if err != nil {
+		return "", false, err
+	}
+
+	return v, true, nil
+}
+
+func (r *redisStore) set(ctx context.Context, key, value string, ttl time.Duration) error {
+	return r.client.Set(ctx, r.prefix+key, value, ttl).Err()
+}
+
+func (r *redisStore) del(ctx context.Context, key string) error {
+	return r.client.Del(ctx, r.prefix+key).Err()
+}
+
+func (r *redisStore) sadd(ctx context.Context, key, member string) error {
+	return r.client.SAdd(ctx, r.prefix+key, member).Err()
+}
+
+func (r *redisStore) smembers(ctx context.Context, key string) ([]string, error) {
+	return r.client.SMembers(ctx, r.prefix+key).Result()
+}
@@ -0,0 +1,95 @@
+package api
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/YaleSpinup/datasync-api/common"
+	"github.com/patrickmn/go-cache"
+)
+
+// mockStore is a sharedStore in memory
+type mockStore struct {
+	cache *cache.Cache
+
+	mu   sync.Mutex
+	sets map[string]map[string]bool
+}
+
+func newMockStore() *mockStore {
+	return &mockStore{cache: cache.New(time.Hour, time.Hour), sets: map[string]map[string]bool{}}
+}
+
+func (m *mockStore) setNX(ctx context.Context, key, value string, ttl time.Duration) (bool, error) {
+	return m.cache.Add(key, value, ttl) == nil, nil
+}
+
+func (m *mockStore) get(ctx context.Context, key string) (string, bool, error) {
+	v, ok := m.cache.Get(key)
+	if !ok {
+		return "", false, nil
+	}
+
+	return v.(string), true, nil
+}
+
+func (m *mockStore) set(ctx context.Context, key, value string, ttl time.Duration) error {
+	m.cache.Set(key, value, ttl)
+	return nil
+}
+
+func (m *mockStore) del(ctx context.Context, key string) error {
+	m.cache.Delete(key)
+	return nil
+}
+
+func (m *mockStore) sadd(ctx context.Context, key, member string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.sets[key] == nil {
+		m.sets[key] = map[string]bool{}
+	}
+	m.sets[key][member] = true
+
+	return nil
+}
+
+func (m *mockStore) smembers(ctx context.Context, key string) ([]string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	members := []string{}
+	for member := range m.sets[key] {
+		members = append(members, member)
+	}
+	sort.Strings(members)
+
+	return members, nil
+}
+
+func TestNewRedisStore(t *testing.T) {
+	store, err := newRedisStore(common.Flywheel{
+		Namespace:     "datasync-api",
+		RedisAddress:  "redis:6379",
+		RedisDatabase: "2",
+	})
+	if err != nil {
+		t.Fatalf("expected nil error, got %s", err)
+	}
+
+	if store.prefix != "datasync-api:" {
+		t.Errorf("expected prefix datasync-api:, got %s", store.prefix)
+	}
+
+	if opts := store.client.Options(); opts.Addr != "redis:6379" || opts.DB != 2 {
+		t.Errorf("expected redis:6379 database 2, got %s database %d", opts.Addr, opts.DB)
+	}
+
+	if _, err := newRedisStore(common.Flywheel{RedisDatabase: "two"}); err == nil {
+		t.Error("expected error for invalid database, got nil")
+	}
+}
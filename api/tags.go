@@ -71,6 +71,62 @@ func (tags *Tags) normalize(org, group string) Tags {
 	return normalizedTags
 }
 
+// isProtectedTag returns true for tags managed by spinup or AWS, which cannot be changed by users
+func isProtectedTag(key string) bool {
+	return strings.HasPrefix(key, "spinup:") || strings.HasPrefix(key, "aws:")
+}
+
+// diff returns the tags to add or update and the keys to remove to change the user tags in current
+// to match our tags.  Protected spinup:* and aws:* tags are never added, changed or removed.
+func (tags *Tags) diff(current Tags) (Tags, []string) {
+	existing := map[string]string{}
+	for _, t := range current {
+		existing[t.Key] = t.Value
+	}
+
+	wanted := map[string]bool{}
+	add := Tags{}
+	for _, t := range *tags {
+		if isProtectedTag(t.Key) {
+			continue
+		}
+
+		wanted[t.Key] = true
+		if v, ok := existing[t.Key]; !ok || v != t.Value {
+			add = append(add, t)
+		}
+	}
+
+	remove := []string{}
+	for _, t := range current {
+		if !isProtectedTag(t.Key) && !wanted[t.Key] {
+			remove = append(remove, t.Key)
+		}
+	}
+
+	return add, remove
+}
+
+// apply returns a copy of our tags with the given tags added or updated and the given keys removed
+func (tags *Tags) apply(add Tags, remove []string) Tags {
+	skip := map[string]bool{}
+	for _, k := range remove {
+		skip[k] = true
+	}
+	for _, t := range add {
+		skip[t.Key] = true
+	}
+
+	out := Tags{}
+	for _, t := range *tags {
+		if !skip[t.Key] {
+			out = append(out, t)
+		}
+	}
+
+	return append(out, add...)
+}
+
 // toDatasyncTags converts from api Tags to DataSync tags
 func (tags *Tags) toDatasyncTags() []*datasync.TagListEntry {
 	datasyncTags := make([]*datasync.TagListEntry, 0, len(*tags))
@@ -83,6 +139,15 @@ func (tags *Tags) toDatasyncTags() []*datasync.TagListEntry {
 	return datasyncTags
 }
 
+// toCloudWatchLogsTags converts from api Tags to CloudWatch Logs tags
+func (tags *Tags) toCloudWatchLogsTags() map[string]*string {
+	logsTags := make(map[string]*string, len(*tags))
+	for _, t := range *tags {
+		logsTags[t.Key] = aws.String(t.Value)
+	}
+	return logsTags
+}
+
 // fromDatasyncTags converts from DocDB tags to api Tags
 func fromDatasyncTags(datasyncTags []*datasync.TagListEntry) Tags {
 	tags := make(Tags, 0, len(datasyncTags))
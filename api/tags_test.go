@@ -254,3 +254,54 @@ func Test_tags_normalize(t *testing.T) {
 		})
 	}
 }
+
+func Test_tags_diff(t *testing.T) {
+	current := Tags{
+		{Key: "spinup:org", Value: "spindev"},
+		{Key: "spinup:spaceid", Value: "abc-123"},
+		{Key: "aws:cloudformation:stack-name", Value: "stack"},
+		{Key: "env", Value: "dev"},
+		{Key: "owner", Value: "hiro"},
+		{Key: "project", Value: "metaverse"},
+	}
+
+	tags := Tags{
+		{Key: "spinup:org", Value: "fedland"},
+		{Key: "spinup:other", Value: "foo"},
+		{Key: "aws:foo", Value: "bar"},
+		{Key: "env", Value: "prod"},
+		{Key: "owner", Value: "hiro"},
+		{Key: "cost-center", Value: "1234"},
+	}
+
+	add, remove := tags.diff(current)
+
+	wantAdd := Tags{
+		{Key: "env", Value: "prod"},
+		{Key: "cost-center", Value: "1234"},
+	}
+	if !reflect.DeepEqual(add, wantAdd) {
+		t.Errorf("expected tags to add %+v, got %+v", wantAdd, add)
+	}
+
+	wantRemove := []string{"project"}
+	if !reflect.DeepEqual(remove, wantRemove) {
+		t.Errorf("expected keys to remove %+v, got %+v", wantRemove, remove)
+	}
+
+	// reverting the change
+	restore, restoreKeys := current.diff(current.apply(add, remove))
+
+	wantRestore := Tags{
+		{Key: "env", Value: "dev"},
+		{Key: "project", Value: "metaverse"},
+	}
+	if !reflect.DeepEqual(restore, wantRestore) {
+		t.Errorf("expected tags to restore %+v, got %+v", wantRestore, restore)
+	}
+
+	wantRestoreKeys := []string{"cost-center"}
+	if !reflect.DeepEqual(restoreKeys, wantRestoreKeys) {
+		t.Errorf("expected keys to remove %+v, got %+v", wantRestoreKeys, restoreKeys)
+	}
+}
@@ -0,0 +1,253 @@
+package api
+
+import (
+	"context"
+	"time"
+
+	"github.com/YaleSpinup/apierror"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/datasync"
+	"github.com/aws/aws-sdk-go/service/resourcegroupstaggingapi"
+	"github.com/patrickmn/go-cache"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// taskIndexTTL is how long the tasks in a group are cached, it limits how stale the index can
+	// be when movers are changed outside of this API
+	taskIndexTTL = 5 * time.Minute
+	// taskDescribeConcurrency is the maximum number of concurrent DescribeTask calls when filling the index
+	taskDescribeConcurrency = 10
+)
+
+// taskIndexLookups counts the task index lookups by result (hit or miss)
+var taskIndexLookups = promauto.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "datasync_api",
+	Name:      "task_index_lookups_total",
+	Help:      "Number of lookups in the datasync task name index, by result (hit or miss).",
+}, []string{"result"})
+
+var (
+	// groupMovers is the number of movers in each group, as of the last time the group was indexed
+	groupMovers = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "datasync_api",
+		Name:      "movers",
+		Help:      "Number of data movers in a group, as of the last time the group was indexed.",
+	}, []string{"account", "region", "group"})
+
+	// groupRunsInProgress is the number of running movers in each group, as of the last time the group was indexed
+	groupRunsInProgress = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "datasync_api",
+		Name:      "runs_in_progress",
+		Help:      "Number of data mover runs in progress in a group, as of the last time the group was indexed.",
+	}, []string{"account", "region", "group"})
+)
+
+// taskIndexEntry is a datasync task in the index
+type taskIndexEntry struct {
+	Name   string
+	Group  string
+	Region string
+	Arn    string
+	Tags   Tags
+	// Status is the task status when it was indexed, it's only used for the group metrics
+	Status string
+}
+
+// taskIndexKey returns the cache key of the tasks in a group of an account in a region
+func taskIndexKey(account, region, group string) string {
+	return account + "/" + region + "/" + group
+}
+
+// invalidateTaskIndex forgets the tasks in a group, it's called when movers are created, updated or deleted
+func (s *server) invalidateTaskIndex(account, region, group string) {
+	if s.taskIndex == nil {
+		return
+	}
+
+	log.Debugf("invalidating task index for %s", taskIndexKey(account, region, group))
+
+	s.taskIndex.Delete(taskIndexKey(account, region, group))
+}
+
+// groupTasks returns the datasync tasks in a group from the index.  On a miss, the tasks are listed
+// with the resource groups tagging api and described concurrently to find their names.
+func (o *datasyncOrchestrator) groupTasks(ctx context.Context, group string) ([]*taskIndexEntry, error) {
+	if entries, ok := o.indexedTasks(group); ok {
+		return entries, nil
+	}
+
+	out, err := o.rgClient.GetResourcesWithTags(ctx, []string{"datasync:task"}, o.moverTagFilters(group))
+	if err != nil {
+		return nil, err
+	}
+
+	return o.indexTasks(ctx, group, out)
+}
+
+// allTasks returns the datasync tasks in all groups.  The tasks are split by group, so groups that
+// are already indexed don't need to be described.
+func (o *datasyncOrchestrator) allTasks(ctx context.Context) ([]*taskIndexEntry, error) {
+	out, err := o.rgClient.GetResourcesWithTags(ctx, []string{"datasync:task"}, o.moverTagFilters(""))
+	if err != nil {
+		return nil, err
+	}
+
+	groups := []string{}
+	resources := map[string][]*resourcegroupstaggingapi.ResourceTagMapping{}
+	for _, r := range out {
+		g := ""
+		for _, t := range r.Tags {
+			if aws.StringValue(t.Key) == "spinup:spaceid" {
+				g = aws.StringValue(t.Value)
+			}
+		}
+
+		if _, ok := resources[g]; !ok {
+			groups = append(groups, g)
+		}
+		resources[g] = append(resources[g], r)
+	}
+
+	all := make([]*taskIndexEntry, 0, len(out))
+	for _, g := range groups {
+		var entries []*taskIndexEntry
+		if g == "" {
+			// tasks without a group can't be indexed
+			entries, err = o.describeTaskEntries(ctx, resources[g])
+		} else if indexed, ok := o.indexedTasks(g); ok {
+			entries = indexed
+		} else {
+			entries, err = o.indexTasks(ctx, g, resources[g])
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		all = append(all, entries...)
+	}
+
+	return all, nil
+}
+
+// indexedTasks returns the tasks in a group if they're in the index
+func (o *datasyncOrchestrator) indexedTasks(group string) ([]*taskIndexEntry, bool) {
+	if o.server.taskIndex != nil {
+		if v, ok := o.server.taskIndex.Get(taskIndexKey(o.account, o.region, group)); ok {
+			if entries, ok := v.([]*taskIndexEntry); ok {
+				taskIndexLookups.WithLabelValues("hit").Inc()
+				return entries, true
+			}
+		}
+	}
+
+	taskIndexLookups.WithLabelValues("miss").Inc()
+
+	return nil, false
+}
+
+// indexTasks describes the tasks listed in a group and adds them to the index
+func (o *datasyncOrchestrator) indexTasks(ctx context.Context, group string, resources []*resourcegroupstaggingapi.ResourceTagMapping) ([]*taskIndexEntry, error) {
+	log.Debugf("indexing %d tasks for %s", len(resources), taskIndexKey(o.account, o.region, group))
+
+	entries, err := o.describeTaskEntries(ctx, resources)
+	if err != nil {
+		return nil, err
+	}
+
+	if o.server.taskIndex != nil {
+		o.server.taskIndex.Set(taskIndexKey(o.account, o.region, group), entries, cache.DefaultExpiration)
+	}
+
+	running := 0
+	for _, e := range entries {
+		if e.Status == datasync.TaskStatusRunning {
+			running++
+		}
+	}
+	groupMovers.WithLabelValues(o.account, o.region, group).Set(float64(len(entries)))
+	groupRunsInProgress.WithLabelValues(o.account, o.region, group).Set(float64(running))
+
+	return entries, nil
+}
+
+// describeTaskEntries describes the tasks to find their names and statuses, with at most
+// taskDescribeConcurrency DescribeTask calls at a time
+func (o *datasyncOrchestrator) describeTaskEntries(ctx context.Context, resources []*resourcegroupstaggingapi.ResourceTagMapping) ([]*taskIndexEntry, error) {
+	entries := make([]*taskIndexEntry, len(resources))
+	if err := concurrently(len(resources), taskDescribeConcurrency, func(i int) error {
+		r := resources[i]
+
+		task, err := o.datasyncClient.DescribeDatasyncTask(ctx, aws.StringValue(r.ResourceARN))
+		if err != nil {
+			return err
+		}
+
+		if task.Name == nil {
+			return apierror.New(apierror.ErrInternalError, "unable to determine datamover name", nil)
+		}
+
+		tags := fromResourcegroupstaggingapiTags(r.Tags)
+
+		group := ""
+		for _, t := range tags {
+			if t.Key == "spinup:spaceid" {
+				group = t.Value
+			}
+		}
+
+		entries[i] = &taskIndexEntry{
+			Name:   aws.StringValue(task.Name),
+			Group:  group,
+			Region: o.region,
+			Arn:    aws.StringValue(r.ResourceARN),
+			Tags:   tags,
+			Status: aws.StringValue(task.Status),
+		}
+
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
+
+// taskEntryFromName returns the index entry of a task by name.  A cached index may be missing movers
+// created elsewhere, so a name that isn't in the cached index is confirmed by reloading the group.
+func (o *datasyncOrchestrator) taskEntryFromName(ctx context.Context, group, name string) (*taskIndexEntry, error) {
+	if entries, ok := o.indexedTasks(group); ok {
+		if e := findTaskEntry(entries, name); e != nil {
+			return e, nil
+		}
+	}
+
+	out, err := o.rgClient.GetResourcesWithTags(ctx, []string{"datasync:task"}, o.moverTagFilters(group))
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := o.indexTasks(ctx, group, out)
+	if err != nil {
+		return nil, err
+	}
+
+	if e := findTaskEntry(entries, name); e != nil {
+		return e, nil
+	}
+
+	return nil, apierror.New(apierror.ErrNotFound, "datasync mover not found", nil)
+}
+
+// findTaskEntry returns the entry with the task name, or nil
+func findTaskEntry(entries []*taskIndexEntry, name string) *taskIndexEntry {
+	for _, e := range entries {
+		if e.Name == name {
+			return e
+		}
+	}
+
+	return nil
+}
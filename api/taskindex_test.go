@@ -0,0 +1,275 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/YaleSpinup/apierror"
+	yresourcegroupstaggingapi "github.com/YaleSpinup/aws-go/services/resourcegroupstaggingapi"
+	ydatasync "github.com/YaleSpinup/datasync-api/datasync"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/datasync"
+	"github.com/aws/aws-sdk-go/service/datasync/datasynciface"
+	"github.com/aws/aws-sdk-go/service/resourcegroupstaggingapi"
+	"github.com/aws/aws-sdk-go/service/resourcegroupstaggingapi/resourcegroupstaggingapiiface"
+	"github.com/patrickmn/go-cache"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+// mockIndexDataSync describes the tasks in names, tracking the number of (concurrent) calls
+type mockIndexDataSync struct {
+	datasynciface.DataSyncAPI
+	names map[string]string
+
+	mu        sync.Mutex
+	calls     int
+	running   int
+	maxActive int
+	execCalls int
+}
+
+func (d *mockIndexDataSync) DescribeTaskWithContext(ctx context.Context, input *datasync.DescribeTaskInput, opts ...request.Option) (*datasync.DescribeTaskOutput, error) {
+	d.mu.Lock()
+	d.calls++
+	d.running++
+	if d.running > d.maxActive {
+		d.maxActive = d.running
+	}
+	d.mu.Unlock()
+
+	time.Sleep(time.Millisecond)
+
+	d.mu.Lock()
+	d.running--
+	name, ok := d.names[aws.StringValue(input.TaskArn)]
+	d.mu.Unlock()
+
+	if !ok {
+		return nil, awserr.New(datasync.ErrCodeInvalidRequestException, "task not found", nil)
+	}
+
+	// even tasks are S3 to EFS and available, odd tasks are S3 to NFS and running
+	status, dst := datasync.TaskStatusAvailable, "loc-efs"
+	if strings.HasSuffix(aws.StringValue(input.TaskArn), "1") || strings.HasSuffix(aws.StringValue(input.TaskArn), "3") {
+		status, dst = datasync.TaskStatusRunning, "loc-nfs"
+	}
+
+	return &datasync.DescribeTaskOutput{
+		Name:                   aws.String(name),
+		TaskArn:                input.TaskArn,
+		Status:                 aws.String(status),
+		SourceLocationArn:      aws.String("loc-s3"),
+		DestinationLocationArn: aws.String(dst),
+	}, nil
+}
+
+func (d *mockIndexDataSync) ListLocationsPagesWithContext(ctx context.Context, input *datasync.ListLocationsInput, fn func(*datasync.ListLocationsOutput, bool) bool, opts ...request.Option) error {
+	fn(&datasync.ListLocationsOutput{
+		Locations: []*datasync.LocationListEntry{
+			{LocationArn: aws.String("loc-s3"), LocationUri: aws.String("s3://bucket1/")},
+			{LocationArn: aws.String("loc-efs"), LocationUri: aws.String("efs://us-east-1.fs-0123/")},
+			{LocationArn: aws.String("loc-nfs"), LocationUri: aws.String("nfs://10.0.0.1/")},
+		},
+	}, true)
+
+	return nil
+}
+
+func (d *mockIndexDataSync) ListTaskExecutionsPagesWithContext(ctx context.Context, input *datasync.ListTaskExecutionsInput, fn func(*datasync.ListTaskExecutionsOutput, bool) bool, opts ...request.Option) error {
+	fn(&datasync.ListTaskExecutionsOutput{
+		TaskExecutions: []*datasync.TaskExecutionListEntry{
+			{TaskExecutionArn: aws.String(aws.StringValue(input.TaskArn) + "/execution/exec-1"), Status: aws.String(datasync.TaskExecutionStatusError)},
+			{TaskExecutionArn: aws.String(aws.StringValue(input.TaskArn) + "/execution/exec-2"), Status: aws.String(datasync.TaskExecutionStatusSuccess)},
+			{TaskExecutionArn: aws.String(aws.StringValue(input.TaskArn) + "/execution/exec-3"), Status: aws.String(datasync.TaskExecutionStatusTransferring)},
+		},
+	}, true)
+
+	return nil
+}
+
+// mockRunStatus is the status of the executions returned by the mock, exec-n starts n hours after testTime
+var mockRunStatus = map[string]string{
+	"exec-1": datasync.TaskExecutionStatusError,
+	"exec-2": datasync.TaskExecutionStatusSuccess,
+	"exec-3": datasync.TaskExecutionStatusTransferring,
+}
+
+func (d *mockIndexDataSync) DescribeTaskExecutionWithContext(ctx context.Context, input *datasync.DescribeTaskExecutionInput, opts ...request.Option) (*datasync.DescribeTaskExecutionOutput, error) {
+	d.mu.Lock()
+	d.execCalls++
+	d.mu.Unlock()
+
+	id := aws.StringValue(input.TaskExecutionArn)
+	id = id[strings.LastIndex(id, "/")+1:]
+
+	hours, err := strconv.Atoi(strings.TrimPrefix(id, "exec-"))
+	if err != nil {
+		return nil, awserr.New(datasync.ErrCodeInvalidRequestException, "execution not found", nil)
+	}
+
+	out := &datasync.DescribeTaskExecutionOutput{
+		BytesTransferred: aws.Int64(int64(hours) * 1024),
+		FilesTransferred: aws.Int64(int64(hours)),
+		StartTime:        aws.Time(testTime.Add(time.Duration(hours) * time.Hour)),
+		Status:           aws.String(mockRunStatus[id]),
+		TaskExecutionArn: input.TaskExecutionArn,
+	}
+
+	if mockRunStatus[id] != datasync.TaskExecutionStatusTransferring {
+		out.Result = &datasync.TaskExecutionResultDetail{TotalDuration: aws.Int64(60000)}
+	}
+
+	return out, nil
+}
+
+// mockIndexRGClient returns a task for each of the names in the mock datasync client
+type mockIndexRGClient struct {
+	resourcegroupstaggingapiiface.ResourceGroupsTaggingAPIAPI
+	tasks []*resourcegroupstaggingapi.ResourceTagMapping
+}
+
+func (r *mockIndexRGClient) GetResourcesWithContext(ctx context.Context, input *resourcegroupstaggingapi.GetResourcesInput, opts ...request.Option) (*resourcegroupstaggingapi.GetResourcesOutput, error) {
+	return &resourcegroupstaggingapi.GetResourcesOutput{
+		PaginationToken:        aws.String(""),
+		ResourceTagMappingList: r.tasks,
+	}, nil
+}
+
+func newMockIndexOrchestrator(t *testing.T, count int) (*datasyncOrchestrator, *mockIndexDataSync) {
+	ds := &mockIndexDataSync{names: map[string]string{}}
+	rg := &mockIndexRGClient{}
+
+	for i := 0; i < count; i++ {
+		tArn := fmt.Sprintf("arn:aws:datasync:us-east-1:012345678901:task/task-%017d", i)
+		ds.names[tArn] = fmt.Sprintf("mover%d", i)
+		rg.tasks = append(rg.tasks, &resourcegroupstaggingapi.ResourceTagMapping{
+			ResourceARN: aws.String(tArn),
+			Tags: []*resourcegroupstaggingapi.Tag{
+				{Key: aws.String("spinup:spaceid"), Value: aws.String("group1")},
+			},
+		})
+	}
+
+	return &datasyncOrchestrator{
+		account:        "012345678901",
+		accountConfig:  &accountConfig{id: "012345678901", regions: []string{"us-east-1"}},
+		region:         "us-east-1",
+		server:         &server{org: "org", taskIndex: cache.New(taskIndexTTL, 2*taskIndexTTL)},
+		sp:             &sessionParams{},
+		datasyncClient: ydatasync.Datasync{Service: ds},
+		rgClient:       yresourcegroupstaggingapi.ResourceGroupsTaggingAPI{Service: rg},
+	}, ds
+}
+
+func Test_describeTaskEntries(t *testing.T) {
+	o, ds := newMockIndexOrchestrator(t, 50)
+
+	out, err := o.rgClient.GetResourcesWithTags(context.TODO(), []string{"datasync:task"}, o.moverTagFilters("group1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	entries, err := o.describeTaskEntries(context.TODO(), out)
+	if err != nil {
+		t.Fatalf("expected nil error, got %s", err)
+	}
+
+	// entries are returned in the order of the resources
+	for i, e := range entries {
+		assert.Equal(t, fmt.Sprintf("mover%d", i), e.Name)
+		assert.Equal(t, aws.StringValue(out[i].ResourceARN), e.Arn)
+		assert.Equal(t, Tags{{Key: "spinup:spaceid", Value: "group1"}}, e.Tags)
+	}
+
+	if ds.maxActive > taskDescribeConcurrency {
+		t.Errorf("expected at most %d concurrent describes, got %d", taskDescribeConcurrency, ds.maxActive)
+	}
+
+	// a task that can't be described fails the whole list
+	delete(ds.names, aws.StringValue(out[10].ResourceARN))
+	if _, err := o.describeTaskEntries(context.TODO(), out); err == nil {
+		t.Error("expected error, got nil")
+	}
+}
+
+func Test_taskDetailsFromNameIndex(t *testing.T) {
+	o, ds := newMockIndexOrchestrator(t, 20)
+
+	hits := testutil.ToFloat64(taskIndexLookups.WithLabelValues("hit"))
+	misses := testutil.ToFloat64(taskIndexLookups.WithLabelValues("miss"))
+
+	// the first lookup fills the index and describes the matching task again
+	task, _, err := o.taskDetailsFromName(context.TODO(), "group1", "mover5")
+	if err != nil {
+		t.Fatalf("expected nil error, got %s", err)
+	}
+	assert.Equal(t, "mover5", aws.StringValue(task.Name))
+	assert.Equal(t, 21, ds.calls)
+
+	// later lookups only describe the matching task
+	if _, _, err := o.taskDetailsFromName(context.TODO(), "group1", "mover7"); err != nil {
+		t.Fatalf("expected nil error, got %s", err)
+	}
+	assert.Equal(t, 22, ds.calls)
+
+	assert.Equal(t, hits+1, testutil.ToFloat64(taskIndexLookups.WithLabelValues("hit")))
+	assert.Equal(t, misses+1, testutil.ToFloat64(taskIndexLookups.WithLabelValues("miss")))
+
+	// a name that isn't indexed reloads the group before returning not found
+	_, _, err = o.taskDetailsFromName(context.TODO(), "group1", "missing")
+	if aerr, ok := err.(apierror.Error); !ok || aerr.Code != apierror.ErrNotFound {
+		t.Errorf("expected not found error, got %v", err)
+	}
+	assert.Equal(t, 42, ds.calls)
+
+	// invalidating the group reloads the index on the next lookup
+	o.server.invalidateTaskIndex(o.account, o.region, "group1")
+	if _, _, err := o.taskDetailsFromName(context.TODO(), "group1", "mover7"); err != nil {
+		t.Fatalf("expected nil error, got %s", err)
+	}
+	assert.Equal(t, 63, ds.calls)
+
+	// a renamed task isn't found by its old name
+	ds.names["arn:aws:datasync:us-east-1:012345678901:task/task-00000000000000003"] = "renamed"
+	_, _, err = o.taskDetailsFromName(context.TODO(), "group1", "mover3")
+	if aerr, ok := err.(apierror.Error); !ok || aerr.Code != apierror.ErrNotFound {
+		t.Errorf("expected not found error, got %v", err)
+	}
+
+	if _, ok := o.server.taskIndex.Get(taskIndexKey(o.account, o.region, "group1")); ok {
+		t.Error("expected the index to be invalidated after a stale lookup")
+	}
+}
+
+func Test_datamoverListIndex(t *testing.T) {
+	o, ds := newMockIndexOrchestrator(t, 5)
+
+	want := []string{"mover0", "mover1", "mover2", "mover3", "mover4"}
+
+	got, err := o.datamoverList(context.TODO(), "")
+	if err != nil {
+		t.Fatalf("expected nil error, got %s", err)
+	}
+	assert.Equal(t, want, got)
+	assert.Equal(t, 5, ds.calls)
+
+	// odd tasks are running
+	assert.Equal(t, float64(5), testutil.ToFloat64(groupMovers.WithLabelValues(o.account, o.region, "group1")))
+	assert.Equal(t, float64(2), testutil.ToFloat64(groupRunsInProgress.WithLabelValues(o.account, o.region, "group1")))
+
+	// the group was indexed by listing all movers
+	got, err = o.datamoverList(context.TODO(), "group1")
+	if err != nil {
+		t.Fatalf("expected nil error, got %s", err)
+	}
+	assert.Equal(t, want, got)
+	assert.Equal(t, 5, ds.calls)
+}
@@ -1,8 +1,10 @@
 package api
 
 import (
+	"strings"
 	"time"
 
+	yiam "github.com/YaleSpinup/aws-go/services/iam"
 	"github.com/aws/aws-sdk-go/service/datasync"
 )
 
@@ -11,15 +13,122 @@ type DatamoverCreateRequest struct {
 	Name        *string
 	Source      *DatamoverLocationInput
 	Destination *DatamoverLocationInput
-	Tags        Tags
+	Options     *DatamoverOptions
+	// BytesPerSecond limits the bandwidth used by the task, -1 is unlimited.  It's
+	// the same as passing Options.BytesPerSecond.
+	BytesPerSecond *int64
+	// Includes and Excludes are lists of filter patterns, ie. "/project1" or "*.tmp"
+	Includes []string
+	Excludes []string
+	// Schedule is a cron or rate expression, ie. "cron(0 2 * * ? *)" or "rate(12 hours)"
+	Schedule *string
+	// Logging creates a CloudWatch log group for the mover when it's passed
+	Logging *DatamoverLoggingInput
+	// Report configures DataSync task reports written to an S3 bucket when it's passed
+	Report *DatamoverReportInput
+	// Manifest limits the mover to the objects listed in a manifest when it's passed
+	Manifest *DatamoverManifestInput
+	Tags     Tags
+}
+
+// DatamoverManifestInput is a CSV manifest listing the exact files or objects to transfer
+// https://docs.aws.amazon.com/datasync/latest/userguide/transferring-with-manifest.html
+type DatamoverManifestInput struct {
+	// S3BucketArn is the bucket with the manifest, it must belong to the group
+	S3BucketArn *string
+	// ObjectPath is the key of the manifest object, ie. "manifests/2021-12-01.csv"
+	ObjectPath *string
+	// ObjectVersionId is the (optional) version of the manifest object
+	ObjectVersionId *string
+}
+
+// DatamoverLoggingInput is the configuration of the mover CloudWatch log group
+type DatamoverLoggingInput struct {
+	// RetentionInDays is the number of days to keep logs (default: 30)
+	RetentionInDays *int64
+}
+
+// DatamoverReportInput is the configuration of the mover task reports
+// https://docs.aws.amazon.com/datasync/latest/userguide/task-reports.html
+type DatamoverReportInput struct {
+	// S3BucketArn is the bucket the reports are written to
+	S3BucketArn *string
+	// Subdirectory is the prefix in the bucket for the reports
+	Subdirectory *string
+	// OutputType is one of SUMMARY_ONLY, STANDARD (default: STANDARD)
+	OutputType *string
+	// ReportLevel is one of ERRORS_ONLY, SUCCESSES_AND_ERRORS
+	ReportLevel *string
+	// ObjectVersionIds is one of INCLUDE, NONE
+	ObjectVersionIds *string
+}
+
+// DatamoverUpdateRequest is data used to update the configuration of a DataSync mover,
+// only the fields that are passed are changed
+type DatamoverUpdateRequest struct {
+	Name    *string
+	Options *DatamoverOptions
+	// BytesPerSecond limits the bandwidth used by the task, -1 is unlimited
+	BytesPerSecond *int64
+	// Schedule is a cron or rate expression, an empty string removes the schedule
+	Schedule *string
+	// Includes and Excludes replace the filter patterns, an empty list removes the filter
+	Includes []string
+	Excludes []string
+	// Tags replace the user tags on the mover, the spinup:* tags cannot be changed
+	Tags Tags
+}
+
+// DatamoverOptions are the options used when creating a DataSync task, any options not
+// set will use the DataSync defaults except where noted below
+// https://docs.aws.amazon.com/sdk-for-go/api/service/datasync/#Options
+type DatamoverOptions struct {
+	// Atime is one of BEST_EFFORT, NONE
+	Atime *string
+	// BytesPerSecond limits the bandwidth used by the task, -1 is unlimited
+	BytesPerSecond *int64
+	// Gid is one of INT_VALUE, NAME, BOTH, NONE
+	Gid *string
+	// LogLevel is one of OFF, BASIC, TRANSFER
+	LogLevel *string
+	// Mtime is one of PRESERVE, NONE
+	Mtime *string
+	// ObjectTags is one of PRESERVE, NONE
+	ObjectTags *string
+	// OverwriteMode is one of ALWAYS, NEVER
+	OverwriteMode *string
+	// PosixPermissions is one of PRESERVE, NONE
+	PosixPermissions *string
+	// PreserveDeletedFiles is one of PRESERVE, REMOVE (default: PRESERVE)
+	PreserveDeletedFiles *string
+	// PreserveDevices is one of NONE, PRESERVE
+	PreserveDevices *string
+	// SecurityDescriptorCopyFlags is one of NONE, OWNER_DACL, OWNER_DACL_SACL
+	SecurityDescriptorCopyFlags *string
+	// TaskQueueing is one of ENABLED, DISABLED
+	TaskQueueing *string
+	// TransferMode is one of CHANGED, ALL (default: CHANGED)
+	TransferMode *string
+	// Uid is one of INT_VALUE, NAME, BOTH, NONE
+	Uid *string
+	// VerifyMode is one of POINT_IN_TIME_CONSISTENT, ONLY_FILES_TRANSFERRED, NONE (default: ONLY_FILES_TRANSFERRED)
+	VerifyMode *string
 }
 
 // DatamoverLocationInput is an abstraction for the different location type inputs
-// currently only S3 and EFS are supported
+// currently S3, EFS, SMB, NFS, the FSx family, object storage and Azure Blob are supported
 type DatamoverLocationInput struct {
-	Type LocationType
-	S3   *DatamoverLocationS3Input
-	EFS  *DatamoverLocationEFSInput
+	Type          LocationType
+	S3            *DatamoverLocationS3Input
+	EFS           *DatamoverLocationEFSInput
+	SMB           *DatamoverLocationSMBInput
+	NFS           *DatamoverLocationNFSInput
+	FSxWindows    *DatamoverLocationFSxWindowsInput
+	FSxLustre     *DatamoverLocationFSxLustreInput
+	FSxOntap      *DatamoverLocationFSxOntapInput
+	FSxOpenZfs    *DatamoverLocationFSxOpenZfsInput
+	ObjectStorage *DatamoverLocationObjectStorageInput
+	AzureBlob     *DatamoverLocationAzureBlobInput
 }
 
 type DatamoverLocationS3Input struct {
@@ -37,35 +146,172 @@ type DatamoverLocationEFSInput struct {
 	Subdirectory      *string
 }
 
+type DatamoverLocationSMBInput struct {
+	// AgentArns are the DataSync agents that can connect to the SMB file server
+	AgentArns []*string
+	Domain    *string
+	// MountVersion is one of the following:
+	// AUTOMATIC, SMB2, SMB3, SMB1, SMB2_0
+	MountVersion   *string
+	Password       *string
+	ServerHostname *string
+	Subdirectory   *string
+	User           *string
+}
+
+type DatamoverLocationNFSInput struct {
+	// AgentArns are the DataSync agents that can connect to the NFS server
+	AgentArns []*string
+	// MountVersion is one of the following:
+	// AUTOMATIC, NFS3, NFS4_0, NFS4_1
+	MountVersion   *string
+	ServerHostname *string
+	Subdirectory   *string
+}
+
+type DatamoverLocationFSxWindowsInput struct {
+	Domain            *string
+	FsxFilesystemArn  *string
+	Password          *string
+	SecurityGroupArns []*string
+	Subdirectory      *string
+	User              *string
+}
+
+type DatamoverLocationFSxLustreInput struct {
+	FsxFilesystemArn  *string
+	SecurityGroupArns []*string
+	Subdirectory      *string
+}
+
+type DatamoverLocationFSxOntapInput struct {
+	// Protocol must set exactly one of NFS or SMB
+	Protocol                 *DatamoverFSxProtocolInput
+	SecurityGroupArns        []*string
+	StorageVirtualMachineArn *string
+	Subdirectory             *string
+}
+
+type DatamoverLocationFSxOpenZfsInput struct {
+	FsxFilesystemArn *string
+	// MountVersion is one of the following:
+	// AUTOMATIC, NFS3, NFS4_0, NFS4_1
+	MountVersion      *string
+	SecurityGroupArns []*string
+	Subdirectory      *string
+}
+
+// DatamoverFSxProtocolInput is the protocol used to access an FSx for ONTAP storage virtual machine
+type DatamoverFSxProtocolInput struct {
+	NFS *DatamoverFSxProtocolNFSInput
+	SMB *DatamoverFSxProtocolSMBInput
+}
+
+type DatamoverFSxProtocolNFSInput struct {
+	MountVersion *string
+}
+
+type DatamoverFSxProtocolSMBInput struct {
+	Domain       *string
+	MountVersion *string
+	Password     *string
+	User         *string
+}
+
+type DatamoverLocationObjectStorageInput struct {
+	// AccessKey and SecretKey are only required if the object storage server requires authentication
+	AccessKey  *string
+	AgentArns  []*string
+	BucketName *string
+	SecretKey  *string
+	// ServerCertificate is a PEM encoded certificate chain used to verify a self-signed or private CA
+	ServerCertificate *string
+	ServerHostname    *string
+	ServerPort        *int64
+	// ServerProtocol is one of the following:
+	// HTTPS, HTTP
+	ServerProtocol *string
+	Subdirectory   *string
+}
+
+type DatamoverLocationAzureBlobInput struct {
+	// AccessTier is one of the following:
+	// HOT, COOL, ARCHIVE
+	AccessTier *string
+	AgentArns  []*string
+	// BlobType is one of the following:
+	// BLOCK
+	BlobType     *string
+	ContainerUrl *string
+	// SasToken is the shared access signature token used to access the container
+	SasToken     *string
+	Subdirectory *string
+}
+
 type LocationType string
 
 const (
-	S3  LocationType = "S3"
-	EFS LocationType = "EFS"
-	SMB LocationType = "SMB"
-	NFS LocationType = "NFS"
+	S3            LocationType = "S3"
+	EFS           LocationType = "EFS"
+	SMB           LocationType = "SMB"
+	NFS           LocationType = "NFS"
+	FSxWindows    LocationType = "FSX_WINDOWS"
+	FSxLustre     LocationType = "FSX_LUSTRE"
+	FSxOntap      LocationType = "FSX_ONTAP"
+	FSxOpenZfs    LocationType = "FSX_OPENZFS"
+	ObjectStorage LocationType = "OBJECT_STORAGE"
+	AzureBlob     LocationType = "AZURE_BLOB"
 )
 
 func (lt LocationType) String() string {
 	return string(lt)
 }
 
+// locationTypeFromScheme maps the scheme of a DataSync location URI (ie. s3, efs, fsxw) to a LocationType
+func locationTypeFromScheme(scheme string) LocationType {
+	switch s := strings.ToLower(scheme); {
+	case s == "fsxw":
+		return FSxWindows
+	case s == "fsxl":
+		return FSxLustre
+	case strings.HasPrefix(s, "fsxn"):
+		// ONTAP locations are reported as fsxn-nfs or fsxn-smb depending on the protocol
+		return FSxOntap
+	case s == "fsxz":
+		return FSxOpenZfs
+	case s == "object-storage":
+		return ObjectStorage
+	case s == "azure-blob":
+		return AzureBlob
+	default:
+		return LocationType(strings.ToUpper(s))
+	}
+}
+
 // DatamoverResponse is the output from DataSync mover operations
 type DatamoverResponse struct {
 	// https://docs.aws.amazon.com/sdk-for-go/api/service/datasync/#DescribeTaskOutput
 	Task        *datasync.DescribeTaskOutput
 	Source      *DatamoverLocationOutput
 	Destination *DatamoverLocationOutput
-	Tags        Tags `json:",omitempty"`
+	// NextRun is the next time the mover is scheduled to run
+	NextRun *time.Time `json:",omitempty"`
+	Tags    Tags       `json:",omitempty"`
 }
 
 // DatamoverLocationOutput is an abstraction for the different location type outputs
 type DatamoverLocationOutput struct {
-	Type LocationType
-	S3   *datasync.DescribeLocationS3Output  `json:",omitempty"`
-	EFS  *datasync.DescribeLocationEfsOutput `json:",omitempty"`
-	SMB  *datasync.DescribeLocationSmbOutput `json:",omitempty"`
-	NFS  *datasync.DescribeLocationNfsOutput `json:",omitempty"`
+	Type          LocationType
+	S3            *datasync.DescribeLocationS3Output            `json:",omitempty"`
+	EFS           *datasync.DescribeLocationEfsOutput           `json:",omitempty"`
+	SMB           *datasync.DescribeLocationSmbOutput           `json:",omitempty"`
+	NFS           *datasync.DescribeLocationNfsOutput           `json:",omitempty"`
+	FSxWindows    *datasync.DescribeLocationFsxWindowsOutput    `json:",omitempty"`
+	FSxLustre     *datasync.DescribeLocationFsxLustreOutput     `json:",omitempty"`
+	FSxOntap      *datasync.DescribeLocationFsxOntapOutput      `json:",omitempty"`
+	FSxOpenZfs    *datasync.DescribeLocationFsxOpenZfsOutput    `json:",omitempty"`
+	ObjectStorage *datasync.DescribeLocationObjectStorageOutput `json:",omitempty"`
+	AzureBlob     *datasync.DescribeLocationAzureBlobOutput     `json:",omitempty"`
 }
 
 type DatamoverRun struct {
@@ -77,7 +323,194 @@ type DatamoverRun struct {
 	StartTime                *time.Time
 	Status                   *string
 	Result                   *datasync.TaskExecutionResultDetail
+	// BytesPerSecond is the current bandwidth limit of the run, -1 is unlimited
+	BytesPerSecond *int64
+}
+
+// DatamoverRunListInput is the filters, expansion and pagination of a data mover run list
+type DatamoverRunListInput struct {
+	// Expand returns a summary of each run instead of its id
+	Expand bool
+	// Status is the DataSync task execution status, ie. SUCCESS or ERROR
+	Status string
+	// Since and Until limit the runs by start time
+	Since *time.Time
+	Until *time.Time
+	// Limit is the maximum number of runs in a page, 0 returns all of the runs
+	Limit int
+	// Next is the continuation token returned with the previous page
+	Next string
 }
+
+// DatamoverRunSummary is a data mover run in an expanded list
+type DatamoverRunSummary struct {
+	Id               string
+	StartTime        *time.Time
+	Status           *string
+	BytesTransferred *int64
+	FilesTransferred *int64
+	// Duration is the total duration of a finished run in milliseconds
+	Duration *int64 `json:",omitempty"`
+}
+
+// DatamoverRunUpdateRequest is data used to update a running DataSync task execution
+type DatamoverRunUpdateRequest struct {
+	// BytesPerSecond limits the bandwidth used by the run, -1 is unlimited
+	BytesPerSecond *int64
+}
+
+// DatamoverRunLogs is a page of log events for a DataSync task execution
+type DatamoverRunLogs struct {
+	Events    []*DatamoverLogEvent
+	NextToken *string `json:",omitempty"`
+}
+
+type DatamoverLogEvent struct {
+	Timestamp time.Time
+	Message   string
+}
+
+// DatamoverWebhooksRequest is the list of webhook URLs notified when the runs of a data mover finish
+type DatamoverWebhooksRequest struct {
+	Urls []string
+}
+
+// DatamoverWebhooks is the webhooks of a data mover and the most recent deliveries, newest first
+type DatamoverWebhooks struct {
+	Urls       []string
+	Deliveries []*WebhookDelivery
+}
+
+// WebhookDelivery is an attempt to deliver a notification to a webhook
+type WebhookDelivery struct {
+	Id           string
+	Url          string
+	Event        string
+	RunId        string
+	Delivered    bool
+	Attempts     int
+	ResponseCode int    `json:",omitempty"`
+	Error        string `json:",omitempty"`
+	Time         time.Time
+}
+
+// WebhookPayload is the body of a notification sent to a webhook when a data mover run finishes
+type WebhookPayload struct {
+	// Event is run.succeeded or run.failed
+	Event            string
+	Account          string
+	Region           string
+	Group            string
+	Mover            string
+	RunId            string
+	Status           *string
+	StartTime        *time.Time
+	BytesTransferred *int64
+	FilesTransferred *int64
+	ErrorCode        *string
+	ErrorDetail      *string
+	Timestamp        time.Time
+}
+
+// DatamoverRunReport is a summary of the task report for a DataSync task execution
+type DatamoverRunReport struct {
+	// Status is the status of the report generation, one of PENDING, SUCCESS, ERROR
+	Status      *string
+	Transferred int64
+	Skipped     int64
+	Verified    int64
+	Deleted     int64
+	// Failed is the number of objects that failed to transfer, verify or delete
+	Failed int64
+	// Failures lists up to the first 100 failed objects
+	Failures []*DatamoverReportFailure
+	// Files are the keys of the report files in the report bucket
+	Files []string
+}
+
+// DatamoverReportFailure is an object that failed in a DataSync task execution
+type DatamoverReportFailure struct {
+	Category     string
+	RelativePath string
+	ErrorCode    string
+	ErrorDetail  string `json:",omitempty"`
+}
+
 type MoverUpdateAction struct {
 	State *string
+	DatamoverRunOverrides
+}
+
+// DatamoverRunOverrides are settings that override the mover configuration for a single run
+type DatamoverRunOverrides struct {
+	Includes []string
+	Excludes []string
+	// Manifest overrides the mover manifest for the run
+	Manifest *DatamoverManifestInput
+}
+
+// DatamoverListInput is the filters, expansion and pagination of a data mover list
+type DatamoverListInput struct {
+	// Expand returns a summary of each mover instead of its name
+	Expand bool
+	// Tags are the tags a mover must have, a tag with an empty value matches any value
+	Tags Tags
+	// Status is the DataSync task status, ie. AVAILABLE or RUNNING
+	Status string
+	// LocationType matches movers with a source or destination of the type
+	LocationType LocationType
+	// Limit is the maximum number of movers in a page, 0 returns all of the movers
+	Limit int
+	// Next is the cursor returned with the previous page
+	Next string
+}
+
+// DatamoverSummary is a data mover in an expanded list
+type DatamoverSummary struct {
+	Name            string
+	Group           string
+	Region          string
+	Status          *string
+	SourceType      LocationType
+	DestinationType LocationType
+	Schedule        *string    `json:",omitempty"`
+	NextRun         *time.Time `json:",omitempty"`
+	LastRunId       *string    `json:",omitempty"`
+	LastRunStatus   *string    `json:",omitempty"`
+	Tags            Tags       `json:",omitempty"`
+}
+
+// DatamoverPlan is the list of resources a data mover create request would create, update or reuse
+type DatamoverPlan struct {
+	Name      string
+	Group     string
+	Resources []*DatamoverPlanResource
+}
+
+// DatamoverPlanResource is a resource in a data mover plan
+type DatamoverPlanResource struct {
+	// Type is the kind of resource, ie. IAMRole, LogGroup, LogResourcePolicy, Location or Task
+	Type string
+	// Action is one of create, update (an existing resource is changed) or reuse
+	Action      string
+	Name        string
+	Description string
+	Arn         *string `json:",omitempty"`
+	// Policy is the inline policy of an IAM role
+	Policy *yiam.PolicyDocument `json:",omitempty"`
+}
+
+// AgentCreateRequest is data used to activate a DataSync agent
+type AgentCreateRequest struct {
+	// ActivationKey is retrieved from the agent after it's deployed
+	ActivationKey *string
+	Name          *string
+	Tags          Tags
+}
+
+// AgentResponse is the output from DataSync agent operations
+type AgentResponse struct {
+	// https://docs.aws.amazon.com/sdk-for-go/api/service/datasync/#DescribeAgentOutput
+	Agent *datasync.DescribeAgentOutput
+	Tags  Tags `json:",omitempty"`
 }
@@ -0,0 +1,25 @@
+package api
+
+import "testing"
+
+func Test_locationTypeFromScheme(t *testing.T) {
+	tests := map[string]LocationType{
+		"s3":             S3,
+		"efs":            EFS,
+		"smb":            SMB,
+		"nfs":            NFS,
+		"fsxw":           FSxWindows,
+		"fsxl":           FSxLustre,
+		"fsxn-nfs":       FSxOntap,
+		"fsxn-smb":       FSxOntap,
+		"fsxz":           FSxOpenZfs,
+		"object-storage": ObjectStorage,
+		"azure-blob":     AzureBlob,
+	}
+
+	for scheme, want := range tests {
+		if got := locationTypeFromScheme(scheme); got != want {
+			t.Errorf("locationTypeFromScheme(%s): expected %s, got %s", scheme, want, got)
+		}
+	}
+}
@@ -0,0 +1,630 @@
+package api
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/YaleSpinup/apierror"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/arn"
+	"github.com/aws/aws-sdk-go/service/datasync"
+)
+
+var moverNameRegex = regexp.MustCompile("^[a-zA-Z0-9-]+$")
+
+// validateMoverName validates the name of a mover
+func validateMoverName(name *string) error {
+	if name == nil {
+		return apierror.New(apierror.ErrBadRequest, "Name is a required field", nil)
+	}
+
+	if len(*name) > 40 {
+		return apierror.New(apierror.ErrBadRequest, "Name cannot exceed 40 characters ", nil)
+	}
+
+	if !moverNameRegex.MatchString(*name) {
+		return apierror.New(apierror.ErrBadRequest, "Name doesn't match regex "+moverNameRegex.String(), nil)
+	}
+
+	return nil
+}
+
+// validateLocationInput validates the required fields for the given location type
+func validateLocationInput(input *DatamoverLocationInput) error {
+	if input == nil {
+		return apierror.New(apierror.ErrBadRequest, "missing location input", nil)
+	}
+
+	switch input.Type {
+	case S3:
+		if input.S3 == nil {
+			return apierror.New(apierror.ErrBadRequest, "missing S3 location input", nil)
+		}
+
+		return validateArn("S3BucketArn", input.S3.S3BucketArn, "s3", "")
+	case EFS:
+		if input.EFS == nil {
+			return apierror.New(apierror.ErrBadRequest, "missing EFS location input", nil)
+		}
+
+		if err := validateArn("EfsFilesystemArn", input.EFS.EfsFilesystemArn, "elasticfilesystem", "file-system/"); err != nil {
+			return err
+		}
+
+		if err := validateSecurityGroupArns(input.EFS.SecurityGroupArns); err != nil {
+			return err
+		}
+
+		return validateArn("SubnetArn", input.EFS.SubnetArn, "ec2", "subnet/")
+	case SMB:
+		if input.SMB == nil {
+			return apierror.New(apierror.ErrBadRequest, "missing SMB location input", nil)
+		}
+
+		return validateAgentArns(input.SMB.AgentArns)
+	case NFS:
+		if input.NFS == nil {
+			return apierror.New(apierror.ErrBadRequest, "missing NFS location input", nil)
+		}
+
+		return validateAgentArns(input.NFS.AgentArns)
+	case FSxWindows:
+		if input.FSxWindows == nil {
+			return apierror.New(apierror.ErrBadRequest, "missing FSxWindows location input", nil)
+		}
+
+		if err := validateArn("FsxFilesystemArn", input.FSxWindows.FsxFilesystemArn, "fsx", "file-system/"); err != nil {
+			return err
+		}
+
+		return validateSecurityGroupArns(input.FSxWindows.SecurityGroupArns)
+	case FSxLustre:
+		if input.FSxLustre == nil {
+			return apierror.New(apierror.ErrBadRequest, "missing FSxLustre location input", nil)
+		}
+
+		if err := validateArn("FsxFilesystemArn", input.FSxLustre.FsxFilesystemArn, "fsx", "file-system/"); err != nil {
+			return err
+		}
+
+		return validateSecurityGroupArns(input.FSxLustre.SecurityGroupArns)
+	case FSxOntap:
+		if input.FSxOntap == nil {
+			return apierror.New(apierror.ErrBadRequest, "missing FSxOntap location input", nil)
+		}
+
+		if p := input.FSxOntap.Protocol; p == nil || (p.NFS == nil) == (p.SMB == nil) {
+			return apierror.New(apierror.ErrBadRequest, "exactly one of NFS or SMB is required for the FSxOntap protocol", nil)
+		}
+
+		if err := validateArn("StorageVirtualMachineArn", input.FSxOntap.StorageVirtualMachineArn, "fsx", "storage-virtual-machine/"); err != nil {
+			return err
+		}
+
+		return validateSecurityGroupArns(input.FSxOntap.SecurityGroupArns)
+	case FSxOpenZfs:
+		if input.FSxOpenZfs == nil {
+			return apierror.New(apierror.ErrBadRequest, "missing FSxOpenZfs location input", nil)
+		}
+
+		if err := validateArn("FsxFilesystemArn", input.FSxOpenZfs.FsxFilesystemArn, "fsx", "file-system/"); err != nil {
+			return err
+		}
+
+		return validateSecurityGroupArns(input.FSxOpenZfs.SecurityGroupArns)
+	case ObjectStorage:
+		if input.ObjectStorage == nil {
+			return apierror.New(apierror.ErrBadRequest, "missing ObjectStorage location input", nil)
+		}
+
+		if aws.StringValue(input.ObjectStorage.ServerHostname) == "" || aws.StringValue(input.ObjectStorage.BucketName) == "" {
+			return apierror.New(apierror.ErrBadRequest, "ServerHostname and BucketName are required for ObjectStorage", nil)
+		}
+
+		if (input.ObjectStorage.AccessKey == nil) != (input.ObjectStorage.SecretKey == nil) {
+			return apierror.New(apierror.ErrBadRequest, "AccessKey and SecretKey must be passed together for ObjectStorage", nil)
+		}
+
+		if p := input.ObjectStorage.ServerProtocol; p != nil && !validEnum(aws.StringValue(p), datasync.ObjectStorageServerProtocol_Values()) {
+			return apierror.New(apierror.ErrBadRequest, "invalid ServerProtocol "+aws.StringValue(p), nil)
+		}
+
+		return validateAgentArns(input.ObjectStorage.AgentArns)
+	case AzureBlob:
+		if input.AzureBlob == nil {
+			return apierror.New(apierror.ErrBadRequest, "missing AzureBlob location input", nil)
+		}
+
+		if aws.StringValue(input.AzureBlob.ContainerUrl) == "" || aws.StringValue(input.AzureBlob.SasToken) == "" {
+			return apierror.New(apierror.ErrBadRequest, "ContainerUrl and SasToken are required for AzureBlob", nil)
+		}
+
+		if t := input.AzureBlob.AccessTier; t != nil && !validEnum(aws.StringValue(t), datasync.AzureAccessTier_Values()) {
+			return apierror.New(apierror.ErrBadRequest, "invalid AccessTier "+aws.StringValue(t), nil)
+		}
+
+		if t := input.AzureBlob.BlobType; t != nil && !validEnum(aws.StringValue(t), datasync.AzureBlobType_Values()) {
+			return apierror.New(apierror.ErrBadRequest, "invalid BlobType "+aws.StringValue(t), nil)
+		}
+
+		return validateAgentArns(input.AzureBlob.AgentArns)
+	default:
+		return apierror.New(apierror.ErrBadRequest, "invalid location type "+input.Type.String(), nil)
+	}
+}
+
+// validateOptions validates the task option enum values and the combinations DataSync rejects,
+// logging is true when the mover has a CloudWatch log group
+func validateOptions(opts *DatamoverOptions, dst *DatamoverLocationInput, logging bool) error {
+	if opts == nil {
+		return nil
+	}
+
+	enums := []struct {
+		field string
+		value *string
+		valid []string
+	}{
+		{"Atime", opts.Atime, datasync.Atime_Values()},
+		{"Gid", opts.Gid, datasync.Gid_Values()},
+		{"LogLevel", opts.LogLevel, datasync.LogLevel_Values()},
+		{"Mtime", opts.Mtime, datasync.Mtime_Values()},
+		{"ObjectTags", opts.ObjectTags, datasync.ObjectTags_Values()},
+		{"OverwriteMode", opts.OverwriteMode, datasync.OverwriteMode_Values()},
+		{"PosixPermissions", opts.PosixPermissions, datasync.PosixPermissions_Values()},
+		{"PreserveDeletedFiles", opts.PreserveDeletedFiles, datasync.PreserveDeletedFiles_Values()},
+		{"PreserveDevices", opts.PreserveDevices, datasync.PreserveDevices_Values()},
+		{"SecurityDescriptorCopyFlags", opts.SecurityDescriptorCopyFlags, datasync.SmbSecurityDescriptorCopyFlags_Values()},
+		{"TaskQueueing", opts.TaskQueueing, datasync.TaskQueueing_Values()},
+		{"TransferMode", opts.TransferMode, datasync.TransferMode_Values()},
+		{"Uid", opts.Uid, datasync.Uid_Values()},
+		{"VerifyMode", opts.VerifyMode, datasync.VerifyMode_Values()},
+	}
+
+	for _, e := range enums {
+		if e.value != nil && !validEnum(aws.StringValue(e.value), e.valid) {
+			return apierror.New(apierror.ErrBadRequest, fmt.Sprintf("invalid %s %s, valid values are %s", e.field, aws.StringValue(e.value), strings.Join(e.valid, ", ")), nil)
+		}
+	}
+
+	if err := validateBandwidth(opts.BytesPerSecond); err != nil {
+		return err
+	}
+
+	// Atime defaults to BEST_EFFORT and Mtime defaults to PRESERVE, they must be set together
+	atime, mtime := aws.StringValue(opts.Atime), aws.StringValue(opts.Mtime)
+	if atime == "" {
+		atime = datasync.AtimeBestEffort
+	}
+	if mtime == "" {
+		mtime = datasync.MtimePreserve
+	}
+	if (atime == datasync.AtimeBestEffort) != (mtime == datasync.MtimePreserve) {
+		return apierror.New(apierror.ErrBadRequest, "Atime BEST_EFFORT requires Mtime PRESERVE and Atime NONE requires Mtime NONE", nil)
+	}
+
+	if aws.StringValue(opts.PreserveDeletedFiles) == datasync.PreserveDeletedFilesRemove && aws.StringValue(opts.TransferMode) == datasync.TransferModeAll {
+		return apierror.New(apierror.ErrBadRequest, "PreserveDeletedFiles REMOVE cannot be used with TransferMode ALL", nil)
+	}
+
+	if l := aws.StringValue(opts.LogLevel); !logging && l != "" && l != datasync.LogLevelOff {
+		return apierror.New(apierror.ErrBadRequest, "LogLevel "+l+" requires a CloudWatch log group", nil)
+	}
+
+	if aws.StringValue(opts.VerifyMode) == datasync.VerifyModePointInTimeConsistent && dst != nil && dst.Type == S3 && dst.S3 != nil {
+		switch aws.StringValue(dst.S3.S3StorageClass) {
+		case datasync.S3StorageClassGlacier, datasync.S3StorageClassDeepArchive:
+			return apierror.New(apierror.ErrBadRequest, "VerifyMode POINT_IN_TIME_CONSISTENT cannot be used with the "+aws.StringValue(dst.S3.S3StorageClass)+" storage class", nil)
+		}
+	}
+
+	return nil
+}
+
+// validateBandwidth validates a BytesPerSecond bandwidth limit
+func validateBandwidth(b *int64) error {
+	if b != nil && aws.Int64Value(b) != -1 && aws.Int64Value(b) < 1 {
+		return apierror.New(apierror.ErrBadRequest, "BytesPerSecond must be -1 (unlimited) or greater than 0", nil)
+	}
+
+	return nil
+}
+
+// logRetentionDays are the valid CloudWatch Logs retention periods
+var logRetentionDays = []int64{1, 3, 5, 7, 14, 30, 60, 90, 120, 150, 180, 365, 400, 545, 731, 1096, 1827, 2192, 2557, 2922, 3288, 3653}
+
+// validateLogging validates the mover log group configuration
+func validateLogging(input *DatamoverLoggingInput) error {
+	if input == nil || input.RetentionInDays == nil {
+		return nil
+	}
+
+	for _, d := range logRetentionDays {
+		if aws.Int64Value(input.RetentionInDays) == d {
+			return nil
+		}
+	}
+
+	return apierror.New(apierror.ErrBadRequest, fmt.Sprintf("invalid RetentionInDays %d", aws.Int64Value(input.RetentionInDays)), nil)
+}
+
+// validateReport validates the mover task report configuration
+func validateReport(input *DatamoverReportInput) error {
+	if input == nil {
+		return nil
+	}
+
+	if err := validateArn("Report.S3BucketArn", input.S3BucketArn, "s3", ""); err != nil {
+		return err
+	}
+
+	if strings.Contains(aws.StringValue(input.S3BucketArn), "/") {
+		return apierror.New(apierror.ErrBadRequest, "invalid Report.S3BucketArn "+aws.StringValue(input.S3BucketArn)+", expected a bucket ARN", nil)
+	}
+
+	enums := []struct {
+		field string
+		value *string
+		valid []string
+	}{
+		{"OutputType", input.OutputType, datasync.ReportOutputType_Values()},
+		{"ReportLevel", input.ReportLevel, datasync.ReportLevel_Values()},
+		{"ObjectVersionIds", input.ObjectVersionIds, datasync.ObjectVersionIds_Values()},
+	}
+
+	for _, e := range enums {
+		if e.value != nil && !validEnum(aws.StringValue(e.value), e.valid) {
+			return apierror.New(apierror.ErrBadRequest, fmt.Sprintf("invalid Report.%s %s, valid values are %s", e.field, aws.StringValue(e.value), strings.Join(e.valid, ", ")), nil)
+		}
+	}
+
+	return nil
+}
+
+// validateManifest validates the location of a transfer manifest
+func validateManifest(input *DatamoverManifestInput) error {
+	if input == nil {
+		return nil
+	}
+
+	if err := validateArn("Manifest.S3BucketArn", input.S3BucketArn, "s3", ""); err != nil {
+		return err
+	}
+
+	if strings.Contains(aws.StringValue(input.S3BucketArn), "/") {
+		return apierror.New(apierror.ErrBadRequest, "invalid Manifest.S3BucketArn "+aws.StringValue(input.S3BucketArn)+", expected a bucket ARN", nil)
+	}
+
+	p := aws.StringValue(input.ObjectPath)
+	if p == "" {
+		return apierror.New(apierror.ErrBadRequest, "Manifest.ObjectPath is a required field", nil)
+	}
+
+	if strings.HasPrefix(p, "/") || len(p) > 1024 {
+		return apierror.New(apierror.ErrBadRequest, "Manifest.ObjectPath must be an object key without a leading / and up to 1024 characters", nil)
+	}
+
+	if input.ObjectVersionId != nil && aws.StringValue(input.ObjectVersionId) == "" {
+		return apierror.New(apierror.ErrBadRequest, "Manifest.ObjectVersionId cannot be empty", nil)
+	}
+
+	return nil
+}
+
+// webhookUrlRegex matches the characters allowed in DataSync tag values, since webhooks are stored as tags
+var webhookUrlRegex = regexp.MustCompile(`^[a-zA-Z0-9+=._:@/-]+$`)
+
+// privateIP returns true for loopback, link-local, private and unspecified addresses, webhooks can't be delivered to them
+func privateIP(ip net.IP) bool {
+	return ip.IsLoopback() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsPrivate() || ip.IsUnspecified()
+}
+
+// validateWebhookUrls validates the webhooks of a mover.  Each webhook must be a unique https URL of up to
+// 256 characters without a query string, ie. "https://hooks.example.edu/datasync/group1".  Hosts that are
+// loopback, link-local or private addresses (or localhost) are rejected.
+func validateWebhookUrls(urls []string) error {
+	if len(urls) > maxWebhooks {
+		return apierror.New(apierror.ErrBadRequest, fmt.Sprintf("a mover can have up to %d webhooks", maxWebhooks), nil)
+	}
+
+	seen := map[string]bool{}
+	for _, u := range urls {
+		parsed, err := url.Parse(u)
+		if err != nil || parsed.Scheme != "https" || parsed.Host == "" {
+			return apierror.New(apierror.ErrBadRequest, "invalid webhook "+u+", expected an https URL", err)
+		}
+
+		if len(u) > 256 || !webhookUrlRegex.MatchString(u) {
+			return apierror.New(apierror.ErrBadRequest, "invalid webhook "+u+", URLs can be up to 256 characters of letters, numbers and + = . _ : @ / -", nil)
+		}
+
+		host := strings.TrimSuffix(strings.ToLower(parsed.Hostname()), ".")
+		if ip := net.ParseIP(host); (ip != nil && privateIP(ip)) || host == "localhost" || strings.HasSuffix(host, ".localhost") {
+			return apierror.New(apierror.ErrBadRequest, "invalid webhook "+u+", the host can't be a loopback, link-local or private address", nil)
+		}
+
+		if seen[u] {
+			return apierror.New(apierror.ErrBadRequest, "duplicate webhook "+u, nil)
+		}
+		seen[u] = true
+	}
+
+	return nil
+}
+
+// validateFilterPatterns validates a list of DataSync SIMPLE_PATTERN filters.  Each pattern must be a
+// path starting with / or a wildcard (*) and cannot contain the | delimiter, ie. "/project1" or "*.tmp"
+func validateFilterPatterns(field string, patterns []string) error {
+	length := 0
+	for _, p := range patterns {
+		if p == "" || strings.TrimSpace(p) != p {
+			return apierror.New(apierror.ErrBadRequest, fmt.Sprintf("%s pattern '%s' cannot be empty or have leading or trailing spaces", field, p), nil)
+		}
+
+		if strings.Contains(p, "|") {
+			return apierror.New(apierror.ErrBadRequest, fmt.Sprintf("%s pattern '%s' cannot contain |, pass multiple patterns instead", field, p), nil)
+		}
+
+		if !strings.HasPrefix(p, "/") && !strings.HasPrefix(p, "*") {
+			return apierror.New(apierror.ErrBadRequest, fmt.Sprintf("%s pattern '%s' must start with / or *", field, p), nil)
+		}
+
+		if strings.Contains(p, "//") {
+			return apierror.New(apierror.ErrBadRequest, fmt.Sprintf("%s pattern '%s' contains an empty path element", field, p), nil)
+		}
+
+		length += len(p) + 1
+	}
+
+	// the joined filter string is limited to 102400 characters
+	if length > 102400 {
+		return apierror.New(apierror.ErrBadRequest, field+" patterns exceed the maximum length", nil)
+	}
+
+	return nil
+}
+
+// validEnum returns true if the value is one of the valid values
+func validEnum(value string, valid []string) bool {
+	for _, v := range valid {
+		if value == v {
+			return true
+		}
+	}
+	return false
+}
+
+// validateSecurityGroupArns makes sure at least one security group is passed and all are valid ARNs
+func validateSecurityGroupArns(sgs []*string) error {
+	if len(sgs) == 0 {
+		return apierror.New(apierror.ErrBadRequest, "at least one security group ARN is required", nil)
+	}
+
+	for _, sg := range sgs {
+		if err := validateArn("SecurityGroupArns", sg, "ec2", "security-group/"); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// validateAgentArns makes sure at least one agent is passed and all are valid ARNs
+func validateAgentArns(agents []*string) error {
+	if len(agents) == 0 {
+		return apierror.New(apierror.ErrBadRequest, "at least one agent ARN is required", nil)
+	}
+
+	for _, a := range agents {
+		if err := validateArn("AgentArns", a, "datasync", "agent/"); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// validateArn validates that the field is an ARN for the given service, with an optional resource prefix
+func validateArn(field string, a *string, service, resourcePrefix string) error {
+	if a == nil {
+		return apierror.New(apierror.ErrBadRequest, field+" is a required field", nil)
+	}
+
+	parsed, err := arn.Parse(aws.StringValue(a))
+	if err != nil {
+		return apierror.New(apierror.ErrBadRequest, "failed to parse "+field+" "+aws.StringValue(a), err)
+	}
+
+	if parsed.Service != service || !strings.HasPrefix(parsed.Resource, resourcePrefix) {
+		return apierror.New(apierror.ErrBadRequest, "invalid "+field+" "+aws.StringValue(a), nil)
+	}
+
+	return nil
+}
+
+// locationTypes are the supported location types
+var locationTypes = []LocationType{S3, EFS, SMB, NFS, FSxWindows, FSxLustre, FSxOntap, FSxOpenZfs, ObjectStorage, AzureBlob}
+
+// parseListInput parses the filters, expansion and pagination of a data mover list from the query string,
+// tags are passed as tag=key=value (or tag=key to match any value).  It returns nil when none are passed.
+func parseListInput(query url.Values) (*DatamoverListInput, error) {
+	input := &DatamoverListInput{}
+	found := false
+
+	if e := query.Get("expand"); e != "" {
+		v, err := strconv.ParseBool(e)
+		if err != nil {
+			return nil, apierror.New(apierror.ErrBadRequest, "expand must be true or false", nil)
+		}
+		input.Expand = v
+		found = true
+	}
+
+	for _, t := range query["tag"] {
+		kv := strings.SplitN(t, "=", 2)
+		if kv[0] == "" {
+			return nil, apierror.New(apierror.ErrBadRequest, "invalid tag filter "+t, nil)
+		}
+
+		tag := Tag{Key: kv[0]}
+		if len(kv) == 2 {
+			tag.Value = kv[1]
+		}
+		input.Tags = append(input.Tags, tag)
+		found = true
+	}
+
+	if s := query.Get("status"); s != "" {
+		valid := false
+		for _, v := range datasync.TaskStatus_Values() {
+			if s == v {
+				valid = true
+			}
+		}
+
+		if !valid {
+			return nil, apierror.New(apierror.ErrBadRequest, "invalid status "+s, nil)
+		}
+		input.Status = s
+		found = true
+	}
+
+	if l := query.Get("locationType"); l != "" {
+		valid := false
+		for _, v := range locationTypes {
+			if LocationType(l) == v {
+				valid = true
+			}
+		}
+
+		if !valid {
+			return nil, apierror.New(apierror.ErrBadRequest, "invalid location type "+l, nil)
+		}
+		input.LocationType = LocationType(l)
+		found = true
+	}
+
+	if l := query.Get("limit"); l != "" {
+		v, err := strconv.Atoi(l)
+		if err != nil || v < 1 || v > maxListLimit {
+			return nil, apierror.New(apierror.ErrBadRequest, fmt.Sprintf("limit must be between 1 and %d", maxListLimit), nil)
+		}
+		input.Limit = v
+		found = true
+	}
+
+	if n := query.Get("next"); n != "" {
+		if _, err := decodeListCursor(n); err != nil {
+			return nil, err
+		}
+
+		if input.Limit == 0 {
+			input.Limit = defaultListLimit
+		}
+		input.Next = n
+		found = true
+	}
+
+	if !found {
+		return nil, nil
+	}
+
+	return input, nil
+}
+
+// parseRunListInput parses the filters, expansion and pagination of a data mover run list from the query
+// string, since and until are RFC3339 times.  It returns nil when none are passed.
+func parseRunListInput(query url.Values) (*DatamoverRunListInput, error) {
+	input := &DatamoverRunListInput{}
+	found := false
+
+	if e := query.Get("expand"); e != "" {
+		v, err := strconv.ParseBool(e)
+		if err != nil {
+			return nil, apierror.New(apierror.ErrBadRequest, "expand must be true or false", nil)
+		}
+		input.Expand = v
+		found = true
+	}
+
+	if s := query.Get("status"); s != "" {
+		valid := false
+		for _, v := range datasync.TaskExecutionStatus_Values() {
+			if s == v {
+				valid = true
+			}
+		}
+
+		if !valid {
+			return nil, apierror.New(apierror.ErrBadRequest, "invalid status "+s, nil)
+		}
+		input.Status = s
+		found = true
+	}
+
+	since, err := parseTimeParam(query, "since")
+	if err != nil {
+		return nil, err
+	}
+
+	until, err := parseTimeParam(query, "until")
+	if err != nil {
+		return nil, err
+	}
+
+	if since != nil || until != nil {
+		input.Since, input.Until = since, until
+		found = true
+	}
+
+	if input.Since != nil && input.Until != nil && input.Until.Before(*input.Since) {
+		return nil, apierror.New(apierror.ErrBadRequest, "until cannot be before since", nil)
+	}
+
+	if l := query.Get("limit"); l != "" {
+		v, err := strconv.Atoi(l)
+		if err != nil || v < 1 || v > maxListLimit {
+			return nil, apierror.New(apierror.ErrBadRequest, fmt.Sprintf("limit must be between 1 and %d", maxListLimit), nil)
+		}
+		input.Limit = v
+		found = true
+	}
+
+	if n := query.Get("next"); n != "" {
+		if _, err := decodeRunCursor(n); err != nil {
+			return nil, err
+		}
+
+		if input.Limit == 0 {
+			input.Limit = defaultListLimit
+		}
+		input.Next = n
+		found = true
+	}
+
+	if !found {
+		return nil, nil
+	}
+
+	return input, nil
+}
+
+// parseTimeParam parses an optional RFC3339 time from the query string
+func parseTimeParam(query url.Values, field string) (*time.Time, error) {
+	v := query.Get(field)
+	if v == "" {
+		return nil, nil
+	}
+
+	t, err := time.Parse(time.RFC3339, v)
+	if err != nil {
+		return nil, apierror.New(apierror.ErrBadRequest, field+" must be an RFC3339 time, ie. 2021-12-01T00:00:00Z", nil)
+	}
+
+	return &t, nil
+}
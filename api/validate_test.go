@@ -0,0 +1,424 @@
+package api
+
+import (
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+)
+
+func Test_validateLocationInput(t *testing.T) {
+	sgs := []*string{aws.String("arn:aws:ec2:us-east-1:012345678901:security-group/sg-01234567890123456")}
+
+	tests := []struct {
+		name    string
+		input   *DatamoverLocationInput
+		wantErr bool
+	}{
+		{"nil input", nil, true},
+		{"invalid type", &DatamoverLocationInput{Type: "FOO"}, true},
+		{"missing S3 input", &DatamoverLocationInput{Type: S3}, true},
+		{"invalid S3 bucket arn", &DatamoverLocationInput{Type: S3, S3: &DatamoverLocationS3Input{S3BucketArn: aws.String("bucket")}}, true},
+		{"valid S3", &DatamoverLocationInput{Type: S3, S3: &DatamoverLocationS3Input{S3BucketArn: aws.String("arn:aws:s3:::bucket")}}, false},
+		{
+			"EFS invalid subnet",
+			&DatamoverLocationInput{Type: EFS, EFS: &DatamoverLocationEFSInput{
+				EfsFilesystemArn:  aws.String("arn:aws:elasticfilesystem:us-east-1:012345678901:file-system/fs-01234567890123456"),
+				SecurityGroupArns: sgs,
+				SubnetArn:         aws.String("arn:aws:ec2:us-east-1:012345678901:security-group/sg-01234567890123456"),
+			}},
+			true,
+		},
+		{
+			"valid EFS",
+			&DatamoverLocationInput{Type: EFS, EFS: &DatamoverLocationEFSInput{
+				EfsFilesystemArn:  aws.String("arn:aws:elasticfilesystem:us-east-1:012345678901:file-system/fs-01234567890123456"),
+				SecurityGroupArns: sgs,
+				SubnetArn:         aws.String("arn:aws:ec2:us-east-1:012345678901:subnet/subnet-01234567890123456"),
+			}},
+			false,
+		},
+		{"SMB missing agents", &DatamoverLocationInput{Type: SMB, SMB: &DatamoverLocationSMBInput{}}, true},
+		{
+			"valid NFS",
+			&DatamoverLocationInput{Type: NFS, NFS: &DatamoverLocationNFSInput{
+				AgentArns: []*string{aws.String("arn:aws:datasync:us-east-1:012345678901:agent/agent-0914d8e6e0674c8b7")},
+			}},
+			false,
+		},
+		{
+			"FSxLustre missing security groups",
+			&DatamoverLocationInput{Type: FSxLustre, FSxLustre: &DatamoverLocationFSxLustreInput{
+				FsxFilesystemArn: aws.String("arn:aws:fsx:us-east-1:012345678901:file-system/fs-01234567890123456"),
+			}},
+			true,
+		},
+		{
+			"FSxWindows invalid security group",
+			&DatamoverLocationInput{Type: FSxWindows, FSxWindows: &DatamoverLocationFSxWindowsInput{
+				FsxFilesystemArn:  aws.String("arn:aws:fsx:us-east-1:012345678901:file-system/fs-01234567890123456"),
+				SecurityGroupArns: []*string{aws.String("sg-01234567890123456")},
+			}},
+			true,
+		},
+		{
+			"valid FSxWindows",
+			&DatamoverLocationInput{Type: FSxWindows, FSxWindows: &DatamoverLocationFSxWindowsInput{
+				FsxFilesystemArn:  aws.String("arn:aws:fsx:us-east-1:012345678901:file-system/fs-01234567890123456"),
+				SecurityGroupArns: sgs,
+			}},
+			false,
+		},
+		{
+			"FSxOntap both protocols",
+			&DatamoverLocationInput{Type: FSxOntap, FSxOntap: &DatamoverLocationFSxOntapInput{
+				Protocol: &DatamoverFSxProtocolInput{
+					NFS: &DatamoverFSxProtocolNFSInput{},
+					SMB: &DatamoverFSxProtocolSMBInput{},
+				},
+				SecurityGroupArns:        sgs,
+				StorageVirtualMachineArn: aws.String("arn:aws:fsx:us-east-1:012345678901:storage-virtual-machine/fs-01234567890123456/svm-01234567890123456"),
+			}},
+			true,
+		},
+		{
+			"valid FSxOntap",
+			&DatamoverLocationInput{Type: FSxOntap, FSxOntap: &DatamoverLocationFSxOntapInput{
+				Protocol:                 &DatamoverFSxProtocolInput{NFS: &DatamoverFSxProtocolNFSInput{}},
+				SecurityGroupArns:        sgs,
+				StorageVirtualMachineArn: aws.String("arn:aws:fsx:us-east-1:012345678901:storage-virtual-machine/fs-01234567890123456/svm-01234567890123456"),
+			}},
+			false,
+		},
+		{
+			"valid FSxOpenZfs",
+			&DatamoverLocationInput{Type: FSxOpenZfs, FSxOpenZfs: &DatamoverLocationFSxOpenZfsInput{
+				FsxFilesystemArn:  aws.String("arn:aws:fsx:us-east-1:012345678901:file-system/fs-01234567890123456"),
+				SecurityGroupArns: sgs,
+			}},
+			false,
+		},
+		{
+			"ObjectStorage access key without secret",
+			&DatamoverLocationInput{Type: ObjectStorage, ObjectStorage: &DatamoverLocationObjectStorageInput{
+				AccessKey:      aws.String("AKIAEXAMPLE"),
+				AgentArns:      []*string{aws.String("arn:aws:datasync:us-east-1:012345678901:agent/agent-0914d8e6e0674c8b7")},
+				BucketName:     aws.String("bucket"),
+				ServerHostname: aws.String("objects.example.com"),
+			}},
+			true,
+		},
+		{
+			"valid ObjectStorage",
+			&DatamoverLocationInput{Type: ObjectStorage, ObjectStorage: &DatamoverLocationObjectStorageInput{
+				AgentArns:      []*string{aws.String("arn:aws:datasync:us-east-1:012345678901:agent/agent-0914d8e6e0674c8b7")},
+				BucketName:     aws.String("bucket"),
+				ServerHostname: aws.String("objects.example.com"),
+				ServerProtocol: aws.String("HTTPS"),
+			}},
+			false,
+		},
+		{
+			"AzureBlob missing token",
+			&DatamoverLocationInput{Type: AzureBlob, AzureBlob: &DatamoverLocationAzureBlobInput{
+				AgentArns:    []*string{aws.String("arn:aws:datasync:us-east-1:012345678901:agent/agent-0914d8e6e0674c8b7")},
+				ContainerUrl: aws.String("https://example.blob.core.windows.net/container"),
+			}},
+			true,
+		},
+		{
+			"AzureBlob invalid access tier",
+			&DatamoverLocationInput{Type: AzureBlob, AzureBlob: &DatamoverLocationAzureBlobInput{
+				AccessTier:   aws.String("WARM"),
+				AgentArns:    []*string{aws.String("arn:aws:datasync:us-east-1:012345678901:agent/agent-0914d8e6e0674c8b7")},
+				ContainerUrl: aws.String("https://example.blob.core.windows.net/container"),
+				SasToken:     aws.String("sp=r&st=2023-12-01"),
+			}},
+			true,
+		},
+		{
+			"valid AzureBlob",
+			&DatamoverLocationInput{Type: AzureBlob, AzureBlob: &DatamoverLocationAzureBlobInput{
+				AgentArns:    []*string{aws.String("arn:aws:datasync:us-east-1:012345678901:agent/agent-0914d8e6e0674c8b7")},
+				ContainerUrl: aws.String("https://example.blob.core.windows.net/container"),
+				SasToken:     aws.String("sp=r&st=2023-12-01"),
+			}},
+			false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateLocationInput(tt.input)
+			if tt.wantErr && err == nil {
+				t.Error("expected error but did not receive error")
+			} else if !tt.wantErr && err != nil {
+				t.Errorf("received unexpected error %v", err)
+			}
+		})
+	}
+}
+
+func Test_validateOptions(t *testing.T) {
+	glacier := &DatamoverLocationInput{Type: S3, S3: &DatamoverLocationS3Input{S3StorageClass: aws.String("GLACIER")}}
+
+	tests := []struct {
+		name    string
+		opts    *DatamoverOptions
+		dst     *DatamoverLocationInput
+		logging bool
+		wantErr bool
+	}{
+		{"nil options", nil, nil, false, false},
+		{"empty options", &DatamoverOptions{}, nil, false, false},
+		{"invalid enum", &DatamoverOptions{OverwriteMode: aws.String("SOMETIMES")}, nil, false, true},
+		{"valid mirror", &DatamoverOptions{PreserveDeletedFiles: aws.String("REMOVE"), OverwriteMode: aws.String("ALWAYS"), PosixPermissions: aws.String("PRESERVE")}, nil, false, false},
+		{"remove with transfer all", &DatamoverOptions{PreserveDeletedFiles: aws.String("REMOVE"), TransferMode: aws.String("ALL")}, nil, false, true},
+		{"atime none with default mtime", &DatamoverOptions{Atime: aws.String("NONE")}, nil, false, true},
+		{"atime and mtime none", &DatamoverOptions{Atime: aws.String("NONE"), Mtime: aws.String("NONE")}, nil, false, false},
+		{"invalid bandwidth", &DatamoverOptions{BytesPerSecond: aws.Int64(0)}, nil, false, true},
+		{"unlimited bandwidth", &DatamoverOptions{BytesPerSecond: aws.Int64(-1)}, nil, false, false},
+		{"logging without log group", &DatamoverOptions{LogLevel: aws.String("TRANSFER")}, nil, false, true},
+		{"logging with log group", &DatamoverOptions{LogLevel: aws.String("TRANSFER")}, nil, true, false},
+		{"point in time to glacier", &DatamoverOptions{VerifyMode: aws.String("POINT_IN_TIME_CONSISTENT")}, glacier, false, true},
+		{"only files transferred to glacier", &DatamoverOptions{VerifyMode: aws.String("ONLY_FILES_TRANSFERRED")}, glacier, false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateOptions(tt.opts, tt.dst, tt.logging)
+			if tt.wantErr && err == nil {
+				t.Error("expected error but did not receive error")
+			} else if !tt.wantErr && err != nil {
+				t.Errorf("received unexpected error %v", err)
+			}
+		})
+	}
+}
+
+func Test_validateFilterPatterns(t *testing.T) {
+	tests := []struct {
+		name     string
+		patterns []string
+		wantErr  bool
+	}{
+		{"nil patterns", nil, false},
+		{"valid patterns", []string{"/project1", "*/scratch", "*.tmp"}, false},
+		{"empty pattern", []string{""}, true},
+		{"pipe in pattern", []string{"/project1|/project2"}, true},
+		{"relative pattern", []string{"project1"}, true},
+		{"trailing space", []string{"/project1 "}, true},
+		{"empty path element", []string{"/project1//data"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateFilterPatterns("Includes", tt.patterns)
+			if tt.wantErr && err == nil {
+				t.Error("expected error but did not receive error")
+			} else if !tt.wantErr && err != nil {
+				t.Errorf("received unexpected error %v", err)
+			}
+		})
+	}
+}
+
+func Test_validateLogging(t *testing.T) {
+	if err := validateLogging(nil); err != nil {
+		t.Errorf("expected nil error for nil input, got %s", err)
+	}
+
+	if err := validateLogging(&DatamoverLoggingInput{}); err != nil {
+		t.Errorf("expected nil error for default retention, got %s", err)
+	}
+
+	if err := validateLogging(&DatamoverLoggingInput{RetentionInDays: aws.Int64(90)}); err != nil {
+		t.Errorf("expected nil error for 90 day retention, got %s", err)
+	}
+
+	if err := validateLogging(&DatamoverLoggingInput{RetentionInDays: aws.Int64(10)}); err == nil {
+		t.Error("expected error for 10 day retention, got nil")
+	}
+}
+
+func Test_validateReport(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   *DatamoverReportInput
+		wantErr bool
+	}{
+		{"nil input", nil, false},
+		{"missing bucket", &DatamoverReportInput{}, true},
+		{"bucket", &DatamoverReportInput{S3BucketArn: aws.String("arn:aws:s3:::reports")}, false},
+		{"object arn", &DatamoverReportInput{S3BucketArn: aws.String("arn:aws:s3:::reports/foo")}, true},
+		{"not s3", &DatamoverReportInput{S3BucketArn: aws.String("arn:aws:sqs:us-east-1:012345678901:reports")}, true},
+		{
+			"all options",
+			&DatamoverReportInput{
+				S3BucketArn:      aws.String("arn:aws:s3:::reports"),
+				Subdirectory:     aws.String("datasync"),
+				OutputType:       aws.String("STANDARD"),
+				ReportLevel:      aws.String("ERRORS_ONLY"),
+				ObjectVersionIds: aws.String("INCLUDE"),
+			},
+			false,
+		},
+		{"bad output type", &DatamoverReportInput{S3BucketArn: aws.String("arn:aws:s3:::reports"), OutputType: aws.String("DETAILED")}, true},
+		{"bad report level", &DatamoverReportInput{S3BucketArn: aws.String("arn:aws:s3:::reports"), ReportLevel: aws.String("ALL")}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := validateReport(tt.input); (err != nil) != tt.wantErr {
+				t.Errorf("validateReport() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func Test_validateManifest(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   *DatamoverManifestInput
+		wantErr bool
+	}{
+		{"nil input", nil, false},
+		{"missing bucket", &DatamoverManifestInput{ObjectPath: aws.String("lists/today.csv")}, true},
+		{"manifest", &DatamoverManifestInput{S3BucketArn: aws.String("arn:aws:s3:::manifests"), ObjectPath: aws.String("lists/today.csv")}, false},
+		{"version", &DatamoverManifestInput{S3BucketArn: aws.String("arn:aws:s3:::manifests"), ObjectPath: aws.String("lists/today.csv"), ObjectVersionId: aws.String("3HL4kqtJlcpXroDTDmjVBH40Nrjfkd")}, false},
+		{"empty version", &DatamoverManifestInput{S3BucketArn: aws.String("arn:aws:s3:::manifests"), ObjectPath: aws.String("lists/today.csv"), ObjectVersionId: aws.String("")}, true},
+		{"object arn", &DatamoverManifestInput{S3BucketArn: aws.String("arn:aws:s3:::manifests/lists"), ObjectPath: aws.String("today.csv")}, true},
+		{"missing path", &DatamoverManifestInput{S3BucketArn: aws.String("arn:aws:s3:::manifests")}, true},
+		{"leading slash", &DatamoverManifestInput{S3BucketArn: aws.String("arn:aws:s3:::manifests"), ObjectPath: aws.String("/lists/today.csv")}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := validateManifest(tt.input); (err != nil) != tt.wantErr {
+				t.Errorf("validateManifest() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func Test_validateWebhookUrls(t *testing.T) {
+	tests := []struct {
+		name    string
+		urls    []string
+		wantErr bool
+	}{
+		{"no webhooks", []string{}, false},
+		{"webhooks", []string{"https://hooks.example.edu/datasync/group1", "https://hooks.example.edu:8443/datasync"}, false},
+		{"http", []string{"http://hooks.example.edu/datasync"}, true},
+		{"missing host", []string{"https:///datasync"}, true},
+		{"query string", []string{"https://hooks.example.edu/datasync?token=abc"}, true},
+		{"too long", []string{"https://hooks.example.edu/" + strings.Repeat("a", 240)}, true},
+		{"duplicate", []string{"https://hooks.example.edu/datasync", "https://hooks.example.edu/datasync"}, true},
+		{"too many", []string{"https://a.example.edu", "https://b.example.edu", "https://c.example.edu", "https://d.example.edu", "https://e.example.edu", "https://f.example.edu"}, true},
+		{"public address", []string{"https://198.51.100.7/datasync"}, false},
+		{"metadata endpoint", []string{"https://169.254.169.254/latest/meta-data"}, true},
+		{"loopback", []string{"https://127.0.0.1:8443/datasync"}, true},
+		{"private", []string{"https://10.1.2.3/datasync"}, true},
+		{"unspecified", []string{"https://0.0.0.0/datasync"}, true},
+		{"localhost", []string{"https://localhost/datasync"}, true},
+		{"localhost subdomain", []string{"https://hooks.LOCALHOST./datasync"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := validateWebhookUrls(tt.urls); (err != nil) != tt.wantErr {
+				t.Errorf("validateWebhookUrls() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func Test_parseListInput(t *testing.T) {
+	tests := []struct {
+		name    string
+		query   string
+		want    *DatamoverListInput
+		wantErr bool
+	}{
+		{"no parameters", "", nil, false},
+		{"unrelated parameters", "foo=bar", nil, false},
+		{"expand", "expand=true", &DatamoverListInput{Expand: true}, false},
+		{"invalid expand", "expand=yes", nil, true},
+		{"tags", "tag=Project%3Dgenomics&tag=Owner", &DatamoverListInput{Tags: Tags{{Key: "Project", Value: "genomics"}, {Key: "Owner"}}}, false},
+		{"tag without key", "tag=%3Dgenomics", nil, true},
+		{"status", "status=RUNNING", &DatamoverListInput{Status: "RUNNING"}, false},
+		{"invalid status", "status=running", nil, true},
+		{"location type", "locationType=FSX_ONTAP", &DatamoverListInput{LocationType: FSxOntap}, false},
+		{"invalid location type", "locationType=FTP", nil, true},
+		{"limit", "limit=10", &DatamoverListInput{Limit: 10}, false},
+		{"limit too large", "limit=1001", nil, true},
+		{"invalid limit", "limit=ten", nil, true},
+		{"cursor uses the default limit", "next=Z3JvdXAxL21vdmVyMS91cy1lYXN0LTE", &DatamoverListInput{Limit: defaultListLimit, Next: "Z3JvdXAxL21vdmVyMS91cy1lYXN0LTE"}, false},
+		{"invalid cursor", "next=Z3JvdXAx", nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query, err := url.ParseQuery(tt.query)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := parseListInput(query)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseListInput() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseListInput() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_parseRunListInput(t *testing.T) {
+	since := time.Date(2021, 12, 1, 0, 0, 0, 0, time.UTC)
+	until := time.Date(2021, 12, 2, 0, 0, 0, 0, time.UTC)
+	token := encodeRunCursor("exec-1")
+
+	tests := []struct {
+		name    string
+		query   string
+		want    *DatamoverRunListInput
+		wantErr bool
+	}{
+		{"no parameters", "", nil, false},
+		{"expand", "expand=true", &DatamoverRunListInput{Expand: true}, false},
+		{"invalid expand", "expand=yes", nil, true},
+		{"status", "status=SUCCESS", &DatamoverRunListInput{Status: "SUCCESS"}, false},
+		{"invalid status", "status=DONE", nil, true},
+		{"since and until", "since=2021-12-01T00:00:00Z&until=2021-12-02T00:00:00Z", &DatamoverRunListInput{Since: &since, Until: &until}, false},
+		{"invalid since", "since=yesterday", nil, true},
+		{"until before since", "since=2021-12-02T00:00:00Z&until=2021-12-01T00:00:00Z", nil, true},
+		{"limit", "limit=10", &DatamoverRunListInput{Limit: 10}, false},
+		{"invalid limit", "limit=0", nil, true},
+		{"token uses the default limit", "next=" + token, &DatamoverRunListInput{Limit: defaultListLimit, Next: token}, false},
+		{"invalid token", "next=!!!", nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query, err := url.ParseQuery(tt.query)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := parseRunListInput(query)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseRunListInput() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseRunListInput() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
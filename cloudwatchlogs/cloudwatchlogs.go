@@ -0,0 +1,169 @@
+package cloudwatchlogs
+
+import (
+	"context"
+
+	"github.com/YaleSpinup/apierror"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/cloudwatchlogs"
+	"github.com/aws/aws-sdk-go/service/cloudwatchlogs/cloudwatchlogsiface"
+	log "github.com/sirupsen/logrus"
+)
+
+// CloudWatchLogs is a wrapper around the aws cloudwatchlogs service
+type CloudWatchLogs struct {
+	session *session.Session
+	Service cloudwatchlogsiface.CloudWatchLogsAPI
+}
+
+type CloudWatchLogsOption func(*CloudWatchLogs)
+
+func New(opts ...CloudWatchLogsOption) CloudWatchLogs {
+	c := CloudWatchLogs{}
+
+	for _, opt := range opts {
+		opt(&c)
+	}
+
+	if c.session != nil {
+		c.Service = cloudwatchlogs.New(c.session)
+	}
+
+	return c
+}
+
+func WithSession(sess *session.Session) CloudWatchLogsOption {
+	return func(c *CloudWatchLogs) {
+		log.Debug("using aws session")
+		c.session = sess
+	}
+}
+
+func WithCredentials(key, secret, token, region string) CloudWatchLogsOption {
+	return func(c *CloudWatchLogs) {
+		log.Debugf("creating new session with key id %s in region %s", key, region)
+		sess := session.Must(session.NewSession(&aws.Config{
+			Credentials: credentials.NewStaticCredentials(key, secret, token),
+			Region:      aws.String(region),
+		}))
+		c.session = sess
+	}
+}
+
+// CreateLogGroup creates a log group with the given retention (in days) and tags
+func (c *CloudWatchLogs) CreateLogGroup(ctx context.Context, name string, retention int64, tags map[string]*string) error {
+	if name == "" {
+		return apierror.New(apierror.ErrBadRequest, "invalid input", nil)
+	}
+
+	log.Infof("creating cloudwatch log group %s", name)
+
+	if _, err := c.Service.CreateLogGroupWithContext(ctx, &cloudwatchlogs.CreateLogGroupInput{
+		LogGroupName: aws.String(name),
+		Tags:         tags,
+	}); err != nil {
+		return ErrCode("failed to create log group", err)
+	}
+
+	if retention > 0 {
+		if _, err := c.Service.PutRetentionPolicyWithContext(ctx, &cloudwatchlogs.PutRetentionPolicyInput{
+			LogGroupName:    aws.String(name),
+			RetentionInDays: aws.Int64(retention),
+		}); err != nil {
+			// don't leave behind a log group that would be kept forever
+			if _, derr := c.Service.DeleteLogGroupWithContext(ctx, &cloudwatchlogs.DeleteLogGroupInput{
+				LogGroupName: aws.String(name),
+			}); derr != nil {
+				log.Warnf("failed to delete log group %s after failing to set its retention: %s", name, derr)
+			}
+
+			return ErrCode("failed to set log group retention", err)
+		}
+	}
+
+	return nil
+}
+
+// DescribeLogGroup returns details about a log group
+func (c *CloudWatchLogs) DescribeLogGroup(ctx context.Context, name string) (*cloudwatchlogs.LogGroup, error) {
+	if name == "" {
+		return nil, apierror.New(apierror.ErrBadRequest, "invalid input", nil)
+	}
+
+	log.Infof("describing cloudwatch log group %s", name)
+
+	out, err := c.Service.DescribeLogGroupsWithContext(ctx, &cloudwatchlogs.DescribeLogGroupsInput{
+		LogGroupNamePrefix: aws.String(name),
+	})
+	if err != nil {
+		return nil, ErrCode("failed to describe log group", err)
+	}
+
+	log.Debugf("describing cloudwatch log group output: %+v", out)
+
+	for _, g := range out.LogGroups {
+		if aws.StringValue(g.LogGroupName) == name {
+			return g, nil
+		}
+	}
+
+	return nil, apierror.New(apierror.ErrNotFound, "log group not found", nil)
+}
+
+// DeleteLogGroup deletes a log group and all of its log events
+func (c *CloudWatchLogs) DeleteLogGroup(ctx context.Context, name string) error {
+	if name == "" {
+		return apierror.New(apierror.ErrBadRequest, "invalid input", nil)
+	}
+
+	log.Infof("deleting cloudwatch log group %s", name)
+
+	if _, err := c.Service.DeleteLogGroupWithContext(ctx, &cloudwatchlogs.DeleteLogGroupInput{
+		LogGroupName: aws.String(name),
+	}); err != nil {
+		return ErrCode("failed to delete log group", err)
+	}
+
+	return nil
+}
+
+// PutResourcePolicy creates or updates a resource policy allowing AWS services to write to log groups
+func (c *CloudWatchLogs) PutResourcePolicy(ctx context.Context, name, policy string) error {
+	if name == "" || policy == "" {
+		return apierror.New(apierror.ErrBadRequest, "invalid input", nil)
+	}
+
+	log.Infof("putting cloudwatch logs resource policy %s", name)
+
+	out, err := c.Service.PutResourcePolicyWithContext(ctx, &cloudwatchlogs.PutResourcePolicyInput{
+		PolicyName:     aws.String(name),
+		PolicyDocument: aws.String(policy),
+	})
+	if err != nil {
+		return ErrCode("failed to put resource policy", err)
+	}
+
+	log.Debugf("putting cloudwatch logs resource policy output: %+v", out)
+
+	return nil
+}
+
+// FilterLogEvents returns a page of log events from a log group
+func (c *CloudWatchLogs) FilterLogEvents(ctx context.Context, input *cloudwatchlogs.FilterLogEventsInput) (*cloudwatchlogs.FilterLogEventsOutput, error) {
+	if input == nil || aws.StringValue(input.LogGroupName) == "" {
+		return nil, apierror.New(apierror.ErrBadRequest, "invalid input", nil)
+	}
+
+	log.Infof("filtering cloudwatch log events in %s", aws.StringValue(input.LogGroupName))
+
+	out, err := c.Service.FilterLogEventsWithContext(ctx, input)
+	if err != nil {
+		return nil, ErrCode("failed to get log events", err)
+	}
+
+	log.Debugf("filtering cloudwatch log events returned %d events", len(out.Events))
+
+	return out, nil
+}
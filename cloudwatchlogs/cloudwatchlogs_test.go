@@ -0,0 +1,68 @@
+package cloudwatchlogs
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/cloudwatchlogs"
+	"github.com/aws/aws-sdk-go/service/cloudwatchlogs/cloudwatchlogsiface"
+)
+
+// mockCloudWatchLogsClient is a fake cloudwatchlogs client
+type mockCloudWatchLogsClient struct {
+	cloudwatchlogsiface.CloudWatchLogsAPI
+	t   *testing.T
+	err error
+}
+
+func newMockCloudWatchLogsClient(t *testing.T, err error) cloudwatchlogsiface.CloudWatchLogsAPI {
+	return &mockCloudWatchLogsClient{
+		t:   t,
+		err: err,
+	}
+}
+
+func (m *mockCloudWatchLogsClient) DescribeLogGroupsWithContext(ctx context.Context, input *cloudwatchlogs.DescribeLogGroupsInput, opts ...request.Option) (*cloudwatchlogs.DescribeLogGroupsOutput, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+
+	return &cloudwatchlogs.DescribeLogGroupsOutput{
+		LogGroups: []*cloudwatchlogs.LogGroup{
+			{LogGroupName: aws.String("/aws/datasync/group1-extra")},
+			{LogGroupName: aws.String("/aws/datasync/group1"), Arn: aws.String("arn:aws:logs:us-east-1:012345678901:log-group:/aws/datasync/group1:*")},
+		},
+	}, nil
+}
+
+func TestNewSession(t *testing.T) {
+	client := New()
+	to := reflect.TypeOf(client).String()
+	if to != "cloudwatchlogs.CloudWatchLogs" {
+		t.Errorf("expected type to be cloudwatchlogs.CloudWatchLogs, got %s", to)
+	}
+}
+
+func TestDescribeLogGroup(t *testing.T) {
+	c := CloudWatchLogs{Service: newMockCloudWatchLogsClient(t, nil)}
+
+	out, err := c.DescribeLogGroup(context.TODO(), "/aws/datasync/group1")
+	if err != nil {
+		t.Fatalf("expected nil error, got %s", err)
+	}
+
+	if aws.StringValue(out.Arn) != "arn:aws:logs:us-east-1:012345678901:log-group:/aws/datasync/group1:*" {
+		t.Errorf("unexpected log group %+v", out)
+	}
+
+	if _, err := c.DescribeLogGroup(context.TODO(), "/aws/datasync/missing"); err == nil {
+		t.Error("expected not found error, got nil")
+	}
+
+	if _, err := c.DescribeLogGroup(context.TODO(), ""); err == nil {
+		t.Error("expected error for empty name, got nil")
+	}
+}
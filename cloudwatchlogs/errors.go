@@ -0,0 +1,83 @@
+package cloudwatchlogs
+
+import (
+	"github.com/YaleSpinup/apierror"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/cloudwatchlogs"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+func ErrCode(msg string, err error) error {
+	if aerr, ok := errors.Cause(err).(awserr.Error); ok {
+		switch aerr.Code() {
+		case
+			"Forbidden",
+
+			// ErrCodeAccessDeniedException for service response error code
+			// "AccessDeniedException".
+			//
+			// You don't have sufficient permissions to perform this action.
+			cloudwatchlogs.ErrCodeAccessDeniedException:
+
+			return apierror.New(apierror.ErrForbidden, msg, aerr)
+		case
+			// ErrCodeResourceAlreadyExistsException for service response error code
+			// "ResourceAlreadyExistsException".
+			//
+			// The specified resource already exists.
+			cloudwatchlogs.ErrCodeResourceAlreadyExistsException,
+
+			// ErrCodeOperationAbortedException for service response error code
+			// "OperationAbortedException".
+			//
+			// Multiple concurrent requests to update the same resource were in conflict.
+			cloudwatchlogs.ErrCodeOperationAbortedException:
+
+			return apierror.New(apierror.ErrConflict, msg, aerr)
+		case
+			// ErrCodeLimitExceededException for service response error code
+			// "LimitExceededException".
+			//
+			// You have reached the maximum number of resources that can be created.
+			cloudwatchlogs.ErrCodeLimitExceededException,
+
+			// ErrCodeThrottlingException for service response error code
+			// "ThrottlingException".
+			//
+			// The request was throttled because of quota limits.
+			cloudwatchlogs.ErrCodeThrottlingException:
+
+			return apierror.New(apierror.ErrLimitExceeded, msg, aerr)
+		case
+			// ErrCodeResourceNotFoundException for service response error code
+			// "ResourceNotFoundException".
+			//
+			// The specified resource does not exist.
+			cloudwatchlogs.ErrCodeResourceNotFoundException:
+
+			return apierror.New(apierror.ErrNotFound, msg, aerr)
+		case
+			// ErrCodeInvalidParameterException for service response error code
+			// "InvalidParameterException".
+			//
+			// A parameter is specified incorrectly.
+			cloudwatchlogs.ErrCodeInvalidParameterException:
+
+			return apierror.New(apierror.ErrBadRequest, msg, aerr)
+		case
+			// ErrCodeServiceUnavailableException for service response error code
+			// "ServiceUnavailableException".
+			//
+			// The service cannot complete the request.
+			cloudwatchlogs.ErrCodeServiceUnavailableException:
+
+			return apierror.New(apierror.ErrServiceUnavailable, msg, aerr)
+		default:
+			return apierror.New(apierror.ErrBadRequest, msg, aerr)
+		}
+	}
+
+	log.Warnf("uncaught error: %s, returning Internal Server Error", err)
+	return apierror.New(apierror.ErrInternalError, msg, err)
+}
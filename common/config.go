@@ -28,7 +28,10 @@ import (
 type Config struct {
 	ListenAddress string
 	Account       Account
+	Accounts      map[string]MemberAccount
 	Flywheel      Flywheel
+	Webhooks      Webhooks
+	Events        Events
 	Token         string
 	LogLevel      string
 	Version       Version
@@ -43,6 +46,21 @@ type Account struct {
 	Secret     string
 	Region     string
 	Role       string
+	// Regions are the regions movers are managed in, Region is the default and is always included
+	Regions []string
+}
+
+// MemberAccount is the configuration for a member account managed by the API, keyed by account ID or alias.
+// Fields that aren't set default to the values of the Account.
+type MemberAccount struct {
+	// Id is the account ID, it's required when the account is keyed by an alias
+	Id         string
+	Role       string
+	ExternalID string
+	// Regions are the regions movers are managed in, the first is the default
+	Regions []string
+	// Groups are the groups allowed to manage movers and agents in the account, any group is allowed when it's empty
+	Groups []string
 }
 
 // Flywheel is the configuration for task tracking in flywheel
@@ -55,6 +73,19 @@ type Flywheel struct {
 	TTL           string
 }
 
+// Webhooks is the configuration for run notifications, notifications are disabled without a secret
+type Webhooks struct {
+	// Secret signs the webhook payloads
+	Secret string
+}
+
+// Events is the configuration for receiving DataSync execution state change events from EventBridge,
+// events are only received when a queue is configured
+type Events struct {
+	// QueueUrl is the SQS queue the EventBridge rule sends the events to
+	QueueUrl string
+}
+
 // Version carries around the API version information
 type Version struct {
 	Version    string
@@ -32,6 +32,14 @@ var testConfig = []byte(
 			"role": "uber-role",
 			"externalId": "foobar"
 		},
+		"accounts": {
+			"research": {
+				"id": "012345678901",
+				"role": "research-role",
+				"regions": ["us-west-2"],
+				"groups": ["group1"]
+			}
+		},
 		"token": "SEKRET",
 		"logLevel": "info",
 		"org": "test"
@@ -49,6 +57,14 @@ func TestReadConfig(t *testing.T) {
 			Role:       "uber-role",
 			ExternalID: "foobar",
 		},
+		Accounts: map[string]MemberAccount{
+			"research": {
+				Id:      "012345678901",
+				Role:    "research-role",
+				Regions: []string{"us-west-2"},
+				Groups:  []string{"group1"},
+			},
+		},
 		Token:    "SEKRET",
 		LogLevel: "info",
 		Org:      "test",
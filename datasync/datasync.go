@@ -114,6 +114,21 @@ func (d *Datasync) ListDatasyncTasks(ctx context.Context) ([]string, error) {
 }
 
 func (d *Datasync) ListDatasyncTaskExecutions(ctx context.Context, taskArn string) ([]string, error) {
+	entries, err := d.ListDatasyncTaskExecutionEntries(ctx, taskArn)
+	if err != nil {
+		return nil, err
+	}
+
+	execs := make([]string, 0, len(entries))
+	for _, e := range entries {
+		execs = append(execs, aws.StringValue(e.TaskExecutionArn))
+	}
+
+	return execs, nil
+}
+
+// ListDatasyncTaskExecutionEntries lists the executions of a task with their status
+func (d *Datasync) ListDatasyncTaskExecutionEntries(ctx context.Context, taskArn string) ([]*datasync.TaskExecutionListEntry, error) {
 	if taskArn == "" {
 		return nil, apierror.New(apierror.ErrBadRequest, "invalid input", nil)
 	}
@@ -122,13 +137,11 @@ func (d *Datasync) ListDatasyncTaskExecutions(ctx context.Context, taskArn strin
 
 	filters := &datasync.ListTaskExecutionsInput{TaskArn: aws.String(taskArn)}
 
-	execs := []string{}
+	execs := []*datasync.TaskExecutionListEntry{}
 	if err := d.Service.ListTaskExecutionsPagesWithContext(ctx,
 		filters,
 		func(page *datasync.ListTaskExecutionsOutput, lastPage bool) bool {
-			for _, c := range page.TaskExecutions {
-				execs = append(execs, aws.StringValue(c.TaskExecutionArn))
-			}
+			execs = append(execs, page.TaskExecutions...)
 			return true
 		},
 		func(r *request.Request) {}); err != nil {
@@ -194,6 +207,134 @@ func (d *Datasync) CreateDatasyncLocationEfs(ctx context.Context, input *datasyn
 	return out, nil
 }
 
+// CreateDatasyncLocationSmb creates Smb datasync location
+func (d *Datasync) CreateDatasyncLocationSmb(ctx context.Context, input *datasync.CreateLocationSmbInput) (*datasync.CreateLocationSmbOutput, error) {
+	if input == nil {
+		return nil, apierror.New(apierror.ErrBadRequest, "invalid input", nil)
+	}
+
+	log.Infof("creating Smb location for %s", aws.StringValue(input.ServerHostname))
+
+	out, err := d.Service.CreateLocationSmbWithContext(ctx, input)
+	if err != nil {
+		return nil, ErrCode("failed to create location", err)
+	}
+
+	return out, nil
+}
+
+// CreateDatasyncLocationNfs creates Nfs datasync location
+func (d *Datasync) CreateDatasyncLocationNfs(ctx context.Context, input *datasync.CreateLocationNfsInput) (*datasync.CreateLocationNfsOutput, error) {
+	if input == nil {
+		return nil, apierror.New(apierror.ErrBadRequest, "invalid input", nil)
+	}
+
+	log.Infof("creating Nfs location for %s", aws.StringValue(input.ServerHostname))
+
+	out, err := d.Service.CreateLocationNfsWithContext(ctx, input)
+	if err != nil {
+		return nil, ErrCode("failed to create location", err)
+	}
+
+	return out, nil
+}
+
+// CreateDatasyncLocationFsxWindows creates FSx Windows datasync location
+func (d *Datasync) CreateDatasyncLocationFsxWindows(ctx context.Context, input *datasync.CreateLocationFsxWindowsInput) (*datasync.CreateLocationFsxWindowsOutput, error) {
+	if input == nil {
+		return nil, apierror.New(apierror.ErrBadRequest, "invalid input", nil)
+	}
+
+	log.Infof("creating FSx Windows location for %s", aws.StringValue(input.FsxFilesystemArn))
+
+	out, err := d.Service.CreateLocationFsxWindowsWithContext(ctx, input)
+	if err != nil {
+		return nil, ErrCode("failed to create location", err)
+	}
+
+	return out, nil
+}
+
+// CreateDatasyncLocationFsxLustre creates FSx Lustre datasync location
+func (d *Datasync) CreateDatasyncLocationFsxLustre(ctx context.Context, input *datasync.CreateLocationFsxLustreInput) (*datasync.CreateLocationFsxLustreOutput, error) {
+	if input == nil {
+		return nil, apierror.New(apierror.ErrBadRequest, "invalid input", nil)
+	}
+
+	log.Infof("creating FSx Lustre location for %s", aws.StringValue(input.FsxFilesystemArn))
+
+	out, err := d.Service.CreateLocationFsxLustreWithContext(ctx, input)
+	if err != nil {
+		return nil, ErrCode("failed to create location", err)
+	}
+
+	return out, nil
+}
+
+// CreateDatasyncLocationFsxOntap creates FSx ONTAP datasync location
+func (d *Datasync) CreateDatasyncLocationFsxOntap(ctx context.Context, input *datasync.CreateLocationFsxOntapInput) (*datasync.CreateLocationFsxOntapOutput, error) {
+	if input == nil {
+		return nil, apierror.New(apierror.ErrBadRequest, "invalid input", nil)
+	}
+
+	log.Infof("creating FSx ONTAP location for %s", aws.StringValue(input.StorageVirtualMachineArn))
+
+	out, err := d.Service.CreateLocationFsxOntapWithContext(ctx, input)
+	if err != nil {
+		return nil, ErrCode("failed to create location", err)
+	}
+
+	return out, nil
+}
+
+// CreateDatasyncLocationFsxOpenZfs creates FSx OpenZFS datasync location
+func (d *Datasync) CreateDatasyncLocationFsxOpenZfs(ctx context.Context, input *datasync.CreateLocationFsxOpenZfsInput) (*datasync.CreateLocationFsxOpenZfsOutput, error) {
+	if input == nil {
+		return nil, apierror.New(apierror.ErrBadRequest, "invalid input", nil)
+	}
+
+	log.Infof("creating FSx OpenZFS location for %s", aws.StringValue(input.FsxFilesystemArn))
+
+	out, err := d.Service.CreateLocationFsxOpenZfsWithContext(ctx, input)
+	if err != nil {
+		return nil, ErrCode("failed to create location", err)
+	}
+
+	return out, nil
+}
+
+// CreateDatasyncLocationObjectStorage creates object storage datasync location
+func (d *Datasync) CreateDatasyncLocationObjectStorage(ctx context.Context, input *datasync.CreateLocationObjectStorageInput) (*datasync.CreateLocationObjectStorageOutput, error) {
+	if input == nil {
+		return nil, apierror.New(apierror.ErrBadRequest, "invalid input", nil)
+	}
+
+	log.Infof("creating object storage location for %s", aws.StringValue(input.ServerHostname))
+
+	out, err := d.Service.CreateLocationObjectStorageWithContext(ctx, input)
+	if err != nil {
+		return nil, ErrCode("failed to create location", err)
+	}
+
+	return out, nil
+}
+
+// CreateDatasyncLocationAzureBlob creates Azure Blob datasync location
+func (d *Datasync) CreateDatasyncLocationAzureBlob(ctx context.Context, input *datasync.CreateLocationAzureBlobInput) (*datasync.CreateLocationAzureBlobOutput, error) {
+	if input == nil {
+		return nil, apierror.New(apierror.ErrBadRequest, "invalid input", nil)
+	}
+
+	log.Infof("creating Azure Blob location for %s", aws.StringValue(input.ContainerUrl))
+
+	out, err := d.Service.CreateLocationAzureBlobWithContext(ctx, input)
+	if err != nil {
+		return nil, ErrCode("failed to create location", err)
+	}
+
+	return out, nil
+}
+
 // CreateDatasyncTask creates a datasync task
 func (d *Datasync) CreateDatasyncTask(ctx context.Context, input *datasync.CreateTaskInput) (*datasync.CreateTaskOutput, error) {
 	if input == nil {
@@ -268,6 +409,24 @@ func (d *Datasync) DescribeDatasyncTask(ctx context.Context, tArn string) (*data
 	return out, nil
 }
 
+// UpdateDatasyncTask updates the configuration of a datasync task
+func (d *Datasync) UpdateDatasyncTask(ctx context.Context, input *datasync.UpdateTaskInput) error {
+	if input == nil || !arn.IsARN(aws.StringValue(input.TaskArn)) {
+		return apierror.New(apierror.ErrBadRequest, "invalid input", nil)
+	}
+
+	log.Infof("updating datasync task %s", aws.StringValue(input.TaskArn))
+
+	out, err := d.Service.UpdateTaskWithContext(ctx, input)
+	if err != nil {
+		return ErrCode("failed to update task", err)
+	}
+
+	log.Debugf("updating datasync task output: %+v", out)
+
+	return nil
+}
+
 // DescribeDatasyncLocationS3 return details about an S3 datasync location
 func (d *Datasync) DescribeDatasyncLocationS3(ctx context.Context, lArn string) (*datasync.DescribeLocationS3Output, error) {
 	if !arn.IsARN(lArn) {
@@ -348,6 +507,126 @@ func (d *Datasync) DescribeDatasyncLocationSmb(ctx context.Context, lArn string)
 	return out, nil
 }
 
+// DescribeDatasyncLocationFsxWindows returns details about an FSx Windows datasync location
+func (d *Datasync) DescribeDatasyncLocationFsxWindows(ctx context.Context, lArn string) (*datasync.DescribeLocationFsxWindowsOutput, error) {
+	if !arn.IsARN(lArn) {
+		return nil, apierror.New(apierror.ErrBadRequest, "invalid location arn", nil)
+	}
+
+	log.Infof("describing datasync location (FSx Windows) %s", lArn)
+
+	out, err := d.Service.DescribeLocationFsxWindowsWithContext(ctx, &datasync.DescribeLocationFsxWindowsInput{
+		LocationArn: aws.String(lArn),
+	})
+	if err != nil {
+		return nil, ErrCode("failed to describe location", err)
+	}
+
+	log.Debugf("describing datasync FSx Windows location output: %+v", out)
+
+	return out, nil
+}
+
+// DescribeDatasyncLocationFsxLustre returns details about an FSx Lustre datasync location
+func (d *Datasync) DescribeDatasyncLocationFsxLustre(ctx context.Context, lArn string) (*datasync.DescribeLocationFsxLustreOutput, error) {
+	if !arn.IsARN(lArn) {
+		return nil, apierror.New(apierror.ErrBadRequest, "invalid location arn", nil)
+	}
+
+	log.Infof("describing datasync location (FSx Lustre) %s", lArn)
+
+	out, err := d.Service.DescribeLocationFsxLustreWithContext(ctx, &datasync.DescribeLocationFsxLustreInput{
+		LocationArn: aws.String(lArn),
+	})
+	if err != nil {
+		return nil, ErrCode("failed to describe location", err)
+	}
+
+	log.Debugf("describing datasync FSx Lustre location output: %+v", out)
+
+	return out, nil
+}
+
+// DescribeDatasyncLocationFsxOntap returns details about an FSx ONTAP datasync location
+func (d *Datasync) DescribeDatasyncLocationFsxOntap(ctx context.Context, lArn string) (*datasync.DescribeLocationFsxOntapOutput, error) {
+	if !arn.IsARN(lArn) {
+		return nil, apierror.New(apierror.ErrBadRequest, "invalid location arn", nil)
+	}
+
+	log.Infof("describing datasync location (FSx ONTAP) %s", lArn)
+
+	out, err := d.Service.DescribeLocationFsxOntapWithContext(ctx, &datasync.DescribeLocationFsxOntapInput{
+		LocationArn: aws.String(lArn),
+	})
+	if err != nil {
+		return nil, ErrCode("failed to describe location", err)
+	}
+
+	log.Debugf("describing datasync FSx ONTAP location output: %+v", out)
+
+	return out, nil
+}
+
+// DescribeDatasyncLocationFsxOpenZfs returns details about an FSx OpenZFS datasync location
+func (d *Datasync) DescribeDatasyncLocationFsxOpenZfs(ctx context.Context, lArn string) (*datasync.DescribeLocationFsxOpenZfsOutput, error) {
+	if !arn.IsARN(lArn) {
+		return nil, apierror.New(apierror.ErrBadRequest, "invalid location arn", nil)
+	}
+
+	log.Infof("describing datasync location (FSx OpenZFS) %s", lArn)
+
+	out, err := d.Service.DescribeLocationFsxOpenZfsWithContext(ctx, &datasync.DescribeLocationFsxOpenZfsInput{
+		LocationArn: aws.String(lArn),
+	})
+	if err != nil {
+		return nil, ErrCode("failed to describe location", err)
+	}
+
+	log.Debugf("describing datasync FSx OpenZFS location output: %+v", out)
+
+	return out, nil
+}
+
+// DescribeDatasyncLocationObjectStorage returns details about an object storage datasync location
+func (d *Datasync) DescribeDatasyncLocationObjectStorage(ctx context.Context, lArn string) (*datasync.DescribeLocationObjectStorageOutput, error) {
+	if !arn.IsARN(lArn) {
+		return nil, apierror.New(apierror.ErrBadRequest, "invalid location arn", nil)
+	}
+
+	log.Infof("describing datasync location (object storage) %s", lArn)
+
+	out, err := d.Service.DescribeLocationObjectStorageWithContext(ctx, &datasync.DescribeLocationObjectStorageInput{
+		LocationArn: aws.String(lArn),
+	})
+	if err != nil {
+		return nil, ErrCode("failed to describe location", err)
+	}
+
+	log.Debugf("describing datasync object storage location output: %+v", out)
+
+	return out, nil
+}
+
+// DescribeDatasyncLocationAzureBlob returns details about an Azure Blob datasync location
+func (d *Datasync) DescribeDatasyncLocationAzureBlob(ctx context.Context, lArn string) (*datasync.DescribeLocationAzureBlobOutput, error) {
+	if !arn.IsARN(lArn) {
+		return nil, apierror.New(apierror.ErrBadRequest, "invalid location arn", nil)
+	}
+
+	log.Infof("describing datasync location (Azure Blob) %s", lArn)
+
+	out, err := d.Service.DescribeLocationAzureBlobWithContext(ctx, &datasync.DescribeLocationAzureBlobInput{
+		LocationArn: aws.String(lArn),
+	})
+	if err != nil {
+		return nil, ErrCode("failed to describe location", err)
+	}
+
+	log.Debugf("describing datasync Azure Blob location output: %+v", out)
+
+	return out, nil
+}
+
 // GetDatasyncTags gets the tags for a documentDB cluster
 func (d *Datasync) GetDatasyncTags(ctx context.Context, tArn string) ([]*datasync.TagListEntry, error) {
 	if !arn.IsARN(tArn) {
@@ -368,19 +647,57 @@ func (d *Datasync) GetDatasyncTags(ctx context.Context, tArn string) ([]*datasyn
 	return out.Tags, err
 }
 
+// TagDatasyncResource adds or updates tags on a datasync resource
+func (d *Datasync) TagDatasyncResource(ctx context.Context, rArn string, tags []*datasync.TagListEntry) error {
+	if !arn.IsARN(rArn) || len(tags) == 0 {
+		return apierror.New(apierror.ErrBadRequest, "invalid input", nil)
+	}
+
+	log.Infof("tagging datasync resource %s", rArn)
+
+	out, err := d.Service.TagResourceWithContext(ctx, &datasync.TagResourceInput{
+		ResourceArn: aws.String(rArn),
+		Tags:        tags,
+	})
+	if err != nil {
+		return ErrCode("failed to tag resource", err)
+	}
+
+	log.Debugf("tagging datasync resource output: %+v", out)
+
+	return nil
+}
+
+// UntagDatasyncResource removes tags from a datasync resource
+func (d *Datasync) UntagDatasyncResource(ctx context.Context, rArn string, keys []*string) error {
+	if !arn.IsARN(rArn) || len(keys) == 0 {
+		return apierror.New(apierror.ErrBadRequest, "invalid input", nil)
+	}
+
+	log.Infof("untagging datasync resource %s", rArn)
+
+	out, err := d.Service.UntagResourceWithContext(ctx, &datasync.UntagResourceInput{
+		ResourceArn: aws.String(rArn),
+		Keys:        keys,
+	})
+	if err != nil {
+		return ErrCode("failed to untag resource", err)
+	}
+
+	log.Debugf("untagging datasync resource output: %+v", out)
+
+	return nil
+}
+
 // StartTaskExecution starts the execution and returns the taskexecution ARN
-func (d *Datasync) StartTaskExecution(ctx context.Context, taskArn string) (*datasync.StartTaskExecutionOutput, error) {
-	if taskArn == "" {
+func (d *Datasync) StartTaskExecution(ctx context.Context, input *datasync.StartTaskExecutionInput) (*datasync.StartTaskExecutionOutput, error) {
+	if input == nil || aws.StringValue(input.TaskArn) == "" {
 		return nil, apierror.New(apierror.ErrBadRequest, "invalid input", nil)
 	}
 
 	log.Info("starting datasync task execution")
 
-	filters := &datasync.StartTaskExecutionInput{TaskArn: aws.String(taskArn)}
-
-	out, err := d.Service.StartTaskExecutionWithContext(ctx,
-		filters,
-		func(r *request.Request) {})
+	out, err := d.Service.StartTaskExecutionWithContext(ctx, input)
 	if err != nil {
 		return nil, ErrCode("failed to start task execution", err)
 	}
@@ -390,6 +707,24 @@ func (d *Datasync) StartTaskExecution(ctx context.Context, taskArn string) (*dat
 	return out, nil
 }
 
+// UpdateTaskExecution updates the options of a running task execution, only BytesPerSecond can be changed
+func (d *Datasync) UpdateTaskExecution(ctx context.Context, input *datasync.UpdateTaskExecutionInput) error {
+	if input == nil || aws.StringValue(input.TaskExecutionArn) == "" || input.Options == nil {
+		return apierror.New(apierror.ErrBadRequest, "invalid input", nil)
+	}
+
+	log.Infof("updating datasync task execution %s", aws.StringValue(input.TaskExecutionArn))
+
+	out, err := d.Service.UpdateTaskExecutionWithContext(ctx, input)
+	if err != nil {
+		return ErrCode("failed to update task execution", err)
+	}
+
+	log.Debugf("updating datasync task execution output: %+v", out)
+
+	return nil
+}
+
 // StopTaskExecution stops the execution and returns the taskexecution ARN
 func (d *Datasync) StopTaskExecution(ctx context.Context, taskArn string) error {
 	if taskArn == "" {
@@ -411,3 +746,61 @@ func (d *Datasync) StopTaskExecution(ctx context.Context, taskArn string) error
 
 	return nil
 }
+
+// CreateDatasyncAgent activates a datasync agent with an activation key and returns the agent ARN
+func (d *Datasync) CreateDatasyncAgent(ctx context.Context, input *datasync.CreateAgentInput) (*datasync.CreateAgentOutput, error) {
+	if input == nil || aws.StringValue(input.ActivationKey) == "" {
+		return nil, apierror.New(apierror.ErrBadRequest, "invalid input", nil)
+	}
+
+	log.Infof("activating datasync agent %s", aws.StringValue(input.AgentName))
+
+	out, err := d.Service.CreateAgentWithContext(ctx, input)
+	if err != nil {
+		return nil, ErrCode("failed to activate agent", err)
+	}
+
+	log.Debugf("activating datasync agent output: %+v", out)
+
+	return out, nil
+}
+
+// DescribeDatasyncAgent returns details about a datasync agent
+func (d *Datasync) DescribeDatasyncAgent(ctx context.Context, aArn string) (*datasync.DescribeAgentOutput, error) {
+	if !arn.IsARN(aArn) {
+		return nil, apierror.New(apierror.ErrBadRequest, "invalid agent arn", nil)
+	}
+
+	log.Infof("describing datasync agent %s", aArn)
+
+	out, err := d.Service.DescribeAgentWithContext(ctx, &datasync.DescribeAgentInput{
+		AgentArn: aws.String(aArn),
+	})
+	if err != nil {
+		return nil, ErrCode("failed to describe agent", err)
+	}
+
+	log.Debugf("describing datasync agent output: %+v", out)
+
+	return out, nil
+}
+
+// DeleteDatasyncAgent deletes a datasync agent
+func (d *Datasync) DeleteDatasyncAgent(ctx context.Context, aArn string) error {
+	if !arn.IsARN(aArn) {
+		return apierror.New(apierror.ErrBadRequest, "invalid agent arn", nil)
+	}
+
+	log.Infof("deleting datasync agent %s", aArn)
+
+	out, err := d.Service.DeleteAgentWithContext(ctx, &datasync.DeleteAgentInput{
+		AgentArn: aws.String(aArn),
+	})
+	if err != nil {
+		return ErrCode("failed to delete agent", err)
+	}
+
+	log.Debugf("deleting datasync agent output: %+v", out)
+
+	return nil
+}
@@ -0,0 +1,57 @@
+package s3
+
+import (
+	"github.com/YaleSpinup/apierror"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+func ErrCode(msg string, err error) error {
+	if aerr, ok := errors.Cause(err).(awserr.Error); ok {
+		switch aerr.Code() {
+		case
+			"Forbidden",
+			"AccessDenied":
+
+			return apierror.New(apierror.ErrForbidden, msg, aerr)
+		case
+			// ErrCodeNoSuchBucket for service response error code
+			// "NoSuchBucket".
+			//
+			// The specified bucket does not exist.
+			s3.ErrCodeNoSuchBucket,
+
+			// ErrCodeNoSuchKey for service response error code
+			// "NoSuchKey".
+			//
+			// The specified key does not exist.
+			s3.ErrCodeNoSuchKey,
+			"NotFound":
+
+			return apierror.New(apierror.ErrNotFound, msg, aerr)
+		case
+			// ErrCodeInvalidObjectState for service response error code
+			// "InvalidObjectState".
+			//
+			// Object is archived and inaccessible until restored.
+			s3.ErrCodeInvalidObjectState:
+
+			return apierror.New(apierror.ErrConflict, msg, aerr)
+		case
+			"SlowDown":
+
+			return apierror.New(apierror.ErrLimitExceeded, msg, aerr)
+		case
+			"ServiceUnavailable":
+
+			return apierror.New(apierror.ErrServiceUnavailable, msg, aerr)
+		default:
+			return apierror.New(apierror.ErrBadRequest, msg, aerr)
+		}
+	}
+
+	log.Warnf("uncaught error: %s, returning Internal Server Error", err)
+	return apierror.New(apierror.ErrInternalError, msg, err)
+}
@@ -0,0 +1,50 @@
+package s3
+
+import (
+	"testing"
+
+	"github.com/YaleSpinup/apierror"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/pkg/errors"
+)
+
+func TestErrCode(t *testing.T) {
+	apiErrorTestCases := map[string]string{
+		"": apierror.ErrBadRequest,
+
+		"Forbidden":    apierror.ErrForbidden,
+		"AccessDenied": apierror.ErrForbidden,
+
+		s3.ErrCodeNoSuchBucket: apierror.ErrNotFound,
+		s3.ErrCodeNoSuchKey:    apierror.ErrNotFound,
+		"NotFound":             apierror.ErrNotFound,
+
+		s3.ErrCodeInvalidObjectState: apierror.ErrConflict,
+
+		"SlowDown": apierror.ErrLimitExceeded,
+
+		"ServiceUnavailable": apierror.ErrServiceUnavailable,
+	}
+
+	for awsErr, apiErr := range apiErrorTestCases {
+		expected := apierror.New(apiErr, "test error", awserr.New(awsErr, awsErr, nil))
+		err := ErrCode("test error", awserr.New(awsErr, awsErr, nil))
+
+		var aerr apierror.Error
+		if !errors.As(err, &aerr) {
+			t.Errorf("expected aws error %s to be an apierror.Error %s, got %s", awsErr, apiErr, err)
+		}
+
+		if aerr.String() != expected.String() {
+			t.Errorf("expected error '%s', got '%s'", expected, aerr)
+		}
+	}
+
+	err := ErrCode("test error", errors.New("Unknown"))
+	if aerr, ok := errors.Cause(err).(apierror.Error); ok {
+		t.Logf("got apierror '%s'", aerr)
+	} else {
+		t.Errorf("expected unknown error to be an apierror.ErrInternalError, got %s", err)
+	}
+}
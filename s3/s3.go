@@ -0,0 +1,108 @@
+package s3
+
+import (
+	"context"
+	"io"
+
+	"github.com/YaleSpinup/apierror"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3iface"
+	log "github.com/sirupsen/logrus"
+)
+
+// S3 is a wrapper around the aws s3 service
+type S3 struct {
+	session *session.Session
+	Service s3iface.S3API
+}
+
+type S3Option func(*S3)
+
+func New(opts ...S3Option) S3 {
+	s := S3{}
+
+	for _, opt := range opts {
+		opt(&s)
+	}
+
+	if s.session != nil {
+		s.Service = s3.New(s.session)
+	}
+
+	return s
+}
+
+func WithSession(sess *session.Session) S3Option {
+	return func(s *S3) {
+		log.Debug("using aws session")
+		s.session = sess
+	}
+}
+
+func WithCredentials(key, secret, token, region string) S3Option {
+	return func(s *S3) {
+		log.Debugf("creating new session with key id %s in region %s", key, region)
+		sess := session.Must(session.NewSession(&aws.Config{
+			Credentials: credentials.NewStaticCredentials(key, secret, token),
+			Region:      aws.String(region),
+		}))
+		s.session = sess
+	}
+}
+
+// ListObjects lists the keys of all objects in a bucket with the given prefix
+func (s *S3) ListObjects(ctx context.Context, bucket, prefix string) ([]string, error) {
+	if bucket == "" {
+		return nil, apierror.New(apierror.ErrBadRequest, "invalid input", nil)
+	}
+
+	log.Infof("listing objects in s3://%s/%s", bucket, prefix)
+
+	keys := []string{}
+	if err := s.Service.ListObjectsV2PagesWithContext(ctx,
+		&s3.ListObjectsV2Input{
+			Bucket: aws.String(bucket),
+			Prefix: aws.String(prefix),
+		},
+		func(page *s3.ListObjectsV2Output, lastPage bool) bool {
+			for _, o := range page.Contents {
+				keys = append(keys, aws.StringValue(o.Key))
+			}
+
+			return true
+		}); err != nil {
+		return nil, ErrCode("failed to list objects", err)
+	}
+
+	log.Debugf("listing objects output: %+v", keys)
+
+	return keys, nil
+}
+
+// GetObject returns the contents of an object
+func (s *S3) GetObject(ctx context.Context, bucket, key string) ([]byte, error) {
+	if bucket == "" || key == "" {
+		return nil, apierror.New(apierror.ErrBadRequest, "invalid input", nil)
+	}
+
+	log.Infof("getting object s3://%s/%s", bucket, key)
+
+	out, err := s.Service.GetObjectWithContext(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		return nil, ErrCode("failed to get object", err)
+	}
+	defer out.Body.Close()
+
+	body, err := io.ReadAll(out.Body)
+	if err != nil {
+		return nil, apierror.New(apierror.ErrInternalError, "failed to read object", err)
+	}
+
+	return body, nil
+}
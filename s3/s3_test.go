@@ -0,0 +1,91 @@
+package s3
+
+import (
+	"context"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3iface"
+)
+
+// mockS3Client is a fake s3 client
+type mockS3Client struct {
+	s3iface.S3API
+	t   *testing.T
+	err error
+}
+
+func newMockS3Client(t *testing.T, err error) s3iface.S3API {
+	return &mockS3Client{
+		t:   t,
+		err: err,
+	}
+}
+
+func (m *mockS3Client) ListObjectsV2PagesWithContext(ctx context.Context, input *s3.ListObjectsV2Input, fn func(*s3.ListObjectsV2Output, bool) bool, opts ...request.Option) error {
+	if m.err != nil {
+		return m.err
+	}
+
+	if !fn(&s3.ListObjectsV2Output{Contents: []*s3.Object{{Key: aws.String("reports/one.json")}}}, false) {
+		return nil
+	}
+
+	fn(&s3.ListObjectsV2Output{Contents: []*s3.Object{{Key: aws.String("reports/two.json")}}}, true)
+	return nil
+}
+
+func (m *mockS3Client) GetObjectWithContext(ctx context.Context, input *s3.GetObjectInput, opts ...request.Option) (*s3.GetObjectOutput, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+
+	return &s3.GetObjectOutput{Body: io.NopCloser(strings.NewReader("{}"))}, nil
+}
+
+func TestNewSession(t *testing.T) {
+	client := New()
+	to := reflect.TypeOf(client).String()
+	if to != "s3.S3" {
+		t.Errorf("expected type to be s3.S3, got %s", to)
+	}
+}
+
+func TestListObjects(t *testing.T) {
+	s := S3{Service: newMockS3Client(t, nil)}
+
+	got, err := s.ListObjects(context.TODO(), "bucket", "reports/")
+	if err != nil {
+		t.Fatalf("expected nil error, got %s", err)
+	}
+
+	if want := []string{"reports/one.json", "reports/two.json"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+
+	if _, err := s.ListObjects(context.TODO(), "", "reports/"); err == nil {
+		t.Error("expected error for empty bucket, got nil")
+	}
+}
+
+func TestGetObject(t *testing.T) {
+	s := S3{Service: newMockS3Client(t, nil)}
+
+	got, err := s.GetObject(context.TODO(), "bucket", "reports/one.json")
+	if err != nil {
+		t.Fatalf("expected nil error, got %s", err)
+	}
+
+	if string(got) != "{}" {
+		t.Errorf("expected {}, got %s", got)
+	}
+
+	if _, err := s.GetObject(context.TODO(), "bucket", ""); err == nil {
+		t.Error("expected error for empty key, got nil")
+	}
+}
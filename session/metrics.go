@@ -0,0 +1,54 @@
+package session
+
+import (
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	// awsRequests counts the AWS API calls by service, operation and error code ("ok" for successful calls)
+	awsRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "datasync_api",
+		Name:      "aws_requests_total",
+		Help:      "Number of AWS API calls, by service, operation and error code.",
+	}, []string{"service", "operation", "code"})
+
+	// awsRequestDuration observes the duration of the AWS API calls, including retries
+	awsRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: "datasync_api",
+		Name:      "aws_request_duration_seconds",
+		Help:      "Duration of AWS API calls including retries, by service and operation.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"service", "operation"})
+)
+
+// metricsHandler records the AWS API call metrics once a request completes, it's added to the
+// complete handlers of every session so all the clients created from it are instrumented
+var metricsHandler = request.NamedHandler{
+	Name: "datasync-api.metrics",
+	Fn:   observeRequest,
+}
+
+// observeRequest records the service, operation, error code and duration of a completed request
+func observeRequest(r *request.Request) {
+	service := r.ClientInfo.ServiceName
+	operation := "unknown"
+	if r.Operation != nil {
+		operation = r.Operation.Name
+	}
+
+	code := "ok"
+	if r.Error != nil {
+		code = "unknown"
+		if aerr, ok := r.Error.(awserr.Error); ok {
+			code = aerr.Code()
+		}
+	}
+
+	awsRequests.WithLabelValues(service, operation, code).Inc()
+	awsRequestDuration.WithLabelValues(service, operation).Observe(time.Since(r.Time).Seconds())
+}
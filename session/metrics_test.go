@@ -0,0 +1,52 @@
+package session
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/client/metadata"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestNewMetricsHandler(t *testing.T) {
+	s := New(WithRegion("us-east-1"))
+
+	if s.Session.Handlers.Complete.Len() == 0 {
+		t.Error("expected the metrics handler to be added to the session's complete handlers")
+	}
+}
+
+func TestObserveRequest(t *testing.T) {
+	tests := []struct {
+		name      string
+		operation *request.Operation
+		err       error
+		code      string
+		wantOp    string
+	}{
+		{"ok", &request.Operation{Name: "DescribeTask"}, nil, "ok", "DescribeTask"},
+		{"aws error", &request.Operation{Name: "DescribeTask"}, awserr.New("InvalidRequestException", "not found", nil), "InvalidRequestException", "DescribeTask"},
+		{"other error", &request.Operation{Name: "AssumeRole"}, errors.New("boom"), "unknown", "AssumeRole"},
+		{"no operation", nil, nil, "ok", "unknown"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			before := testutil.ToFloat64(awsRequests.WithLabelValues("datasync", tt.wantOp, tt.code))
+
+			observeRequest(&request.Request{
+				ClientInfo: metadata.ClientInfo{ServiceName: "datasync"},
+				Operation:  tt.operation,
+				Error:      tt.err,
+				Time:       time.Now().Add(-time.Second),
+			})
+
+			if got := testutil.ToFloat64(awsRequests.WithLabelValues("datasync", tt.wantOp, tt.code)); got != before+1 {
+				t.Errorf("expected %f requests, got %f", before+1, got)
+			}
+		})
+	}
+}
@@ -34,6 +34,7 @@ func New(opts ...SessionOption) Session {
 	}
 
 	sess := session.Must(session.NewSession(&config))
+	sess.Handlers.Complete.PushBackNamed(metricsHandler)
 	s.Session = sess
 
 	return s
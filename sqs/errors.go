@@ -0,0 +1,61 @@
+package sqs
+
+import (
+	"github.com/YaleSpinup/apierror"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/sqs"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+func ErrCode(msg string, err error) error {
+	if aerr, ok := errors.Cause(err).(awserr.Error); ok {
+		switch aerr.Code() {
+		case
+			"Forbidden",
+			"AccessDenied",
+
+			// ErrCodeKmsAccessDenied for service response error code
+			// "KmsAccessDenied".
+			//
+			// The caller doesn't have the required KMS access.
+			sqs.ErrCodeKmsAccessDenied:
+
+			return apierror.New(apierror.ErrForbidden, msg, aerr)
+		case
+			// ErrCodeQueueDoesNotExist for service response error code
+			// "AWS.SimpleQueueService.NonExistentQueue".
+			//
+			// The specified queue doesn't exist.
+			sqs.ErrCodeQueueDoesNotExist,
+			// the code returned without the legacy query error header
+			"QueueDoesNotExist",
+			"NotFound":
+
+			return apierror.New(apierror.ErrNotFound, msg, aerr)
+		case
+			// ErrCodeOverLimit for service response error code
+			// "OverLimit".
+			//
+			// The specified action violates a limit, ie. the maximum number of inflight messages.
+			sqs.ErrCodeOverLimit,
+
+			// ErrCodeRequestThrottled for service response error code
+			// "RequestThrottled".
+			//
+			// The request was denied due to request throttling.
+			sqs.ErrCodeRequestThrottled:
+
+			return apierror.New(apierror.ErrLimitExceeded, msg, aerr)
+		case
+			"ServiceUnavailable":
+
+			return apierror.New(apierror.ErrServiceUnavailable, msg, aerr)
+		default:
+			return apierror.New(apierror.ErrBadRequest, msg, aerr)
+		}
+	}
+
+	log.Warnf("uncaught error: %s, returning Internal Server Error", err)
+	return apierror.New(apierror.ErrInternalError, msg, err)
+}
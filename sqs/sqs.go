@@ -0,0 +1,104 @@
+package sqs
+
+import (
+	"context"
+
+	"github.com/YaleSpinup/apierror"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/sqs"
+	"github.com/aws/aws-sdk-go/service/sqs/sqsiface"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// maxMessages is the maximum number of messages returned by a receive
+	maxMessages = 10
+	// waitTimeSeconds is how long a receive waits for messages to arrive
+	waitTimeSeconds = 20
+)
+
+// SQS is a wrapper around the aws sqs service for a single queue
+type SQS struct {
+	session  *session.Session
+	Service  sqsiface.SQSAPI
+	QueueUrl string
+}
+
+type SQSOption func(*SQS)
+
+func New(opts ...SQSOption) SQS {
+	s := SQS{}
+
+	for _, opt := range opts {
+		opt(&s)
+	}
+
+	if s.session != nil {
+		s.Service = sqs.New(s.session)
+	}
+
+	return s
+}
+
+func WithSession(sess *session.Session) SQSOption {
+	return func(s *SQS) {
+		log.Debug("using aws session")
+		s.session = sess
+	}
+}
+
+func WithCredentials(key, secret, token, region string) SQSOption {
+	return func(s *SQS) {
+		log.Debugf("creating new session with key id %s in region %s", key, region)
+		sess := session.Must(session.NewSession(&aws.Config{
+			Credentials: credentials.NewStaticCredentials(key, secret, token),
+			Region:      aws.String(region),
+		}))
+		s.session = sess
+	}
+}
+
+func WithQueueUrl(url string) SQSOption {
+	return func(s *SQS) {
+		log.Debugf("using sqs queue %s", url)
+		s.QueueUrl = url
+	}
+}
+
+// ReceiveMessages long polls the queue for up to 10 messages
+func (s *SQS) ReceiveMessages(ctx context.Context) ([]*sqs.Message, error) {
+	if s.QueueUrl == "" {
+		return nil, apierror.New(apierror.ErrBadRequest, "invalid input", nil)
+	}
+
+	out, err := s.Service.ReceiveMessageWithContext(ctx, &sqs.ReceiveMessageInput{
+		QueueUrl:            aws.String(s.QueueUrl),
+		MaxNumberOfMessages: aws.Int64(maxMessages),
+		WaitTimeSeconds:     aws.Int64(waitTimeSeconds),
+	})
+	if err != nil {
+		return nil, ErrCode("failed to receive messages", err)
+	}
+
+	log.Debugf("received %d messages from %s", len(out.Messages), s.QueueUrl)
+
+	return out.Messages, nil
+}
+
+// DeleteMessage deletes a received message from the queue
+func (s *SQS) DeleteMessage(ctx context.Context, receiptHandle string) error {
+	if s.QueueUrl == "" || receiptHandle == "" {
+		return apierror.New(apierror.ErrBadRequest, "invalid input", nil)
+	}
+
+	if _, err := s.Service.DeleteMessageWithContext(ctx, &sqs.DeleteMessageInput{
+		QueueUrl:      aws.String(s.QueueUrl),
+		ReceiptHandle: aws.String(receiptHandle),
+	}); err != nil {
+		return ErrCode("failed to delete message", err)
+	}
+
+	return nil
+}
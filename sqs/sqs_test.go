@@ -0,0 +1,109 @@
+package sqs
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/sqs"
+	"github.com/aws/aws-sdk-go/service/sqs/sqsiface"
+)
+
+// mockSQSClient is a fake sqs client
+type mockSQSClient struct {
+	sqsiface.SQSAPI
+	t       *testing.T
+	err     error
+	deleted []string
+}
+
+func newMockSQSClient(t *testing.T, err error) *mockSQSClient {
+	return &mockSQSClient{
+		t:   t,
+		err: err,
+	}
+}
+
+func (m *mockSQSClient) ReceiveMessageWithContext(ctx context.Context, input *sqs.ReceiveMessageInput, opts ...request.Option) (*sqs.ReceiveMessageOutput, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+
+	if aws.Int64Value(input.WaitTimeSeconds) != waitTimeSeconds {
+		m.t.Errorf("expected a long poll of %d seconds, got %d", waitTimeSeconds, aws.Int64Value(input.WaitTimeSeconds))
+	}
+
+	return &sqs.ReceiveMessageOutput{
+		Messages: []*sqs.Message{
+			{Body: aws.String("{}"), ReceiptHandle: aws.String("receipt-1")},
+		},
+	}, nil
+}
+
+func (m *mockSQSClient) DeleteMessageWithContext(ctx context.Context, input *sqs.DeleteMessageInput, opts ...request.Option) (*sqs.DeleteMessageOutput, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+
+	m.deleted = append(m.deleted, aws.StringValue(input.ReceiptHandle))
+	return &sqs.DeleteMessageOutput{}, nil
+}
+
+func TestNewSession(t *testing.T) {
+	client := New(WithQueueUrl("https://sqs.us-east-1.amazonaws.com/012345678901/datasync-events"))
+	to := reflect.TypeOf(client).String()
+	if to != "sqs.SQS" {
+		t.Errorf("expected type to be sqs.SQS, got %s", to)
+	}
+
+	if client.QueueUrl != "https://sqs.us-east-1.amazonaws.com/012345678901/datasync-events" {
+		t.Errorf("expected queue url to be set, got %s", client.QueueUrl)
+	}
+}
+
+func TestReceiveMessages(t *testing.T) {
+	s := SQS{Service: newMockSQSClient(t, nil), QueueUrl: "queue"}
+
+	got, err := s.ReceiveMessages(context.TODO())
+	if err != nil {
+		t.Fatalf("expected nil error, got %s", err)
+	}
+
+	if len(got) != 1 || aws.StringValue(got[0].ReceiptHandle) != "receipt-1" {
+		t.Errorf("expected one message with receipt-1, got %+v", got)
+	}
+
+	if _, err := (&SQS{Service: newMockSQSClient(t, nil)}).ReceiveMessages(context.TODO()); err == nil {
+		t.Error("expected error for empty queue url, got nil")
+	}
+
+	s = SQS{Service: newMockSQSClient(t, awserr.New(sqs.ErrCodeQueueDoesNotExist, "not found", nil)), QueueUrl: "queue"}
+	if _, err := s.ReceiveMessages(context.TODO()); err == nil {
+		t.Error("expected error, got nil")
+	}
+}
+
+func TestDeleteMessage(t *testing.T) {
+	m := newMockSQSClient(t, nil)
+	s := SQS{Service: m, QueueUrl: "queue"}
+
+	if err := s.DeleteMessage(context.TODO(), "receipt-1"); err != nil {
+		t.Fatalf("expected nil error, got %s", err)
+	}
+
+	if !reflect.DeepEqual(m.deleted, []string{"receipt-1"}) {
+		t.Errorf("expected receipt-1 to be deleted, got %v", m.deleted)
+	}
+
+	if err := s.DeleteMessage(context.TODO(), ""); err == nil {
+		t.Error("expected error for empty receipt handle, got nil")
+	}
+
+	s = SQS{Service: newMockSQSClient(t, awserr.New(sqs.ErrCodeReceiptHandleIsInvalid, "invalid", nil)), QueueUrl: "queue"}
+	if err := s.DeleteMessage(context.TODO(), "receipt-1"); err == nil {
+		t.Error("expected error, got nil")
+	}
+}